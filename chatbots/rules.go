@@ -0,0 +1,254 @@
+package chatbots
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ConditionOperator representa los operadores soportados por Condition.Evaluate
+type ConditionOperator string
+
+const (
+	OperatorEquals      ConditionOperator = "eq"
+	OperatorNotEquals   ConditionOperator = "neq"
+	OperatorGreaterThan ConditionOperator = "gt"
+	OperatorGreaterEq   ConditionOperator = "gte"
+	OperatorLessThan    ConditionOperator = "lt"
+	OperatorLessEq      ConditionOperator = "lte"
+	OperatorContains    ConditionOperator = "contains"
+	OperatorIn          ConditionOperator = "in"
+	OperatorExists      ConditionOperator = "exists"
+)
+
+// Evaluate evalúa la condición contra un contexto local, para simular reglas
+// antes de desplegarlas. Field admite rutas con puntos ("contact.city") para
+// acceder a mapas anidados. Los números se comparan numéricamente aunque
+// lleguen como texto ("42" == 42); el resto se compara como texto. Un campo
+// ausente no cumple ninguna condición salvo que el operador sea exists.
+func (c Condition) Evaluate(ctx map[string]interface{}) (bool, error) {
+	actual, found := lookupField(ctx, c.Field)
+	operator := ConditionOperator(strings.ToLower(c.Operator))
+	
+	if operator == OperatorExists {
+		return found, nil
+	}
+	
+	if !isValidOperator(operator) {
+		return false, fmt.Errorf("unsupported operator: %s", c.Operator)
+	}
+	
+	if !found {
+		return false, nil
+	}
+	
+	switch operator {
+	case OperatorEquals:
+		return valuesEqual(actual, c.Value), nil
+	
+	case OperatorNotEquals:
+		return !valuesEqual(actual, c.Value), nil
+	
+	case OperatorGreaterThan, OperatorGreaterEq, OperatorLessThan, OperatorLessEq:
+		left, ok := toNumber(actual)
+		if !ok {
+			return false, fmt.Errorf("field %s is not numeric: %v", c.Field, actual)
+		}
+		
+		right, ok := toNumber(c.Value)
+		if !ok {
+			return false, fmt.Errorf("operator %s requires a numeric value, got %v", c.Operator, c.Value)
+		}
+		
+		switch operator {
+		case OperatorGreaterThan:
+			return left > right, nil
+		case OperatorGreaterEq:
+			return left >= right, nil
+		case OperatorLessThan:
+			return left < right, nil
+		default:
+			return left <= right, nil
+		}
+	
+	case OperatorContains:
+		// En listas se busca el elemento; en el resto, la subcadena
+		if items, ok := toSlice(actual); ok {
+			return sliceContains(items, c.Value), nil
+		}
+		return strings.Contains(
+			strings.ToLower(fmt.Sprint(actual)),
+			strings.ToLower(fmt.Sprint(c.Value)),
+		), nil
+	
+	case OperatorIn:
+		items, ok := toSlice(c.Value)
+		if !ok {
+			return false, fmt.Errorf("operator in requires a list value, got %T", c.Value)
+		}
+		return sliceContains(items, actual), nil
+	}
+	
+	return false, fmt.Errorf("unsupported operator: %s", c.Operator)
+}
+
+// Matches indica si la regla se dispararía con el texto o evento trigger y el
+// contexto ctx: la regla debe estar activa, el disparador debe coincidir y
+// todas las condiciones deben cumplirse. Una condición que no puede evaluarse
+// se considera no cumplida.
+func (r *Rule) Matches(trigger string, ctx map[string]interface{}) bool {
+	if !r.IsActive || !r.Trigger.Matches(trigger) {
+		return false
+	}
+	
+	for _, condition := range r.Conditions {
+		matched, err := condition.Evaluate(ctx)
+		if err != nil || !matched {
+			return false
+		}
+	}
+	
+	return true
+}
+
+// Matches indica si el disparador coincide con el texto o evento recibido.
+// KEYWORD busca alguna palabra clave en el texto sin distinguir mayúsculas,
+// PATTERN aplica la expresión regular y el resto compara con Event.
+func (t *Trigger) Matches(trigger string) bool {
+	switch TriggerType(strings.ToUpper(t.Type)) {
+	case TriggerTypeKeyword:
+		text := strings.ToLower(trigger)
+		for _, keyword := range t.Keywords {
+			if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+				return true
+			}
+		}
+		return false
+	
+	case TriggerTypePattern:
+		re, err := regexp.Compile(t.Pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(trigger)
+	
+	default:
+		return t.Event != "" && strings.EqualFold(t.Event, trigger)
+	}
+}
+
+// isValidOperator indica si el operador está soportado
+func isValidOperator(operator ConditionOperator) bool {
+	switch operator {
+	case OperatorEquals, OperatorNotEquals, OperatorGreaterThan, OperatorGreaterEq,
+		OperatorLessThan, OperatorLessEq, OperatorContains, OperatorIn, OperatorExists:
+		return true
+	}
+	
+	return false
+}
+
+// lookupField busca field en ctx, recorriendo mapas anidados si contiene puntos
+func lookupField(ctx map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := ctx[field]; ok {
+		return value, value != nil
+	}
+	
+	var current interface{} = ctx
+	for _, part := range strings.Split(field, ".") {
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		
+		current, ok = values[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	
+	return current, current != nil
+}
+
+// valuesEqual compara dos valores numéricamente si ambos son números y como
+// texto en caso contrario
+func valuesEqual(a, b interface{}) bool {
+	if left, ok := toNumber(a); ok {
+		if right, ok := toNumber(b); ok {
+			return left == right
+		}
+	}
+	
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// toNumber convierte números y textos numéricos a float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	
+	return 0, false
+}
+
+// toSlice convierte cualquier slice o array a []interface{}
+func toSlice(value interface{}) ([]interface{}, bool) {
+	if items, ok := value.([]interface{}); ok {
+		return items, true
+	}
+	
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	
+	return items, true
+}
+
+// sliceContains indica si items contiene un valor igual a value
+func sliceContains(items []interface{}, value interface{}) bool {
+	for _, item := range items {
+		if valuesEqual(item, value) {
+			return true
+		}
+	}
+	
+	return false
+}
@@ -3,6 +3,8 @@ package chatbots
 import (
 	"context"
 	"fmt"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
 )
 
 // HTTPClient define la interfaz para realizar peticiones HTTP
@@ -277,16 +279,106 @@ func (s *Service) ResolveChatSession(ctx context.Context, whatsappNumber string,
 	return s.UpdateChatStatus(ctx, req)
 }
 
-// AddTagsToChat agrega etiquetas a un chat
+// AddTagsToChat agrega etiquetas a un chat sin modificar su estado. Como el
+// servidor reemplaza la lista completa, las etiquetas nuevas se agregan a las
+// actuales, en orden y sin duplicados.
 func (s *Service) AddTagsToChat(ctx context.Context, whatsappNumber string, tags []string) (*ChatStatusResponse, error) {
-	// Primero obtener el estado actual para no sobrescribir otros campos
-	req := &UpdateChatStatusRequest{
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	
+	current, err := s.GetChatStatus(ctx, whatsappNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	seen := make(map[string]bool, len(current.Tags)+len(tags))
+	merged := make([]string, 0, len(current.Tags)+len(tags))
+	for _, tag := range append(append([]string{}, current.Tags...), tags...) {
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		merged = append(merged, tag)
+	}
+	
+	return s.setChatTags(ctx, whatsappNumber, merged)
+}
+
+// GetChatStatus obtiene el estado, el agente asignado y las etiquetas de un chat
+func (s *Service) GetChatStatus(ctx context.Context, whatsappNumber string) (*ChatStatusResponse, error) {
+	if whatsappNumber == "" {
+		return nil, fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&whatsappNumber); err != nil {
+		return nil, err
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/getChatStatus/%s", whatsappNumber)
+	
+	var response ChatStatusResponse
+	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error getting chat status for %s: %w", whatsappNumber, err)
+	}
+	
+	return &response, nil
+}
+
+// RemoveTagsFromChat quita etiquetas de un chat sin modificar su estado. Las
+// etiquetas restantes conservan su orden y se envían sin duplicados.
+func (s *Service) RemoveTagsFromChat(ctx context.Context, whatsappNumber string, tags []string) (*ChatStatusResponse, error) {
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+	
+	current, err := s.GetChatStatus(ctx, whatsappNumber)
+	if err != nil {
+		return nil, err
+	}
+	
+	removed := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		removed[tag] = true
+	}
+	
+	seen := make(map[string]bool, len(current.Tags))
+	remaining := []string{}
+	for _, tag := range current.Tags {
+		if removed[tag] || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		remaining = append(remaining, tag)
+	}
+	
+	return s.setChatTags(ctx, whatsappNumber, remaining)
+}
+
+// setChatTags reemplaza las etiquetas de un chat. A diferencia de
+// UpdateChatStatus, envía la lista aunque esté vacía para poder quitar la
+// última etiqueta, y nunca envía el estado.
+func (s *Service) setChatTags(ctx context.Context, whatsappNumber string, tags []string) (*ChatStatusResponse, error) {
+	if err := common.NormalizeWhatsappNumber(&whatsappNumber); err != nil {
+		return nil, err
+	}
+	
+	request := struct {
+		WhatsappNumber string   `json:"whatsappNumber"`
+		Tags           []string `json:"tags"`
+	}{
 		WhatsappNumber: whatsappNumber,
-		Status:         string(ChatStatusOpen), // Mantener estado actual o usar uno por defecto
 		Tags:           tags,
 	}
 	
-	return s.UpdateChatStatus(ctx, req)
+	var response ChatStatusResponse
+	err := s.client.DoRequest(ctx, "POST", "/api/v1/updateChatStatus", request, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error updating chat tags for %s: %w", whatsappNumber, err)
+	}
+	
+	return &response, nil
 }
 
 // GetChatbotByName busca un chatbot por nombre
@@ -348,3 +440,223 @@ func (s *Service) UpdateChatbotResponses(ctx context.Context, id string, respons
 	return s.UpdateChatbot(ctx, id, req)
 }
 
+// GetFlows obtiene la lista de todos los flujos de conversación
+func (s *Service) GetFlows(ctx context.Context) (*FlowsResponse, error) {
+	var response FlowsResponse
+	err := s.client.DoRequest(ctx, "GET", "/api/v1/chatFlows", nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error getting flows: %w", err)
+	}
+	
+	return &response, nil
+}
+
+// GetFlow obtiene un flujo de conversación específico por ID
+func (s *Service) GetFlow(ctx context.Context, id string) (*ChatFlow, error) {
+	if id == "" {
+		return nil, fmt.Errorf("flow ID is required")
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/chatFlows/%s", id)
+	
+	var response struct {
+		BaseResponse
+		Flow ChatFlow `json:"flow"`
+	}
+	
+	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error getting flow %s: %w", id, err)
+	}
+	
+	return &response.Flow, nil
+}
+
+// CreateFlow crea un nuevo flujo de conversación
+func (s *Service) CreateFlow(ctx context.Context, req *CreateFlowRequest) (*ChatFlow, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	var response struct {
+		BaseResponse
+		Flow ChatFlow `json:"flow"`
+	}
+	
+	err := s.client.DoRequest(ctx, "POST", "/api/v1/chatFlows", req, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error creating flow: %w", err)
+	}
+	
+	return &response.Flow, nil
+}
+
+// UpdateFlow actualiza un flujo de conversación existente
+func (s *Service) UpdateFlow(ctx context.Context, id string, req *UpdateFlowRequest) (*ChatFlow, error) {
+	if id == "" {
+		return nil, fmt.Errorf("flow ID is required")
+	}
+	
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/chatFlows/%s", id)
+	
+	var response struct {
+		BaseResponse
+		Flow ChatFlow `json:"flow"`
+	}
+	
+	err := s.client.DoRequest(ctx, "PUT", endpoint, req, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error updating flow %s: %w", id, err)
+	}
+	
+	return &response.Flow, nil
+}
+
+// DeleteFlow elimina un flujo de conversación
+func (s *Service) DeleteFlow(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("flow ID is required")
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/chatFlows/%s", id)
+	
+	var response BaseResponse
+	err := s.client.DoRequest(ctx, "DELETE", endpoint, nil, &response)
+	if err != nil {
+		return fmt.Errorf("error deleting flow %s: %w", id, err)
+	}
+	
+	return nil
+}
+
+// GetChatSession obtiene la sesión de chatbot de un contacto
+func (s *Service) GetChatSession(ctx context.Context, whatsappNumber string) (*ChatSession, error) {
+	if whatsappNumber == "" {
+		return nil, fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&whatsappNumber); err != nil {
+		return nil, err
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/chatSessions/%s", whatsappNumber)
+	
+	var response struct {
+		BaseResponse
+		Session ChatSession `json:"session"`
+	}
+	
+	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error getting chat session for %s: %w", whatsappNumber, err)
+	}
+	
+	return &response.Session, nil
+}
+
+// ListChatSessions obtiene las sesiones de chatbot, filtradas por estado y chatbot
+func (s *Service) ListChatSessions(ctx context.Context, params *ListChatSessionsParams) (*ChatSessionsResponse, error) {
+	if params == nil {
+		params = &ListChatSessionsParams{}
+	}
+	
+	params.SetDefaults()
+	
+	// Construir endpoint con query parameters
+	endpoint := common.BuildEndpoint("/api/v1/chatSessions", params.ToMap())
+	
+	var response ChatSessionsResponse
+	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error listing chat sessions: %w", err)
+	}
+	
+	return &response, nil
+}
+
+// GetChatbotSessions obtiene las sesiones que conduce un chatbot, por defecto
+// solo las activas
+func (s *Service) GetChatbotSessions(ctx context.Context, chatbotID string, params *GetSessionsParams) (*ChatSessionsResponse, error) {
+	if chatbotID == "" {
+		return nil, fmt.Errorf("chatbot ID is required")
+	}
+	
+	listParams := &ListChatSessionsParams{
+		Status:    ChatSessionStatusActive,
+		ChatbotID: chatbotID,
+	}
+	if params != nil {
+		if params.Status != "" {
+			listParams.Status = params.Status
+		}
+		listParams.PageSize = params.PageSize
+		listParams.PageNumber = params.PageNumber
+	}
+	
+	response, err := s.ListChatSessions(ctx, listParams)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sessions for chatbot %s: %w", chatbotID, err)
+	}
+	
+	return response, nil
+}
+
+// GetSessionVariables obtiene las variables de flujo asignadas a la sesión de un contacto
+func (s *Service) GetSessionVariables(ctx context.Context, whatsappNumber string) (map[string]interface{}, error) {
+	if whatsappNumber == "" {
+		return nil, fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&whatsappNumber); err != nil {
+		return nil, err
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/getSessionVariables/%s", whatsappNumber)
+	
+	var response SessionVariablesResponse
+	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error getting session variables for %s: %w", whatsappNumber, err)
+	}
+	
+	if response.Variables == nil {
+		response.Variables = make(map[string]interface{})
+	}
+	
+	return response.Variables, nil
+}
+
+// SetSessionVariable asigna una variable de flujo en la sesión de un contacto
+func (s *Service) SetSessionVariable(ctx context.Context, whatsappNumber, key string, value interface{}) error {
+	req := &SetSessionVariableRequest{
+		WhatsappNumber: whatsappNumber,
+		Key:            key,
+		Value:          value,
+	}
+	
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/setSessionVariable/%s", req.WhatsappNumber)
+	
+	var response BaseResponse
+	err := s.client.DoRequest(ctx, "POST", endpoint, req, &response)
+	if err != nil {
+		return fmt.Errorf("error setting session variable %s: %w", key, err)
+	}
+	
+	return nil
+}
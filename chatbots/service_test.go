@@ -0,0 +1,733 @@
+package chatbots
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/messages"
+)
+
+// MockHTTPClient implementa HTTPClient para testing
+type MockHTTPClient struct {
+	DoRequestFunc func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
+}
+
+func (m *MockHTTPClient) DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	if m.DoRequestFunc != nil {
+		return m.DoRequestFunc(ctx, method, endpoint, body, result)
+	}
+	return nil
+}
+
+func TestGetSessionVariables(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if method != "GET" {
+				t.Errorf("Expected GET, got %s", method)
+			}
+			
+			if endpoint != "/api/v1/getSessionVariables/5491112345678" {
+				t.Errorf("Unexpected endpoint %s", endpoint)
+			}
+			
+			return json.Unmarshal([]byte(`{"result":true,"variables":{"plan":"gold","step":2}}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	variables, err := service.GetSessionVariables(context.Background(), "+54 9 11 1234-5678")
+	if err != nil {
+		t.Fatalf("GetSessionVariables() error = %v", err)
+	}
+	
+	if variables["plan"] != "gold" {
+		t.Errorf("Expected plan gold, got %v", variables["plan"])
+	}
+	
+	if variables["step"] != float64(2) {
+		t.Errorf("Expected step 2, got %v", variables["step"])
+	}
+}
+
+func TestGetSessionVariablesEmpty(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return json.Unmarshal([]byte(`{"result":true}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	variables, err := service.GetSessionVariables(context.Background(), "5491112345678")
+	if err != nil {
+		t.Fatalf("GetSessionVariables() error = %v", err)
+	}
+	
+	if variables == nil || len(variables) != 0 {
+		t.Errorf("Expected empty non-nil map, got %v", variables)
+	}
+}
+
+func TestGetSessionVariablesError(t *testing.T) {
+	apiErr := errors.New("server error")
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return apiErr
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	if _, err := service.GetSessionVariables(context.Background(), "5491112345678"); !errors.Is(err, apiErr) {
+		t.Errorf("Expected wrapped API error, got %v", err)
+	}
+}
+
+func TestSetSessionVariable(t *testing.T) {
+	var payload map[string]interface{}
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if method != "POST" {
+				t.Errorf("Expected POST, got %s", method)
+			}
+			
+			if endpoint != "/api/v1/setSessionVariable/5491112345678" {
+				t.Errorf("Unexpected endpoint %s", endpoint)
+			}
+			
+			data, err := json.Marshal(body)
+			if err != nil {
+				t.Fatalf("failed to marshal body: %v", err)
+			}
+			return json.Unmarshal(data, &payload)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	if err := service.SetSessionVariable(context.Background(), "5491112345678", "plan", "gold"); err != nil {
+		t.Fatalf("SetSessionVariable() error = %v", err)
+	}
+	
+	if payload["key"] != "plan" || payload["value"] != "gold" {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+	
+	if _, ok := payload["whatsappNumber"]; ok {
+		t.Error("whatsappNumber should travel in the path, not the body")
+	}
+}
+
+func TestSetSessionVariableValidation(t *testing.T) {
+	called := false
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			called = true
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	tests := []struct {
+		name           string
+		whatsappNumber string
+		key            string
+	}{
+		{name: "empty key", whatsappNumber: "5491112345678", key: ""},
+		{name: "blank key", whatsappNumber: "5491112345678", key: "   "},
+		{name: "empty number", whatsappNumber: "", key: "plan"},
+		{name: "invalid number", whatsappNumber: "12ab", key: "plan"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := service.SetSessionVariable(context.Background(), tt.whatsappNumber, tt.key, "value"); err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+		})
+	}
+	
+	if called {
+		t.Error("DoRequest should not be called for invalid requests")
+	}
+}
+
+// validFlowSteps retorna un flujo mínimo con una pregunta y dos finales
+func validFlowSteps() []FlowStep {
+	return []FlowStep{
+		{
+			ID:      "start",
+			Type:    string(FlowStepTypeQuestion),
+			Message: "¿Querés hablar con ventas?",
+			Options: []FlowOption{
+				{ID: "yes", Text: "Sí", Value: "yes", NextStep: "sales"},
+				{ID: "no", Text: "No", Value: "no", NextStep: "bye"},
+			},
+		},
+		{ID: "sales", Type: string(FlowStepTypeAction), NextStep: "bye"},
+		{ID: "bye", Type: string(FlowStepTypeEnd), Message: "¡Gracias!"},
+	}
+}
+
+func TestCreateFlowRequestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(req *CreateFlowRequest)
+		wantErr bool
+	}{
+		{name: "valid flow", mutate: func(req *CreateFlowRequest) {}},
+		{name: "missing name", mutate: func(req *CreateFlowRequest) { req.Name = "" }, wantErr: true},
+		{name: "no steps", mutate: func(req *CreateFlowRequest) { req.Steps = nil }, wantErr: true},
+		{name: "step without ID", mutate: func(req *CreateFlowRequest) { req.Steps[1].ID = "" }, wantErr: true},
+		{name: "duplicate step ID", mutate: func(req *CreateFlowRequest) { req.Steps[2].ID = "sales" }, wantErr: true},
+		{name: "invalid type", mutate: func(req *CreateFlowRequest) { req.Steps[1].Type = "JUMP" }, wantErr: true},
+		{name: "unknown next step", mutate: func(req *CreateFlowRequest) { req.Steps[1].NextStep = "missing" }, wantErr: true},
+		{name: "unknown option next step", mutate: func(req *CreateFlowRequest) { req.Steps[0].Options[1].NextStep = "missing" }, wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &CreateFlowRequest{Name: "Ventas", Steps: validFlowSteps()}
+			tt.mutate(req)
+			
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUpdateFlowRequestValidation(t *testing.T) {
+	name := ""
+	if err := (&UpdateFlowRequest{Name: &name}).Validate(); err == nil {
+		t.Error("Expected error for empty name")
+	}
+	
+	isActive := false
+	if err := (&UpdateFlowRequest{IsActive: &isActive}).Validate(); err != nil {
+		t.Errorf("Expected update without steps to be valid, got %v", err)
+	}
+	
+	if err := (&UpdateFlowRequest{Steps: []FlowStep{}}).Validate(); err == nil {
+		t.Error("Expected error for an empty, non-nil steps list")
+	}
+	
+	steps := validFlowSteps()
+	steps[0].NextStep = "missing"
+	if err := (&UpdateFlowRequest{Steps: steps}).Validate(); err == nil {
+		t.Error("Expected error for unknown next step")
+	}
+}
+
+func TestFlowCRUD(t *testing.T) {
+	type call struct {
+		method   string
+		endpoint string
+	}
+	var calls []call
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			calls = append(calls, call{method, endpoint})
+			
+			switch {
+			case method == "GET" && endpoint == "/api/v1/chatFlows":
+				return json.Unmarshal([]byte(`{"result":true,"flows":[{"id":"flow_1","name":"Ventas"}]}`), result)
+			case method == "DELETE":
+				return nil
+			default:
+				return json.Unmarshal([]byte(`{"result":true,"flow":{"id":"flow_1","name":"Ventas","steps":[{"id":"start","type":"MESSAGE"}]}}`), result)
+			}
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx := context.Background()
+	
+	flows, err := service.GetFlows(ctx)
+	if err != nil || len(flows.Flows) != 1 {
+		t.Fatalf("GetFlows() = %+v, %v", flows, err)
+	}
+	
+	flow, err := service.CreateFlow(ctx, &CreateFlowRequest{Name: "Ventas", Steps: validFlowSteps()})
+	if err != nil || flow.ID != "flow_1" {
+		t.Fatalf("CreateFlow() = %+v, %v", flow, err)
+	}
+	
+	if flow, err = service.GetFlow(ctx, "flow_1"); err != nil || len(flow.Steps) != 1 {
+		t.Fatalf("GetFlow() = %+v, %v", flow, err)
+	}
+	
+	isActive := true
+	if _, err = service.UpdateFlow(ctx, "flow_1", &UpdateFlowRequest{IsActive: &isActive}); err != nil {
+		t.Fatalf("UpdateFlow() error = %v", err)
+	}
+	
+	if err = service.DeleteFlow(ctx, "flow_1"); err != nil {
+		t.Fatalf("DeleteFlow() error = %v", err)
+	}
+	
+	want := []call{
+		{"GET", "/api/v1/chatFlows"},
+		{"POST", "/api/v1/chatFlows"},
+		{"GET", "/api/v1/chatFlows/flow_1"},
+		{"PUT", "/api/v1/chatFlows/flow_1"},
+		{"DELETE", "/api/v1/chatFlows/flow_1"},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("Expected %d calls, got %+v", len(want), calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("Call %d = %+v, want %+v", i, calls[i], want[i])
+		}
+	}
+	
+	if _, err := service.GetFlow(ctx, ""); err == nil {
+		t.Error("Expected error for empty flow ID")
+	}
+	
+	if err := service.DeleteFlow(ctx, ""); err == nil {
+		t.Error("Expected error for empty flow ID")
+	}
+}
+
+func TestListChatSessions(t *testing.T) {
+	var gotEndpoint string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return json.Unmarshal([]byte(`{"result":true,"page":1,"totalCount":1,"sessions":[{"id":"s1","whatsappNumber":"1234567890","status":"ACTIVE"}]}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	response, err := service.ListChatSessions(context.Background(), &ListChatSessionsParams{
+		Status:    ChatSessionStatusActive,
+		ChatbotID: "bot_1",
+	})
+	if err != nil {
+		t.Fatalf("ListChatSessions() error = %v", err)
+	}
+	
+	wantEndpoint := "/api/v1/chatSessions?chatbotId=bot_1&pageNumber=1&pageSize=20&status=ACTIVE"
+	if gotEndpoint != wantEndpoint {
+		t.Errorf("Expected endpoint %s, got %s", wantEndpoint, gotEndpoint)
+	}
+	
+	if len(response.Sessions) != 1 || !response.Sessions[0].IsActive() {
+		t.Errorf("Unexpected sessions: %+v", response.Sessions)
+	}
+}
+
+func TestGetChatbotSessions(t *testing.T) {
+	var gotEndpoint string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return json.Unmarshal([]byte(`{"result":true,"page":1,"totalCount":2,"sessions":[
+				{"id":"s1","chatbotId":"bot_1","whatsappNumber":"1234567890","status":"ACTIVE"},
+				{"id":"s2","chatbotId":"bot_1","whatsappNumber":"1234567891","status":"ACTIVE"}
+			]}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	response, err := service.GetChatbotSessions(context.Background(), "bot_1", &GetSessionsParams{PageSize: 50})
+	if err != nil {
+		t.Fatalf("GetChatbotSessions() error = %v", err)
+	}
+	
+	wantEndpoint := "/api/v1/chatSessions?chatbotId=bot_1&pageNumber=1&pageSize=50&status=ACTIVE"
+	if gotEndpoint != wantEndpoint {
+		t.Errorf("Expected endpoint %s, got %s", wantEndpoint, gotEndpoint)
+	}
+	
+	if len(response.Sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(response.Sessions))
+	}
+	for _, session := range response.Sessions {
+		if !session.IsActive() {
+			t.Errorf("Expected session %s to be active", session.ID)
+		}
+	}
+	
+	if _, err := service.GetChatbotSessions(context.Background(), "", nil); err == nil {
+		t.Error("Expected error for empty chatbotID")
+	}
+}
+
+func TestGetChatSession(t *testing.T) {
+	var gotEndpoint string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return json.Unmarshal([]byte(`{"result":true,"session":{"id":"s1","whatsappNumber":"1234567890","status":"COMPLETED","startedAt":"2024-01-01T10:00:00Z","endedAt":"2024-01-01T10:05:00Z"}}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	session, err := service.GetChatSession(context.Background(), "+1234567890")
+	if err != nil {
+		t.Fatalf("GetChatSession() error = %v", err)
+	}
+	
+	if gotEndpoint != "/api/v1/chatSessions/1234567890" {
+		t.Errorf("Unexpected endpoint %s", gotEndpoint)
+	}
+	
+	if session.IsActive() {
+		t.Error("Expected ended session to be inactive")
+	}
+	
+	if session.Duration() != 5*time.Minute {
+		t.Errorf("Expected duration 5m, got %v", session.Duration())
+	}
+	
+	if _, err := service.GetChatSession(context.Background(), ""); err == nil {
+		t.Error("Expected error for empty whatsappNumber")
+	}
+}
+
+func TestChatSessionDurationInProgress(t *testing.T) {
+	session := &ChatSession{Status: "active", StartedAt: time.Now().Add(-time.Minute)}
+	
+	if !session.IsActive() {
+		t.Error("Expected session without EndedAt to be active")
+	}
+	
+	if session.Duration() < time.Minute {
+		t.Errorf("Expected duration of at least 1m, got %v", session.Duration())
+	}
+	
+	if (&ChatSession{}).Duration() != 0 {
+		t.Error("Expected zero duration for a session without StartedAt")
+	}
+}
+
+func TestAddTagsToChatPreservesStatus(t *testing.T) {
+	var sent map[string]json.RawMessage
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if method == "GET" {
+				return json.Unmarshal([]byte(`{"result":true,"status":"ASSIGNED","tags":["lead","soporte"]}`), result)
+			}
+			
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			return json.Unmarshal(data, &sent)
+		},
+	}
+	
+	service := NewService(mockClient)
+	if _, err := service.AddTagsToChat(context.Background(), "1234567890", []string{"vip", "lead"}); err != nil {
+		t.Fatalf("AddTagsToChat() error = %v", err)
+	}
+	
+	if _, ok := sent["status"]; ok {
+		t.Errorf("Expected no status in request, got %s", sent["status"])
+	}
+	
+	var got []string
+	if err := json.Unmarshal(sent["tags"], &got); err != nil {
+		t.Fatalf("Expected tags to be sent: %v", err)
+	}
+	
+	want := []string{"lead", "soporte", "vip"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected existing tags to be kept as %v, got %v", want, got)
+	}
+	
+	if _, err := service.AddTagsToChat(context.Background(), "1234567890", nil); err == nil {
+		t.Error("Expected error when no tags are given")
+	}
+}
+
+func TestUpdateChatStatusRequestRequiresStatusWithoutTags(t *testing.T) {
+	req := &UpdateChatStatusRequest{WhatsappNumber: "1234567890"}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected error when neither status nor tags are set")
+	}
+	
+	req.Status = "PENDING"
+	req.Tags = []string{"vip"}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected error for invalid status")
+	}
+}
+
+func TestRemoveTagsFromChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		remove  []string
+		want    []string
+	}{
+		{name: "removes and deduplicates", current: `["vip","lead","vip","soporte"]`, remove: []string{"lead"}, want: []string{"vip", "soporte"}},
+		{name: "last tag", current: `["vip"]`, remove: []string{"vip"}, want: []string{}},
+		{name: "unknown tag", current: `["vip"]`, remove: []string{"otro"}, want: []string{"vip"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent map[string]json.RawMessage
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					if method == "GET" {
+						if endpoint != "/api/v1/getChatStatus/1234567890" {
+							t.Errorf("Unexpected endpoint %s", endpoint)
+						}
+						return json.Unmarshal([]byte(`{"result":true,"status":"ASSIGNED","tags":`+tt.current+`}`), result)
+					}
+					
+					data, err := json.Marshal(body)
+					if err != nil {
+						return err
+					}
+					return json.Unmarshal(data, &sent)
+				},
+			}
+			
+			service := NewService(mockClient)
+			if _, err := service.RemoveTagsFromChat(context.Background(), "1234567890", tt.remove); err != nil {
+				t.Fatalf("RemoveTagsFromChat() error = %v", err)
+			}
+			
+			if _, ok := sent["status"]; ok {
+				t.Error("Expected status not to be sent")
+			}
+			
+			var got []string
+			if err := json.Unmarshal(sent["tags"], &got); err != nil {
+				t.Fatalf("Expected tags to be sent: %v", err)
+			}
+			
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected tags %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected tags %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+// roundTripAction simula el envío y la lectura de una acción desde la API
+func roundTripAction(t *testing.T, action Action) Action {
+	t.Helper()
+	
+	data, err := json.Marshal(action)
+	if err != nil {
+		t.Fatalf("Error marshaling action: %v", err)
+	}
+	
+	var decoded Action
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Error unmarshaling action: %v", err)
+	}
+	
+	return decoded
+}
+
+func TestSendTemplateActionRoundTrip(t *testing.T) {
+	params := []messages.Parameter{
+		{Name: "name", Value: "Juan"},
+		{Name: "order", Value: "A-123"},
+	}
+	
+	action := roundTripAction(t, NewSendTemplateAction("order_update", params))
+	
+	if action.Type != string(ActionTypeSendTemplate) {
+		t.Errorf("Expected type SEND_TEMPLATE, got %s", action.Type)
+	}
+	
+	name, got, ok := action.SendTemplate()
+	if !ok {
+		t.Fatal("Expected SendTemplate() to succeed")
+	}
+	
+	if name != "order_update" {
+		t.Errorf("Expected template order_update, got %s", name)
+	}
+	
+	if len(got) != len(params) || got[0] != params[0] || got[1] != params[1] {
+		t.Errorf("Expected parameters %v, got %v", params, got)
+	}
+	
+	if _, ok := action.AssignedUser(); ok {
+		t.Error("Expected AssignedUser() to fail on a SEND_TEMPLATE action")
+	}
+}
+
+func TestAssignActionRoundTrip(t *testing.T) {
+	action := roundTripAction(t, NewAssignAction("agent_1"))
+	
+	userID, ok := action.AssignedUser()
+	if !ok || userID != "agent_1" {
+		t.Errorf("Expected agent_1, got %q (ok=%v)", userID, ok)
+	}
+	
+	if _, _, ok := action.SendTemplate(); ok {
+		t.Error("Expected SendTemplate() to fail on an ASSIGN_USER action")
+	}
+}
+
+func TestSetVariableActionRoundTrip(t *testing.T) {
+	action := roundTripAction(t, NewSetVariableAction("plan", "gold"))
+	
+	key, value, ok := action.SetVariable()
+	if !ok || key != "plan" || value != "gold" {
+		t.Errorf("Expected plan=gold, got %s=%v (ok=%v)", key, value, ok)
+	}
+}
+
+func TestChatbotIsActive(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{status: "ACTIVE", want: true},
+		{status: "active", want: true},
+		{status: "Active", want: true},
+		{status: "INACTIVE", want: false},
+		{status: "paused", want: false},
+		{status: "", want: false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			chatbot := &Chatbot{Status: tt.status}
+			if got := chatbot.IsActive(); got != tt.want {
+				t.Errorf("IsActive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetActiveChatbots(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return json.Unmarshal([]byte(`{"result":true,"chatbots":[
+				{"id":"1","status":"ACTIVE"},
+				{"id":"2","status":"active"},
+				{"id":"3","status":"INACTIVE"},
+				{"id":"4","status":"Active"}
+			]}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	active, err := service.GetActiveChatbots(context.Background())
+	if err != nil {
+		t.Fatalf("GetActiveChatbots() error = %v", err)
+	}
+	
+	if len(active) != 3 {
+		t.Fatalf("Expected 3 active chatbots, got %d", len(active))
+	}
+	
+	for _, chatbot := range active {
+		if chatbot.ID == "3" {
+			t.Error("Expected inactive chatbot to be filtered out")
+		}
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	ctx := map[string]interface{}{
+		"city":   "Córdoba",
+		"age":    float64(30),
+		"orders": "12",
+		"tags":   []interface{}{"vip", "lead"},
+		"contact": map[string]interface{}{
+			"plan": "gold",
+		},
+	}
+	
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+		wantErr   bool
+	}{
+		{name: "eq string", condition: Condition{Field: "city", Operator: "eq", Value: "Córdoba"}, want: true},
+		{name: "eq number coerced", condition: Condition{Field: "age", Operator: "eq", Value: "30"}, want: true},
+		{name: "neq", condition: Condition{Field: "city", Operator: "neq", Value: "Rosario"}, want: true},
+		{name: "gt numeric string", condition: Condition{Field: "orders", Operator: "gt", Value: 10}, want: true},
+		{name: "lt", condition: Condition{Field: "age", Operator: "lt", Value: 18}, want: false},
+		{name: "gte", condition: Condition{Field: "age", Operator: "gte", Value: 30}, want: true},
+		{name: "gt non numeric", condition: Condition{Field: "city", Operator: "gt", Value: 1}, wantErr: true},
+		{name: "contains substring", condition: Condition{Field: "city", Operator: "contains", Value: "córd"}, want: true},
+		{name: "contains list", condition: Condition{Field: "tags", Operator: "contains", Value: "vip"}, want: true},
+		{name: "in", condition: Condition{Field: "contact.plan", Operator: "in", Value: []string{"gold", "platinum"}}, want: true},
+		{name: "in requires list", condition: Condition{Field: "city", Operator: "in", Value: "gold"}, wantErr: true},
+		{name: "exists", condition: Condition{Field: "contact.plan", Operator: "exists"}, want: true},
+		{name: "not exists", condition: Condition{Field: "email", Operator: "exists"}, want: false},
+		{name: "missing field", condition: Condition{Field: "email", Operator: "eq", Value: ""}, want: false},
+		{name: "operator case", condition: Condition{Field: "city", Operator: "EQ", Value: "Córdoba"}, want: true},
+		{name: "unsupported operator", condition: Condition{Field: "city", Operator: "like", Value: "C%"}, wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.condition.Evaluate(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			
+			if got != tt.want {
+				t.Errorf("Evaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	rule := Rule{
+		IsActive: true,
+		Trigger: Trigger{
+			Type:     string(TriggerTypeKeyword),
+			Keywords: []string{"precio"},
+		},
+		Conditions: []Condition{
+			{Field: "plan", Operator: "in", Value: []interface{}{"gold", "platinum"}},
+		},
+	}
+	
+	if !rule.Matches("¿Cuál es el PRECIO?", map[string]interface{}{"plan": "gold"}) {
+		t.Error("Expected rule to match keyword and conditions")
+	}
+	
+	if rule.Matches("hola", map[string]interface{}{"plan": "gold"}) {
+		t.Error("Expected rule not to match without the keyword")
+	}
+	
+	if rule.Matches("precio", map[string]interface{}{"plan": "free"}) {
+		t.Error("Expected rule not to match when a condition fails")
+	}
+	
+	pattern := Rule{IsActive: true, Trigger: Trigger{Type: string(TriggerTypePattern), Pattern: `^pedido \d+$`}}
+	if !pattern.Matches("pedido 123", nil) || pattern.Matches("pedido abc", nil) {
+		t.Error("Expected pattern trigger to match only numeric orders")
+	}
+	
+	event := Rule{IsActive: true, Trigger: Trigger{Type: string(TriggerTypeEvent), Event: "contact_created"}}
+	if !event.Matches("contact_created", nil) {
+		t.Error("Expected event trigger to match")
+	}
+	
+	rule.IsActive = false
+	if rule.Matches("precio", map[string]interface{}{"plan": "gold"}) {
+		t.Error("Expected inactive rule not to match")
+	}
+}
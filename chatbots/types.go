@@ -1,8 +1,14 @@
 package chatbots
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
+	"github.com/diogenes-moreira/wati-sdk/messages"
 )
 
 // Chatbot representa un chatbot en WATI
@@ -80,10 +86,11 @@ type ChatbotResponse struct {
 	Status    string  `json:"status"`
 }
 
-// UpdateChatStatusRequest representa la petición para actualizar estado de chat
+// UpdateChatStatusRequest representa la petición para actualizar estado de chat.
+// Status puede omitirse si solo se envían Tags; el chat conserva su estado.
 type UpdateChatStatusRequest struct {
 	WhatsappNumber string `json:"whatsappNumber"`
-	Status         string `json:"status"`
+	Status         string `json:"status,omitempty"`
 	AssignedTo     string `json:"assignedTo,omitempty"`
 	Tags           []string `json:"tags,omitempty"`
 	Notes          string `json:"notes,omitempty"`
@@ -95,6 +102,7 @@ type ChatStatusResponse struct {
 	WhatsappNumber string    `json:"whatsappNumber"`
 	Status         string    `json:"status"`
 	AssignedTo     string    `json:"assignedTo,omitempty"`
+	Tags           []string  `json:"tags,omitempty"`
 	UpdatedAt      time.Time `json:"updatedAt"`
 }
 
@@ -136,6 +144,54 @@ type ChatSession struct {
 	Variables      map[string]interface{} `json:"variables,omitempty"`
 }
 
+// ChatSessionStatus representa los posibles estados de una sesión de chatbot
+type ChatSessionStatus string
+
+const (
+	ChatSessionStatusActive    ChatSessionStatus = "ACTIVE"
+	ChatSessionStatusCompleted ChatSessionStatus = "COMPLETED"
+	ChatSessionStatusExpired   ChatSessionStatus = "EXPIRED"
+)
+
+// PaginatedResponse representa una respuesta paginada
+type PaginatedResponse = common.PaginatedResponse
+
+// ChatSessionsResponse representa la respuesta de lista de sesiones de chat
+type ChatSessionsResponse struct {
+	BaseResponse
+	PaginatedResponse
+	Sessions []ChatSession `json:"sessions"`
+}
+
+// ListChatSessionsParams representa los parámetros para listar sesiones de chat
+type ListChatSessionsParams struct {
+	Status     ChatSessionStatus `json:"status,omitempty"`
+	ChatbotID  string            `json:"chatbotId,omitempty"`
+	PageSize   int               `json:"pageSize,omitempty"`
+	PageNumber int               `json:"pageNumber,omitempty"`
+}
+
+// GetSessionsParams representa los parámetros para listar las sesiones de un
+// chatbot. Sin Status se listan solo las sesiones activas.
+type GetSessionsParams struct {
+	Status     ChatSessionStatus `json:"status,omitempty"`
+	PageSize   int               `json:"pageSize,omitempty"`
+	PageNumber int               `json:"pageNumber,omitempty"`
+}
+
+// SessionVariablesResponse representa las variables de flujo de la sesión de un contacto
+type SessionVariablesResponse struct {
+	BaseResponse
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// SetSessionVariableRequest representa la petición para asignar una variable de flujo
+type SetSessionVariableRequest struct {
+	WhatsappNumber string      `json:"-"`
+	Key            string      `json:"key"`
+	Value          interface{} `json:"value"`
+}
+
 // ChatFlow representa un flujo de conversación
 type ChatFlow struct {
 	ID          string     `json:"id"`
@@ -160,6 +216,29 @@ type FlowStep struct {
 	Variables   map[string]interface{} `json:"variables,omitempty"`
 }
 
+// FlowsResponse representa la respuesta de lista de flujos
+type FlowsResponse struct {
+	BaseResponse
+	Flows []ChatFlow `json:"flows"`
+}
+
+// CreateFlowRequest representa la petición para crear un flujo
+type CreateFlowRequest struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Steps       []FlowStep `json:"steps"`
+	IsActive    bool       `json:"isActive"`
+}
+
+// UpdateFlowRequest representa la petición para actualizar un flujo. Steps
+// reemplaza todos los pasos del flujo; nil los deja sin cambios.
+type UpdateFlowRequest struct {
+	Name        *string    `json:"name,omitempty"`
+	Description *string    `json:"description,omitempty"`
+	Steps       []FlowStep `json:"steps,omitempty"`
+	IsActive    *bool      `json:"isActive,omitempty"`
+}
+
 // FlowOption representa una opción en un paso de flujo
 type FlowOption struct {
 	ID       string `json:"id"`
@@ -206,6 +285,17 @@ const (
 	TriggerTypeInactivity  TriggerType = "INACTIVITY"
 )
 
+// FlowStepType representa los tipos de pasos de un flujo
+type FlowStepType string
+
+const (
+	FlowStepTypeMessage   FlowStepType = "MESSAGE"
+	FlowStepTypeQuestion  FlowStepType = "QUESTION"
+	FlowStepTypeCondition FlowStepType = "CONDITION"
+	FlowStepTypeAction    FlowStepType = "ACTION"
+	FlowStepTypeEnd       FlowStepType = "END"
+)
+
 // ActionType representa los tipos de acciones
 type ActionType string
 
@@ -220,7 +310,8 @@ const (
 	ActionTypeTransferToHuman ActionType = "TRANSFER_TO_HUMAN"
 )
 
-// Validate valida la petición de inicio de chatbot
+// Validate valida la petición de inicio de chatbot y deja WhatsappNumber
+// normalizado
 func (r *StartChatbotRequest) Validate() error {
 	if r.ChatbotID == "" {
 		return fmt.Errorf("chatbotId is required")
@@ -230,48 +321,67 @@ func (r *StartChatbotRequest) Validate() error {
 		return fmt.Errorf("whatsappNumber is required")
 	}
 	
-	// Validación básica del número de teléfono
-	if len(r.WhatsappNumber) < 10 {
-		return fmt.Errorf("whatsappNumber must be at least 10 digits")
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
 	}
 	
 	return nil
 }
 
-// Validate valida la petición de actualización de estado de chat
+// Validate valida la petición de actualización de estado de chat y deja
+// WhatsappNumber normalizado
 func (r *UpdateChatStatusRequest) Validate() error {
 	if r.WhatsappNumber == "" {
 		return fmt.Errorf("whatsappNumber is required")
 	}
 	
-	if r.Status == "" {
+	if r.Status == "" && len(r.Tags) == 0 {
 		return fmt.Errorf("status is required")
 	}
 	
 	// Validar que el estado sea válido
-	validStatuses := []string{
-		string(ChatStatusOpen),
-		string(ChatStatusAssigned),
-		string(ChatStatusResolved),
-		string(ChatStatusClosed),
-		string(ChatStatusBot),
-	}
-	
-	isValid := false
-	for _, status := range validStatuses {
-		if r.Status == status {
-			isValid = true
-			break
+	if r.Status != "" {
+		validStatuses := []string{
+			string(ChatStatusOpen),
+			string(ChatStatusAssigned),
+			string(ChatStatusResolved),
+			string(ChatStatusClosed),
+			string(ChatStatusBot),
+		}
+		
+		isValid := false
+		for _, status := range validStatuses {
+			if r.Status == status {
+				isValid = true
+				break
+			}
+		}
+		
+		if !isValid {
+			return fmt.Errorf("invalid status: %s. Valid statuses are: %v", r.Status, validStatuses)
 		}
 	}
 	
-	if !isValid {
-		return fmt.Errorf("invalid status: %s. Valid statuses are: %v", r.Status, validStatuses)
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+// Validate valida la petición de asignación de una variable de sesión y deja
+// WhatsappNumber normalizado
+func (r *SetSessionVariableRequest) Validate() error {
+	if r.WhatsappNumber == "" {
+		return fmt.Errorf("whatsappNumber is required")
 	}
 	
-	// Validación básica del número de teléfono
-	if len(r.WhatsappNumber) < 10 {
-		return fmt.Errorf("whatsappNumber must be at least 10 digits")
+	if strings.TrimSpace(r.Key) == "" {
+		return fmt.Errorf("key is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
 	}
 	
 	return nil
@@ -301,9 +411,143 @@ func (r *CreateChatbotRequest) Validate() error {
 	return nil
 }
 
-// IsActive verifica si el chatbot está activo
+// Validate valida la petición de creación de flujo
+func (r *CreateFlowRequest) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	
+	return validateFlowSteps(r.Steps)
+}
+
+// Validate valida la petición de actualización de flujo
+func (r *UpdateFlowRequest) Validate() error {
+	if r.Name != nil && *r.Name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	
+	if r.Steps == nil {
+		return nil
+	}
+	
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	
+	return validateFlowSteps(r.Steps)
+}
+
+// validateFlowSteps verifica que cada paso tenga un ID único y un tipo válido, y
+// que los NextStep de los pasos y sus opciones apunten a pasos existentes
+func validateFlowSteps(steps []FlowStep) error {
+	validTypes := []FlowStepType{
+		FlowStepTypeMessage,
+		FlowStepTypeQuestion,
+		FlowStepTypeCondition,
+		FlowStepTypeAction,
+		FlowStepTypeEnd,
+	}
+	
+	ids := make(map[string]bool, len(steps))
+	for i, step := range steps {
+		if step.ID == "" {
+			return fmt.Errorf("step ID is required for step %d", i)
+		}
+		
+		if ids[step.ID] {
+			return fmt.Errorf("duplicate step ID %q", step.ID)
+		}
+		ids[step.ID] = true
+		
+		isValid := false
+		for _, stepType := range validTypes {
+			if FlowStepType(step.Type) == stepType {
+				isValid = true
+				break
+			}
+		}
+		
+		if !isValid {
+			return fmt.Errorf("invalid type %q for step %s. Valid types are: %v", step.Type, step.ID, validTypes)
+		}
+	}
+	
+	// Validar referencias una vez conocidos todos los IDs
+	for _, step := range steps {
+		if step.NextStep != "" && !ids[step.NextStep] {
+			return fmt.Errorf("step %s references unknown next step %q", step.ID, step.NextStep)
+		}
+		
+		for j, option := range step.Options {
+			if option.NextStep != "" && !ids[option.NextStep] {
+				return fmt.Errorf("option %d of step %s references unknown next step %q", j, step.ID, option.NextStep)
+			}
+		}
+	}
+	
+	return nil
+}
+
+// ToMap convierte ListChatSessionsParams a un mapa para query parameters
+func (p *ListChatSessionsParams) ToMap() map[string]string {
+	params := make(map[string]string)
+	
+	if p.Status != "" {
+		params["status"] = string(p.Status)
+	}
+	
+	if p.ChatbotID != "" {
+		params["chatbotId"] = p.ChatbotID
+	}
+	
+	if p.PageSize > 0 {
+		params["pageSize"] = strconv.Itoa(p.PageSize)
+	}
+	
+	if p.PageNumber > 0 {
+		params["pageNumber"] = strconv.Itoa(p.PageNumber)
+	}
+	
+	return params
+}
+
+// SetDefaults establece valores por defecto para ListChatSessionsParams
+func (p *ListChatSessionsParams) SetDefaults() {
+	if p.PageSize <= 0 {
+		p.PageSize = 20
+	}
+	
+	if p.PageNumber <= 0 {
+		p.PageNumber = 1
+	}
+}
+
+// IsActive verifica si la sesión sigue en curso
+func (s *ChatSession) IsActive() bool {
+	return s.EndedAt == nil && strings.EqualFold(s.Status, string(ChatSessionStatusActive))
+}
+
+// Duration retorna cuánto duró la sesión, o cuánto lleva si sigue en curso
+func (s *ChatSession) Duration() time.Duration {
+	if s.StartedAt.IsZero() {
+		return 0
+	}
+	
+	if s.EndedAt != nil {
+		return s.EndedAt.Sub(s.StartedAt)
+	}
+	
+	return time.Since(s.StartedAt)
+}
+
+// IsActive verifica si el chatbot está activo. La comparación no distingue
+// mayúsculas porque WATI no es consistente con el formato del estado.
 func (c *Chatbot) IsActive() bool {
-	return c.Status == "active"
+	return strings.EqualFold(c.Status, string(ChatbotStatusActive))
 }
 
 // GetActiveRules retorna solo las reglas activas del chatbot
@@ -355,3 +599,126 @@ func (c *Chatbot) RemoveKeyword(keyword string) {
 	}
 }
 
+// Claves de Action.Parameters usadas por los constructores tipados
+const (
+	actionParamTemplateParameters = "parameters"
+	actionParamVariableKey        = "key"
+	actionParamVariableValue      = "value"
+)
+
+// NewSendMessageAction crea una acción que envía un mensaje de texto
+func NewSendMessageAction(message string) Action {
+	return Action{
+		Type:    string(ActionTypeSendMessage),
+		Message: message,
+	}
+}
+
+// NewSendTemplateAction crea una acción que envía la plantilla name con params
+func NewSendTemplateAction(name string, params []messages.Parameter) Action {
+	return Action{
+		Type:     string(ActionTypeSendTemplate),
+		Template: name,
+		Parameters: map[string]interface{}{
+			actionParamTemplateParameters: params,
+		},
+	}
+}
+
+// NewAssignAction crea una acción que asigna el chat al usuario userID
+func NewAssignAction(userID string) Action {
+	return Action{
+		Type:     string(ActionTypeAssignUser),
+		AssignTo: userID,
+	}
+}
+
+// NewAddTagAction crea una acción que agrega etiquetas al contacto
+func NewAddTagAction(tags ...string) Action {
+	return Action{
+		Type:      string(ActionTypeAddTag),
+		TagsToAdd: tags,
+	}
+}
+
+// NewRemoveTagAction crea una acción que quita etiquetas del contacto
+func NewRemoveTagAction(tags ...string) Action {
+	return Action{
+		Type:         string(ActionTypeRemoveTag),
+		TagsToRemove: tags,
+	}
+}
+
+// NewSetVariableAction crea una acción que asigna una variable de flujo
+func NewSetVariableAction(key string, value interface{}) Action {
+	return Action{
+		Type: string(ActionTypeSetVariable),
+		Parameters: map[string]interface{}{
+			actionParamVariableKey:   key,
+			actionParamVariableValue: value,
+		},
+	}
+}
+
+// NewWaitAction crea una acción que espera delay segundos
+func NewWaitAction(delay int) Action {
+	return Action{
+		Type:  string(ActionTypeWait),
+		Delay: delay,
+	}
+}
+
+// NewTransferToHumanAction crea una acción que transfiere el chat a un agente.
+// userID puede quedar vacío para usar la asignación automática de WATI.
+func NewTransferToHumanAction(userID string) Action {
+	return Action{
+		Type:     string(ActionTypeTransferToHuman),
+		AssignTo: userID,
+	}
+}
+
+// SendTemplate retorna la plantilla y sus parámetros de una acción SEND_TEMPLATE.
+// Funciona tanto con acciones creadas con NewSendTemplateAction como con las
+// recibidas de la API, donde Parameters llega como JSON genérico.
+func (a *Action) SendTemplate() (string, []messages.Parameter, bool) {
+	if a.Type != string(ActionTypeSendTemplate) {
+		return "", nil, false
+	}
+	
+	var params []messages.Parameter
+	if raw, ok := a.Parameters[actionParamTemplateParameters]; ok {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return "", nil, false
+		}
+		
+		if err := json.Unmarshal(data, &params); err != nil {
+			return "", nil, false
+		}
+	}
+	
+	return a.Template, params, true
+}
+
+// AssignedUser retorna el usuario de una acción ASSIGN_USER
+func (a *Action) AssignedUser() (string, bool) {
+	if a.Type != string(ActionTypeAssignUser) {
+		return "", false
+	}
+	
+	return a.AssignTo, true
+}
+
+// SetVariable retorna la clave y el valor de una acción SET_VARIABLE
+func (a *Action) SetVariable() (string, interface{}, bool) {
+	if a.Type != string(ActionTypeSetVariable) {
+		return "", nil, false
+	}
+	
+	key, ok := a.Parameters[actionParamVariableKey].(string)
+	if !ok {
+		return "", nil, false
+	}
+	
+	return key, a.Parameters[actionParamVariableValue], true
+}
@@ -0,0 +1,158 @@
+package wati
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CircuitState representa el estado del circuit breaker del cliente
+type CircuitState string
+
+const (
+	// CircuitClosed deja pasar todas las peticiones
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rechaza las peticiones sin enviarlas hasta que pase la espera
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen deja pasar una única petición de prueba
+	CircuitHalfOpen CircuitState = "half-open"
+)
+
+// CircuitBreakerConfig configura el circuit breaker
+type CircuitBreakerConfig struct {
+	// FailureThreshold es la cantidad de fallos consecutivos que abre el circuito
+	FailureThreshold int
+	// Cooldown es el tiempo que el circuito permanece abierto
+	Cooldown time.Duration
+}
+
+// circuitBreaker corta las peticiones mientras WATI falla de forma sostenida.
+// Cuenta como fallo cada llamada que terminó, tras agotar los reintentos, en
+// un error de red o una respuesta 5xx; el resto de las respuestas indican que
+// el servidor responde y cierran el circuito.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	// trial indica que la petición de prueba del estado half-open está en curso
+	trial bool
+}
+
+// newCircuitBreaker crea un circuit breaker cerrado, o nil si config no lo habilita
+func newCircuitBreaker(config *CircuitBreakerConfig) *circuitBreaker {
+	if config == nil || config.FailureThreshold <= 0 {
+		return nil
+	}
+	
+	return &circuitBreaker{
+		threshold: config.FailureThreshold,
+		cooldown:  config.Cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// allow indica si la petición puede enviarse. Con el circuito abierto retorna
+// un WATIError equivalente a ErrCircuitOpen con la espera restante en RetryAfter.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	switch b.state {
+	case CircuitOpen:
+		remaining := b.cooldown - b.now().Sub(b.openedAt)
+		if remaining > 0 {
+			return circuitOpenError(remaining)
+		}
+		
+		// Terminó la espera: esta petición es la prueba
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return nil
+	
+	case CircuitHalfOpen:
+		if b.trial {
+			return circuitOpenError(0)
+		}
+		b.trial = true
+		return nil
+	}
+	
+	return nil
+}
+
+// done registra el resultado de una petición autorizada por allow
+func (b *circuitBreaker) done(statusCode int, err error) {
+	if b == nil {
+		return
+	}
+	
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	b.trial = false
+	
+	var netErr *NetworkError
+	failed := statusCode >= 500 ||
+		(statusCode == 0 && errors.As(err, &netErr) && !errors.Is(err, context.Canceled))
+	
+	switch {
+	case failed:
+		b.failures++
+		if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	
+	case statusCode > 0:
+		b.state = CircuitClosed
+		b.failures = 0
+	}
+	
+	// Sin respuesta ni error de red (por ejemplo, contexto cancelado) no se
+	// sabe nada del servidor: el estado se mantiene y otra petición puede
+	// hacer la prueba
+}
+
+// currentState retorna el estado del circuito, considerando abierto como
+// half-open una vez terminada la espera
+func (b *circuitBreaker) currentState() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	
+	return b.state
+}
+
+// circuitOpenError crea el error retornado mientras el circuito está abierto
+func circuitOpenError(retryAfter time.Duration) *WATIError {
+	return &WATIError{
+		Code:       ErrCircuitOpen.Code,
+		Message:    ErrCircuitOpen.Message,
+		Type:       ErrCircuitOpen.Type,
+		RetryAfter: retryAfter,
+	}
+}
+
+// CircuitState retorna el estado del circuit breaker del cliente. Sin
+// WithCircuitBreaker es siempre CircuitClosed.
+func (c *Client) CircuitState() CircuitState {
+	return c.breaker.currentState()
+}
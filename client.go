@@ -4,19 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/diogenes-moreira/wati-sdk/chatbots"
+	"github.com/diogenes-moreira/wati-sdk/contacts"
+	"github.com/diogenes-moreira/wati-sdk/media"
+	"github.com/diogenes-moreira/wati-sdk/messages"
+	"github.com/diogenes-moreira/wati-sdk/webhooks"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
-	"github.com/tu-usuario/go-wati/contacts"
-	"github.com/tu-usuario/go-wati/messages"
-	"github.com/tu-usuario/go-wati/chatbots"
-	"github.com/tu-usuario/go-wati/media"
-	"github.com/tu-usuario/go-wati/webhooks"
 )
 
 // WATIClient es la interfaz principal del cliente WATI
@@ -34,18 +40,39 @@ type WATIClient interface {
 	GetConfig() *Config
 	
 	// Utilidades
+	Close() error
 	ValidateToken() error
 	RotateToken() (*TokenResponse, error)
+	GetAccountInfo(ctx context.Context) (*AccountInfo, error)
+	CircuitState() CircuitState
+	RecordedRequests() []RecordedRequest
+	ResetRecordedRequests()
 	
 	// HTTP client interno
 	DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
+	DoRequestWithStatus(ctx context.Context, method, endpoint string, body interface{}, result interface{}) (int, error)
+	DoStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error)
 }
 
 // Client implementa WATIClient
 type Client struct {
-	config   *Config
-	client   *http.Client
-	limiter  *rate.Limiter
+	config      *Config
+	httpClient  *http.Client
+	rateLimiter *rate.Limiter
+	tracer      trace.Tracer
+	breaker     *circuitBreaker
+	logger      Logger
+	
+	// Contexto raíz que Close cancela para abortar las peticiones en curso
+	rootCtx    context.Context
+	cancelRoot context.CancelFunc
+	
+	// Endpoints obsoletos ya advertidos en el log
+	deprecationWarned sync.Map
+	
+	// Peticiones capturadas en modo dry-run
+	dryRunMu       sync.Mutex
+	dryRunRequests []RecordedRequest
 	
 	// Servicios
 	contacts  ContactsService
@@ -72,15 +99,34 @@ func NewClient(apiEndpoint, token string, options ...ClientOption) WATIClient {
 		config.RateLimit.BurstSize,
 	)
 	
-	// Crear cliente HTTP
-	httpClient := &http.Client{
-		Timeout: config.Timeout,
+	// Crear cliente HTTP salvo que el usuario haya provisto uno propio
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: config.Timeout,
+		}
 	}
 	
+	rootCtx, cancelRoot := context.WithCancel(context.Background())
+	
 	client := &Client{
 		config:      config,
 		httpClient:  httpClient,
 		rateLimiter: rateLimiter,
+		rootCtx:     rootCtx,
+		cancelRoot:  cancelRoot,
+		breaker:     newCircuitBreaker(config.CircuitBreaker),
+		logger:      config.Logger,
+	}
+	
+	// WithDebug sin un logger propio usa el logger estándar
+	if client.logger == nil && config.Debug {
+		client.logger = log.Default()
+	}
+	
+	// El tracing solo se habilita si se configuró un TracerProvider
+	if config.TracerProvider != nil {
+		client.tracer = config.TracerProvider.Tracer(tracerName)
 	}
 	
 	// Inicializar servicios
@@ -91,8 +137,13 @@ func NewClient(apiEndpoint, token string, options ...ClientOption) WATIClient {
 
 // initServices inicializa todos los servicios
 func (c *Client) initServices() {
-	c.contacts = contacts.NewService(c)
-	c.messages = messages.NewService(c)
+	contactsService := contacts.NewService(c)
+	c.contacts = contactsService
+	messageOptions := []messages.Option{messages.WithContactLookup(contactsService)}
+	if c.config.QuotaGuard {
+		messageOptions = append(messageOptions, messages.WithQuotaChecker(newQuotaGuard(c.GetAccountInfo, quotaCacheTTL)))
+	}
+	c.messages = messages.NewService(c, messageOptions...)
 	c.chatbots = chatbots.NewService(c)
 	c.media = media.NewService(c)
 	c.webhooks = webhooks.NewService(c)
@@ -138,15 +189,64 @@ func (c *Client) GetConfig() *Config {
 	return c.config
 }
 
+// Close cancela todas las peticiones en curso del cliente. Las peticiones
+// iniciadas después fallan con ErrClientClosed. Es seguro llamarlo varias veces.
+func (c *Client) Close() error {
+	c.cancelRoot()
+	return nil
+}
+
+// withClientContext combina ctx con el contexto raíz del cliente, de modo que
+// la petición se cancela al cancelar ctx o al llamar a Close
+func (c *Client) withClientContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if c.rootCtx.Err() != nil {
+		return nil, nil, ErrClientClosed
+	}
+	
+	merged, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.rootCtx, cancel)
+	
+	return merged, func() {
+		stop()
+		cancel()
+	}, nil
+}
+
+// cancelOnClose libera el contexto de una petición en streaming cuando el
+// llamador cierra el cuerpo de la respuesta
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implementa io.Closer
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// backgroundContext crea el contexto acotado por BackgroundTimeout que usan los
+// métodos que no reciben un contexto del llamador
+func (c *Client) backgroundContext() (context.Context, context.CancelFunc) {
+	timeout := c.config.BackgroundTimeout
+	if timeout <= 0 {
+		timeout = DefaultBackgroundTimeout
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
 // ValidateToken valida el token actual
 func (c *Client) ValidateToken() error {
-	ctx := context.Background()
+	ctx, cancel := c.backgroundContext()
+	defer cancel()
 	
 	// Intentar hacer una petición simple para validar el token
 	var result BaseResponse
 	err := c.DoRequest(ctx, "GET", "/api/v1/chatbots", nil, &result)
 	if err != nil {
-		if watiErr, ok := err.(*WATIError); ok && watiErr.IsAuthenticationError() {
+		var watiErr *WATIError
+		if errors.As(err, &watiErr) && watiErr.IsAuthenticationError() {
 			return ErrInvalidToken
 		}
 		return err
@@ -157,7 +257,8 @@ func (c *Client) ValidateToken() error {
 
 // RotateToken rota el token de autenticación
 func (c *Client) RotateToken() (*TokenResponse, error) {
-	ctx := context.Background()
+	ctx, cancel := c.backgroundContext()
+	defer cancel()
 	
 	var result TokenResponse
 	err := c.DoRequest(ctx, "POST", "/api/v1/rotateToken", nil, &result)
@@ -171,43 +272,80 @@ func (c *Client) RotateToken() (*TokenResponse, error) {
 	return &result, nil
 }
 
-// DoRequest realiza una petición HTTP a la API de WATI
+// GetAccountInfo obtiene el plan, el límite de mensajes y el número conectado
+// de la cuenta, útil para verificar la cuota antes de enviar
+func (c *Client) GetAccountInfo(ctx context.Context) (*AccountInfo, error) {
+	var result AccountInfo
+	err := c.DoRequest(ctx, "GET", "/api/v1/getAccountInfo", nil, &result)
+	if err != nil {
+		return nil, fmt.Errorf("error getting account info: %w", err)
+	}
+	
+	return &result, nil
+}
+
+// DoRequest realiza una petición HTTP a la API de WATI. Una respuesta 2xx sin
+// cuerpo (por ejemplo 204 No Content) es un éxito y deja result sin modificar.
 func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	return c.doRequest(ctx, method, endpoint, body, result, nil)
+}
+
+// DoRequestWithStatus es como DoRequest pero además retorna el código de
+// estado HTTP de la última respuesta recibida, o 0 si no se recibió ninguna
+func (c *Client) DoRequestWithStatus(ctx context.Context, method, endpoint string, body interface{}, result interface{}) (int, error) {
+	var status int
+	err := c.doRequest(ctx, method, endpoint, body, result, &status)
+	return status, err
+}
+
+// doRequest implementa DoRequest y, si status no es nil, guarda en él el
+// código de estado de la respuesta
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}, status *int) (err error) {
+	ctx, release, err := c.withClientContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	
+	ctx, span := c.startSpan(ctx, method, endpoint)
+	var statusCode, retries int
+	defer func() {
+		span.end(statusCode, retries, err)
+		if status != nil {
+			*status = statusCode
+		}
+	}()
+	
+	// Con el circuito abierto la petición no se envía
+	if err := c.breaker.allow(); err != nil {
+		return err
+	}
+	defer func() { c.breaker.done(statusCode, err) }()
+	
 	// Aplicar rate limiting
 	if err := c.rateLimiter.Wait(ctx); err != nil {
 		return fmt.Errorf("rate limiter error: %w", err)
 	}
 	
 	// Construir URL completa
-	fullURL := c.config.APIEndpoint + endpoint
+	fullURL := c.config.APIEndpoint + c.applyDefaultQueryParams(c.resolveEndpoint(ctx, endpoint))
 	
 	// Preparar el cuerpo de la petición
-	var bodyReader io.Reader
+	var bodyBytes []byte
 	if body != nil {
-		bodyBytes, err := json.Marshal(body)
+		bodyBytes, err = json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("error marshaling request body: %w", err)
 		}
-		bodyReader = bytes.NewReader(bodyBytes)
-	}
-	
-	// Crear la petición
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
-	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		c.logBody("request", method, endpoint, bodyBytes)
 	}
 	
-	// Establecer headers
-	req.Header.Set("Authorization", "Bearer "+c.config.Token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "go-wati/1.0.0")
-	
 	// Realizar la petición con reintentos
 	var resp *http.Response
 	var lastErr error
 	
-	for attempt := 0; attempt <= c.config.RetryCount; attempt++ {
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		retries = attempt
 		if attempt > 0 {
 			// Esperar antes del reintento
 			select {
@@ -217,9 +355,27 @@ func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body in
 			}
 		}
 		
-		resp, lastErr = c.httpClient.Do(req)
+		// Crear la petición en cada intento para que el cuerpo pueda volver a enviarse
+		var bodyReader io.Reader
+		if bodyBytes != nil {
+			bodyReader = bytes.NewReader(bodyBytes)
+		}
+		
+		req, err := http.NewRequestWithContext(ctx, method, fullURL, bodyReader)
+		if err != nil {
+			return fmt.Errorf("error creating request: %w", err)
+		}
+		
+		// Establecer headers
+		req.Header.Set("Authorization", "Bearer "+c.config.Token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("User-Agent", c.config.UserAgent)
+		span.inject(req)
+		
+		resp, lastErr = c.send(req, endpoint)
 		if lastErr != nil {
-			if attempt == c.config.RetryCount {
+			if attempt == c.config.MaxRetries {
 				return &NetworkError{
 					Operation: fmt.Sprintf("%s %s", method, endpoint),
 					Err:       lastErr,
@@ -227,18 +383,19 @@ func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body in
 			}
 			continue
 		}
+		statusCode = resp.StatusCode
 		
 		// Si la respuesta es exitosa o no es reintentable, salir del bucle
 		if resp.StatusCode < 500 && resp.StatusCode != 429 {
 			break
 		}
 		
-		resp.Body.Close()
-		
 		// Si es el último intento, no cerrar la respuesta aquí
-		if attempt == c.config.RetryCount {
+		if attempt == c.config.MaxRetries {
 			break
 		}
+		
+		resp.Body.Close()
 	}
 	
 	if resp == nil {
@@ -250,55 +407,317 @@ func (c *Client) DoRequest(ctx context.Context, method, endpoint string, body in
 	
 	defer resp.Body.Close()
 	
+	c.reportDeprecation(endpoint, resp.Header)
+	
 	// Leer el cuerpo de la respuesta
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("error reading response body: %w", err)
 	}
+	c.logBody("response", method, endpoint, respBody)
 	
 	// Verificar el código de estado
 	if resp.StatusCode >= 400 {
-		// Intentar parsear el error de la API
-		var apiError struct {
-			Error   string `json:"error"`
-			Message string `json:"message"`
+		return parseErrorResponse(resp, respBody)
+	}
+	
+	// Parsear la respuesta exitosa; un cuerpo vacío no tiene nada que decodificar
+	if result != nil && len(bytes.TrimSpace(respBody)) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("error unmarshaling response: %w", err)
 		}
-		
-		if json.Unmarshal(respBody, &apiError) == nil && apiError.Error != "" {
-			return NewWATIError(resp.StatusCode, apiError.Error)
+	}
+	
+	return nil
+}
+
+// DoStreamRequest realiza una petición HTTP con un cuerpo arbitrario sin cargarlo
+// en memoria y retorna la respuesta sin procesar. El llamador debe cerrar el
+// cuerpo de la respuesta. Estas peticiones no se reintentan porque el cuerpo
+// no puede volver a leerse.
+func (c *Client) DoStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (resp *http.Response, err error) {
+	ctx, release, err := c.withClientContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	// El contexto debe seguir vivo mientras se lee el cuerpo de la respuesta;
+	// se libera al cerrarlo o de inmediato si la petición falla
+	defer func() {
+		if err != nil {
+			release()
+			return
+		}
+		resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: release}
+	}()
+	
+	ctx, span := c.startSpan(ctx, method, endpoint)
+	var statusCode int
+	defer func() { span.end(statusCode, 0, err) }()
+	
+	// Con el circuito abierto la petición no se envía
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.done(statusCode, err) }()
+	
+	// Aplicar rate limiting
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+	
+	fullURL := c.config.APIEndpoint + c.applyDefaultQueryParams(c.resolveEndpoint(ctx, endpoint))
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	
+	// Establecer headers
+	req.Header.Set("Authorization", "Bearer "+c.config.Token)
+	req.Header.Set("User-Agent", c.config.UserAgent)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	span.inject(req)
+	
+	resp, err = c.send(req, endpoint)
+	if err != nil {
+		return nil, &NetworkError{
+			Operation: fmt.Sprintf("%s %s", method, endpoint),
+			Err:       err,
 		}
+	}
+	statusCode = resp.StatusCode
+	
+	c.reportDeprecation(endpoint, resp.Header)
+	
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
 		
-		if json.Unmarshal(respBody, &apiError) == nil && apiError.Message != "" {
-			return NewWATIError(resp.StatusCode, apiError.Message)
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading response body: %w", err)
 		}
 		
-		return NewWATIError(resp.StatusCode, string(respBody))
+		return nil, parseErrorResponse(resp, respBody)
 	}
 	
-	// Parsear la respuesta exitosa
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("error unmarshaling response: %w", err)
+	return resp, nil
+}
+
+// apiVersionKey es la clave de contexto para la versión de API por petición
+type apiVersionKey struct{}
+
+// ContextWithAPIVersion retorna un contexto que fuerza la versión de API de las
+// peticiones realizadas con él, por encima de la configurada en el cliente
+func ContextWithAPIVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, apiVersionKey{}, version)
+}
+
+// resolveEndpoint aplica la versión de API configurada a los endpoints /api/v1/...
+func (c *Client) resolveEndpoint(ctx context.Context, endpoint string) string {
+	version := c.config.APIVersion
+	if v, ok := ctx.Value(apiVersionKey{}).(string); ok && v != "" {
+		version = v
+	}
+	
+	version = strings.Trim(version, "/")
+	if version == "" || version == DefaultAPIVersion {
+		return endpoint
+	}
+	
+	const defaultPrefix = "/api/" + DefaultAPIVersion + "/"
+	if !strings.HasPrefix(endpoint, defaultPrefix) {
+		return endpoint
+	}
+	
+	return "/api/" + version + "/" + strings.TrimPrefix(endpoint, defaultPrefix)
+}
+
+// applyDefaultQueryParams agrega los parámetros de consulta por defecto al
+// endpoint. Los parámetros presentes en la llamada tienen prioridad y la parte
+// existente de la query se conserva tal cual.
+func (c *Client) applyDefaultQueryParams(endpoint string) string {
+	if len(c.config.DefaultQueryParams) == 0 {
+		return endpoint
+	}
+	
+	path, rawQuery, _ := strings.Cut(endpoint, "?")
+	existing, _ := url.ParseQuery(rawQuery)
+	
+	defaults := url.Values{}
+	for key, value := range c.config.DefaultQueryParams {
+		if _, ok := existing[key]; !ok {
+			defaults.Set(key, value)
 		}
 	}
 	
-	return nil
+	if len(defaults) == 0 {
+		return endpoint
+	}
+	
+	if rawQuery != "" {
+		rawQuery += "&"
+	}
+	
+	return path + "?" + rawQuery + defaults.Encode()
+}
+
+// send ejecuta una petición HTTP aplicando los interceptores configurados.
+// Los interceptores de respuesta reciben nil si la petición falló a nivel de red.
+// endpoint es el indicado por el llamador, que es el que ve el modo dry-run.
+func (c *Client) send(req *http.Request, endpoint string) (*http.Response, error) {
+	for _, intercept := range c.config.RequestInterceptors {
+		intercept(req)
+	}
+	
+	start := time.Now()
+	var resp *http.Response
+	var err error
+	if c.config.DryRun != nil {
+		resp, err = c.dryRun(req, endpoint)
+	} else {
+		resp, err = c.httpClient.Do(req)
+	}
+	elapsed := time.Since(start)
+	
+	c.logAttempt(req, resp, err, elapsed)
+	
+	for _, intercept := range c.config.ResponseInterceptors {
+		intercept(resp, elapsed)
+	}
+	
+	return resp, err
+}
+
+// parseErrorResponse convierte una respuesta fallida en un WATIError
+func parseErrorResponse(resp *http.Response, respBody []byte) *WATIError {
+	watiErr := parseErrorBody(resp.StatusCode, respBody)
+	watiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		watiErr.AllowedMethods = parseAllowHeader(resp.Header.Values("Allow"))
+	}
+	return watiErr
+}
+
+// parseAllowHeader extrae los métodos aceptados del header Allow, que puede venir
+// repetido o como lista separada por comas
+func parseAllowHeader(values []string) []string {
+	var methods []string
+	for _, value := range values {
+		for _, method := range strings.Split(value, ",") {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if method != "" {
+				methods = append(methods, method)
+			}
+		}
+	}
+	
+	return methods
 }
 
-// buildURL construye una URL con parámetros de consulta
-func (c *Client) buildURL(endpoint string, params map[string]string) string {
-	u, _ := url.Parse(c.config.APIEndpoint + endpoint)
+// parseErrorBody convierte el cuerpo de una respuesta fallida en un WATIError.
+// Además del mensaje extrae los errores por campo y el resto de los datos
+// estructurados; si el cuerpo no es JSON se usa tal cual como mensaje.
+func parseErrorBody(statusCode int, respBody []byte) *WATIError {
+	var body map[string]interface{}
+	if json.Unmarshal(respBody, &body) != nil {
+		return NewWATIError(statusCode, string(respBody))
+	}
+	
+	message, _ := body["error"].(string)
+	if message == "" {
+		message, _ = body["message"].(string)
+	}
+	if message == "" {
+		message = string(respBody)
+	}
+	
+	watiErr := NewWATIError(statusCode, message)
 	
-	if len(params) > 0 {
-		q := u.Query()
-		for key, value := range params {
-			if value != "" {
-				q.Set(key, value)
+	for _, key := range []string{"errors", "validationErrors"} {
+		watiErr.ValidationErrors = append(watiErr.ValidationErrors, parseValidationErrors(body[key])...)
+	}
+	
+	// El resto de los campos se conserva en Details
+	details := make(map[string]interface{})
+	for key, value := range body {
+		switch key {
+		case "error", "message", "errors", "validationErrors", "result":
+			continue
+		}
+		details[key] = value
+	}
+	if len(details) > 0 {
+		watiErr.Details = details
+	}
+	
+	return watiErr
+}
+
+// parseValidationErrors interpreta los errores por campo, ya sea como lista de
+// objetos {"field", "message"} o como mapa campo -> mensaje(s)
+func parseValidationErrors(raw interface{}) []ValidationError {
+	var result []ValidationError
+	
+	switch errs := raw.(type) {
+	case []interface{}:
+		for _, item := range errs {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			field, _ := entry["field"].(string)
+			message, _ := entry["message"].(string)
+			if field != "" || message != "" {
+				result = append(result, ValidationError{Field: field, Message: message})
+			}
+		}
+		
+	case map[string]interface{}:
+		fields := make([]string, 0, len(errs))
+		for field := range errs {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		
+		for _, field := range fields {
+			switch messages := errs[field].(type) {
+			case string:
+				result = append(result, ValidationError{Field: field, Message: messages})
+			case []interface{}:
+				for _, message := range messages {
+					if text, ok := message.(string); ok {
+						result = append(result, ValidationError{Field: field, Message: text})
+					}
+				}
 			}
 		}
-		u.RawQuery = q.Encode()
 	}
 	
-	return u.String()
+	return result
 }
 
+// parseRetryAfter interpreta el header Retry-After, expresado en segundos o como
+// fecha HTTP. Retorna cero si el header falta o no es válido.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay
+		}
+	}
+	
+	return 0
+}
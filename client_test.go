@@ -2,12 +2,48 @@ package wati
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+	
+	"github.com/diogenes-moreira/wati-sdk/contacts"
+	"github.com/diogenes-moreira/wati-sdk/messages"
+	"github.com/diogenes-moreira/wati-sdk/webhooks"
 )
 
+// Verificación en tiempo de compilación de que Client implementa WATIClient
+var _ WATIClient = (*Client)(nil)
+
+func TestNewClientInternals(t *testing.T) {
+	client, ok := NewClient("https://test.wati.io/", "test-token", WithTimeout(10)).(*Client)
+	if !ok {
+		t.Fatal("NewClient() did not return *Client")
+	}
+	
+	if client.httpClient == nil {
+		t.Error("httpClient not initialized")
+	}
+	
+	if client.rateLimiter == nil {
+		t.Error("rateLimiter not initialized")
+	}
+	
+	if client.config.APIEndpoint != "https://test.wati.io" {
+		t.Errorf("Expected endpoint without trailing slash, got %s", client.config.APIEndpoint)
+	}
+	
+	if client.httpClient.Timeout != client.config.Timeout {
+		t.Errorf("Expected HTTP client timeout %v, got %v", client.config.Timeout, client.httpClient.Timeout)
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -96,6 +132,20 @@ func TestClientOptions(t *testing.T) {
 	}
 }
 
+func TestClientWithHTTPClient(t *testing.T) {
+	customClient := &http.Client{Timeout: 90 * time.Second}
+	
+	client := NewClient("https://test.wati.io", "test-token", WithHTTPClient(customClient), WithTimeout(5)).(*Client)
+	
+	if client.httpClient != customClient {
+		t.Error("Expected custom HTTP client to be used")
+	}
+	
+	if customClient.Timeout != 90*time.Second {
+		t.Errorf("Expected custom client timeout to be preserved, got %v", customClient.Timeout)
+	}
+}
+
 func TestClientDoRequest(t *testing.T) {
 	// Crear servidor de prueba
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -139,6 +189,46 @@ func TestClientDoRequest(t *testing.T) {
 	}
 }
 
+func TestClientDoRequestEmptyBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+	}{
+		{name: "no content", statusCode: http.StatusNoContent},
+		{name: "empty ok", statusCode: http.StatusOK},
+		{name: "whitespace ok", statusCode: http.StatusOK, body: "\n"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+			
+			client := NewClient(server.URL, "test-token")
+			
+			var response struct {
+				Result bool `json:"result"`
+			}
+			
+			if err := client.DoRequest(context.Background(), "POST", "/test", nil, &response); err != nil {
+				t.Fatalf("DoRequest() error = %v", err)
+			}
+			
+			status, err := client.DoRequestWithStatus(context.Background(), "POST", "/test", nil, &response)
+			if err != nil {
+				t.Fatalf("DoRequestWithStatus() error = %v", err)
+			}
+			if status != tt.statusCode {
+				t.Errorf("Expected status %d, got %d", tt.statusCode, status)
+			}
+		})
+	}
+}
+
 func TestClientDoRequestWithError(t *testing.T) {
 	// Servidor que retorna error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -164,7 +254,8 @@ func TestClientDoRequestWithError(t *testing.T) {
 	}
 	
 	// Verificar que es un APIError
-	if apiErr, ok := err.(*APIError); ok {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
 		if apiErr.Code != http.StatusBadRequest {
 			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, apiErr.Code)
 		}
@@ -173,6 +264,217 @@ func TestClientDoRequestWithError(t *testing.T) {
 	}
 }
 
+func TestWATIErrorIsAndAs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "token expired"}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	// El error atraviesa el fmt.Errorf("...: %w") de la capa de servicios
+	_, err := client.Contacts().GetContacts(context.Background(), nil)
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+	
+	var apiErr *WATIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected errors.As to find *WATIError in %v", err)
+	}
+	
+	if apiErr.Code != http.StatusUnauthorized || apiErr.Message != "token expired" {
+		t.Errorf("Unexpected WATIError %+v", apiErr)
+	}
+	
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Error("Expected errors.Is to match ErrInvalidToken")
+	}
+	
+	if errors.Is(err, ErrResourceNotFound) {
+		t.Error("Did not expect errors.Is to match ErrResourceNotFound")
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	retryAfter := "7"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", retryAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": "rate limit exceeded"}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithRetries(0))
+	
+	err := client.DoRequest(context.Background(), "GET", "/test", nil, nil)
+	
+	var apiErr *WATIError
+	if !errors.As(err, &apiErr) || !apiErr.IsRateLimitError() {
+		t.Fatalf("Expected rate limit WATIError, got %v", err)
+	}
+	
+	if got := apiErr.GetRetryAfter(); got != 7*time.Second {
+		t.Errorf("Expected RetryAfter 7s, got %s", got)
+	}
+}
+
+func TestClientRetryWebhookDeliveryConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"error": "delivery already completed"}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithRetries(0))
+	
+	err := client.Webhooks().RetryWebhookDelivery(context.Background(), "https://example.com/webhook", "dlv-123")
+	if !errors.Is(err, webhooks.ErrDeliveryAlreadySucceeded) {
+		t.Errorf("Expected ErrDeliveryAlreadySucceeded, got %v", err)
+	}
+}
+
+func TestClientMethodNotAllowedAllowHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		w.Write([]byte(`{"error": "method not allowed"}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithRetries(0))
+	
+	err := client.DoRequest(context.Background(), "GET", "/api/v1/sendTemplateMessage", nil, nil)
+	
+	var apiErr *WATIError
+	if !errors.As(err, &apiErr) || !apiErr.IsMethodNotAllowedError() {
+		t.Fatalf("Expected method not allowed WATIError, got %v", err)
+	}
+	
+	if len(apiErr.AllowedMethods) != 1 || apiErr.AllowedMethods[0] != "POST" {
+		t.Errorf("Expected AllowedMethods [POST], got %v", apiErr.AllowedMethods)
+	}
+	
+	if !strings.Contains(err.Error(), "allowed methods: POST") {
+		t.Errorf("Expected allowed methods in error message, got %q", err.Error())
+	}
+	
+	if !errors.Is(err, ErrMethodNotAllowed) {
+		t.Error("Expected errors.Is to match ErrMethodNotAllowed")
+	}
+}
+
+func TestParseAllowHeader(t *testing.T) {
+	got := parseAllowHeader([]string{"get, post", "PUT", " "})
+	want := []string{"GET", "POST", "PUT"}
+	
+	if len(got) != len(want) {
+		t.Fatalf("parseAllowHeader() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseAllowHeader()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+	
+	if methods := parseAllowHeader(nil); methods != nil {
+		t.Errorf("Expected nil for missing header, got %v", methods)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{name: "seconds", value: "120", want: 2 * time.Minute},
+		{name: "http date", value: now.Add(30 * time.Second).Format(http.TimeFormat), want: 30 * time.Second},
+		{name: "past http date", value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{name: "missing", value: "", want: 0},
+		{name: "invalid", value: "soon", want: 0},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrorBody(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantMessage    string
+		wantValidation []ValidationError
+		wantDetailKey  string
+	}{
+		{
+			name:        "plain text",
+			body:        "Bad Gateway",
+			wantMessage: "Bad Gateway",
+		},
+		{
+			name:        "error string only",
+			body:        `{"result": false, "error": "invalid number"}`,
+			wantMessage: "invalid number",
+		},
+		{
+			name:        "field errors as list",
+			body:        `{"message": "validation failed", "code": "E1001", "errors": [{"field": "whatsappNumber", "message": "is invalid"}]}`,
+			wantMessage: "validation failed",
+			wantValidation: []ValidationError{
+				{Field: "whatsappNumber", Message: "is invalid"},
+			},
+			wantDetailKey: "code",
+		},
+		{
+			name:        "field errors as map",
+			body:        `{"error": "validation failed", "errors": {"name": ["is required", "is too short"], "email": "is invalid"}}`,
+			wantMessage: "validation failed",
+			wantValidation: []ValidationError{
+				{Field: "email", Message: "is invalid"},
+				{Field: "name", Message: "is required"},
+				{Field: "name", Message: "is too short"},
+			},
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := parseErrorBody(http.StatusBadRequest, []byte(tt.body))
+			
+			if err.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, err.Message)
+			}
+			
+			if len(err.ValidationErrors) != len(tt.wantValidation) {
+				t.Fatalf("Expected %d validation errors, got %v", len(tt.wantValidation), err.ValidationErrors)
+			}
+			for i, want := range tt.wantValidation {
+				if err.ValidationErrors[i] != want {
+					t.Errorf("Validation error %d = %+v, want %+v", i, err.ValidationErrors[i], want)
+				}
+			}
+			
+			if tt.wantDetailKey != "" {
+				if _, ok := err.Details[tt.wantDetailKey]; !ok {
+					t.Errorf("Expected details to contain %q, got %v", tt.wantDetailKey, err.Details)
+				}
+			} else if err.Details != nil {
+				t.Errorf("Expected no details, got %v", err.Details)
+			}
+		})
+	}
+}
+
 func TestClientRateLimit(t *testing.T) {
 	requestCount := 0
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -222,7 +524,7 @@ func TestClientTimeout(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client := NewClient(server.URL, "test-token", WithTimeout(1)) // 1 segundo timeout
+	client := NewClient(server.URL, "test-token", WithTimeout(1), WithRetries(0)) // 1 segundo timeout, sin reintentos
 	
 	ctx := context.Background()
 	
@@ -283,6 +585,180 @@ func TestClientRetries(t *testing.T) {
 	}
 }
 
+func TestClientInterceptors(t *testing.T) {
+	requestCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestCount++
+		
+		// Fallar la primera request para forzar un reintento
+		if requestCount == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	var interceptedRequests int
+	var statuses []int
+	
+	client := NewClient(
+		server.URL,
+		"test-token",
+		WithRetries(1),
+		WithRequestInterceptor(func(req *http.Request) {
+			interceptedRequests++
+		}),
+		WithResponseInterceptor(func(resp *http.Response, elapsed time.Duration) {
+			if resp != nil {
+				statuses = append(statuses, resp.StatusCode)
+			}
+		}),
+	)
+	
+	err := client.DoRequest(context.Background(), "POST", "/test", map[string]string{"key": "value"}, nil)
+	if err != nil {
+		t.Errorf("DoRequest() error = %v", err)
+		return
+	}
+	
+	if interceptedRequests != 2 {
+		t.Errorf("Expected request interceptor to be called 2 times, got %d", interceptedRequests)
+	}
+	
+	if len(statuses) != 2 || statuses[0] != http.StatusInternalServerError || statuses[1] != http.StatusOK {
+		t.Errorf("Expected statuses [500 200], got %v", statuses)
+	}
+}
+
+func TestClientAPIVersion(t *testing.T) {
+	var lastPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	tests := []struct {
+		name     string
+		options  []ClientOption
+		ctx      context.Context
+		wantPath string
+	}{
+		{
+			name:     "default version",
+			ctx:      context.Background(),
+			wantPath: "/api/v1/getContacts",
+		},
+		{
+			name:     "client version",
+			options:  []ClientOption{WithAPIVersion("v2")},
+			ctx:      context.Background(),
+			wantPath: "/api/v2/getContacts",
+		},
+		{
+			name:     "per-request override",
+			options:  []ClientOption{WithAPIVersion("v2")},
+			ctx:      ContextWithAPIVersion(context.Background(), "v3"),
+			wantPath: "/api/v3/getContacts",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(server.URL, "test-token", tt.options...)
+			
+			if err := client.DoRequest(tt.ctx, "GET", "/api/v1/getContacts", nil, nil); err != nil {
+				t.Fatalf("DoRequest() error = %v", err)
+			}
+			
+			if lastPath != tt.wantPath {
+				t.Errorf("Expected path %s, got %s", tt.wantPath, lastPath)
+			}
+		})
+	}
+}
+
+func TestClientDefaultQueryParams(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithDefaultQueryParams(map[string]string{
+		"channelId": "channel 1&2",
+	}))
+	
+	if _, err := client.Contacts().GetContacts(context.Background(), nil); err != nil {
+		t.Fatalf("GetContacts() error = %v", err)
+	}
+	
+	if got := lastQuery.Get("channelId"); got != "channel 1&2" {
+		t.Errorf("Expected default channelId param, got %q", got)
+	}
+	
+	if lastQuery.Get("pageSize") == "" {
+		t.Error("Expected service query params to be preserved")
+	}
+	
+	// Un parámetro de la llamada no debe ser reemplazado por el valor por defecto
+	if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts?channelId=override", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	if got := lastQuery["channelId"]; len(got) != 1 || got[0] != "override" {
+		t.Errorf("Expected per-call channelId to win, got %v", got)
+	}
+}
+
+func TestClientGetAccountInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v1/getAccountInfo" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{
+			"result": true,
+			"plan": "Pro",
+			"messagingLimit": 10000,
+			"messagingTier": "TIER_10K",
+			"qualityRating": "GREEN",
+			"connectedNumber": "5491112345678"
+		}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	info, err := client.GetAccountInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetAccountInfo() error = %v", err)
+	}
+	
+	if !info.Result || info.Plan != "Pro" || info.MessagingLimit != 10000 {
+		t.Errorf("Unexpected account info: %+v", info)
+	}
+	
+	if info.MessagingTier != "TIER_10K" || info.QualityRating != "GREEN" {
+		t.Errorf("Unexpected tier or quality: %+v", info)
+	}
+	
+	if info.ConnectedNumber != "5491112345678" {
+		t.Errorf("Expected connected number 5491112345678, got %s", info.ConnectedNumber)
+	}
+}
+
 func TestClientContextCancellation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(2 * time.Second)
@@ -351,3 +827,681 @@ func BenchmarkClientDoRequest(b *testing.B) {
 	}
 }
 
+func TestClientValidateTokenBackgroundTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// El servidor nunca responde hasta que el cliente abandona la petición
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	
+	client := NewClient(server.URL, "test-token",
+		WithHTTPClient(&http.Client{}),
+		WithBackgroundTimeout(200*time.Millisecond),
+	)
+	
+	start := time.Now()
+	err := client.ValidateToken()
+	elapsed := time.Since(start)
+	
+	if err == nil {
+		t.Fatal("Expected timeout error, got nil")
+	}
+	
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	
+	if elapsed > 2*time.Second {
+		t.Errorf("ValidateToken took %v, expected to return within the background timeout", elapsed)
+	}
+}
+
+func TestClientQuotaGuardBlocksBulkSend(t *testing.T) {
+	var accountInfoCalls, sendCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/getAccountInfo":
+			atomic.AddInt32(&accountInfoCalls, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"result": true, "messagingLimit": 1000, "messagesSent": 998}`))
+		default:
+			atomic.AddInt32(&sendCalls, 1)
+			w.Write([]byte(`{"result": true}`))
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithQuotaGuard(true))
+	
+	req := &messages.SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_octubre",
+		Recipients: []messages.TemplateMessageRecipient{
+			{WhatsappNumber: "5491100000001"},
+			{WhatsappNumber: "5491100000002"},
+			{WhatsappNumber: "5491100000003"},
+		},
+	}
+	
+	for i := 0; i < 2; i++ {
+		_, err := client.Messages().SendTemplateMessages(context.Background(), req)
+		if !errors.Is(err, ErrQuotaExceeded) {
+			t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+		}
+	}
+	
+	if got := atomic.LoadInt32(&sendCalls); got != 0 {
+		t.Errorf("Expected the send to be blocked, got %d send requests", got)
+	}
+	
+	// La cuota se cachea entre envíos
+	if got := atomic.LoadInt32(&accountInfoCalls); got != 1 {
+		t.Errorf("Expected 1 account info request, got %d", got)
+	}
+}
+
+func TestQuotaGuardCacheExpires(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	guard := newQuotaGuard(func(ctx context.Context) (*AccountInfo, error) {
+		calls++
+		return &AccountInfo{MessagingLimit: 100, MessagesSent: calls * 10}, nil
+	}, time.Minute)
+	guard.now = func() time.Time { return now }
+	
+	remaining, _ := guard.RemainingQuota(context.Background())
+	if remaining != 90 {
+		t.Errorf("Expected 90 remaining, got %d", remaining)
+	}
+	
+	now = now.Add(30 * time.Second)
+	if remaining, _ = guard.RemainingQuota(context.Background()); remaining != 90 || calls != 1 {
+		t.Errorf("Expected cached quota, got %d after %d calls", remaining, calls)
+	}
+	
+	now = now.Add(time.Minute)
+	if remaining, _ = guard.RemainingQuota(context.Background()); remaining != 80 || calls != 2 {
+		t.Errorf("Expected refreshed quota, got %d after %d calls", remaining, calls)
+	}
+}
+
+func TestClientCloseCancelsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+	
+	client := NewClient(server.URL, "test-token",
+		WithHTTPClient(&http.Client{}),
+		WithRetries(0),
+	)
+	
+	errCh := make(chan error, 1)
+	go func() {
+		var result BaseResponse
+		errCh <- client.DoRequest(context.Background(), "GET", "/api/v1/slow", nil, &result)
+	}()
+	
+	<-started
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not cancel the in-flight request")
+	}
+	
+	// Las peticiones posteriores fallan sin llegar al servidor
+	var result BaseResponse
+	err := client.DoRequest(context.Background(), "GET", "/api/v1/slow", nil, &result)
+	if !errors.Is(err, ErrClientClosed) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ErrClientClosed, got %v", err)
+	}
+	
+	if _, err := client.DoStreamRequest(context.Background(), "POST", "/api/v1/uploadMedia", nil, ""); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Expected ErrClientClosed from DoStreamRequest, got %v", err)
+	}
+}
+
+func TestClientDeprecationNotice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", "Wed, 31 Dec 2025 23:59:59 GMT")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	type notice struct {
+		endpoint string
+		sunset   string
+	}
+	var notices []notice
+	client := NewClient(server.URL, "test-token",
+		WithHTTPClient(&http.Client{}),
+		WithDeprecationNotice(func(endpoint, sunset string) {
+			notices = append(notices, notice{endpoint, sunset})
+		}),
+	)
+	
+	var result BaseResponse
+	for i := 0; i < 2; i++ {
+		if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts?pageSize=10", nil, &result); err != nil {
+			t.Fatalf("DoRequest() error = %v", err)
+		}
+	}
+	
+	if len(notices) != 2 {
+		t.Fatalf("Expected the callback on every response, got %d calls", len(notices))
+	}
+	
+	want := notice{"/api/v1/getContacts", "Wed, 31 Dec 2025 23:59:59 GMT"}
+	if notices[0] != want {
+		t.Errorf("Expected notice %+v, got %+v", want, notices[0])
+	}
+}
+
+func TestClientNoDeprecationNotice(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	called := false
+	client := NewClient(server.URL, "test-token",
+		WithHTTPClient(&http.Client{}),
+		WithDeprecationNotice(func(endpoint, sunset string) { called = true }),
+	)
+	
+	var result BaseResponse
+	if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts", nil, &result); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	if called {
+		t.Error("Expected no deprecation notice without Deprecation or Sunset headers")
+	}
+}
+
+func TestClientDryRun(t *testing.T) {
+	client := NewClient("https://live-server.wati.io", "test-token",
+		WithRetries(0),
+		WithDryRun(func(method, endpoint string, body []byte) ([]byte, int, error) {
+			switch endpoint {
+			case "/api/v1/addContact/5511999999999":
+				return []byte(`{"result": true, "message": "created"}`), http.StatusOK, nil
+			case "/api/v1/missing":
+				return []byte(`{"result": false, "message": "not found"}`), http.StatusNotFound, nil
+			}
+			return nil, 0, errors.New("unexpected endpoint")
+		}),
+	)
+	
+	var response struct {
+		Result  bool   `json:"result"`
+		Message string `json:"message"`
+	}
+	
+	err := client.DoRequest(context.Background(), "POST", "/api/v1/addContact/5511999999999", map[string]string{"name": "Ana"}, &response)
+	if err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	if !response.Result || response.Message != "created" {
+		t.Errorf("Expected canned response, got %+v", response)
+	}
+	
+	err = client.DoRequest(context.Background(), "GET", "/api/v1/missing", nil, nil)
+	var watiErr *WATIError
+	if !errors.As(err, &watiErr) || watiErr.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 WATIError, got %v", err)
+	}
+	
+	err = client.DoRequest(context.Background(), "GET", "/api/v1/other", nil, nil)
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) {
+		t.Errorf("Expected NetworkError from responder error, got %v", err)
+	}
+	
+	recorded := client.RecordedRequests()
+	if len(recorded) != 3 {
+		t.Fatalf("Expected 3 recorded requests, got %d", len(recorded))
+	}
+	if recorded[0].Method != "POST" || recorded[0].Endpoint != "/api/v1/addContact/5511999999999" {
+		t.Errorf("Unexpected first request: %+v", recorded[0])
+	}
+	if string(recorded[0].Body) != `{"name":"Ana"}` {
+		t.Errorf("Expected marshaled body, got %s", recorded[0].Body)
+	}
+	if recorded[1].Body != nil {
+		t.Errorf("Expected no body for GET, got %s", recorded[1].Body)
+	}
+	
+	client.ResetRecordedRequests()
+	if len(client.RecordedRequests()) != 0 {
+		t.Error("Expected no recorded requests after reset")
+	}
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	var requests, healthy atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if healthy.Load() == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"message": "down"}`))
+			return
+		}
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithRetries(0), WithCircuitBreaker(2, time.Minute)).(*Client)
+	
+	// Reloj controlado para no esperar el cooldown real
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+	
+	ctx := context.Background()
+	
+	if state := client.CircuitState(); state != CircuitClosed {
+		t.Fatalf("Expected closed circuit, got %s", state)
+	}
+	
+	// Dos fallos consecutivos abren el circuito
+	for i := 0; i < 2; i++ {
+		if err := client.DoRequest(ctx, "GET", "/test", nil, nil); err == nil {
+			t.Fatal("Expected server error")
+		}
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Fatalf("Expected open circuit, got %s", state)
+	}
+	
+	// Con el circuito abierto no se envía nada
+	err := client.DoRequest(ctx, "GET", "/test", nil, nil)
+	var watiErr *WATIError
+	if !errors.Is(err, ErrCircuitOpen) || !errors.As(err, &watiErr) || watiErr.RetryAfter != time.Minute {
+		t.Errorf("Expected ErrCircuitOpen with RetryAfter, got %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 requests to reach the server, got %d", got)
+	}
+	
+	// Pasado el cooldown se permite una prueba; si falla, vuelve a abrirse
+	now = now.Add(time.Minute)
+	if state := client.CircuitState(); state != CircuitHalfOpen {
+		t.Fatalf("Expected half-open circuit, got %s", state)
+	}
+	if err := client.DoRequest(ctx, "GET", "/test", nil, nil); errors.Is(err, ErrCircuitOpen) || err == nil {
+		t.Fatalf("Expected trial request to reach the server, got %v", err)
+	}
+	if state := client.CircuitState(); state != CircuitOpen {
+		t.Fatalf("Expected circuit to reopen after failed trial, got %s", state)
+	}
+	
+	// Una prueba exitosa cierra el circuito
+	now = now.Add(time.Minute)
+	healthy.Store(1)
+	if err := client.DoRequest(ctx, "GET", "/test", nil, nil); err != nil {
+		t.Fatalf("Expected trial request to succeed, got %v", err)
+	}
+	if state := client.CircuitState(); state != CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", state)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("Expected 4 requests to reach the server, got %d", got)
+	}
+}
+
+func TestCircuitBreakerSingleTrial(t *testing.T) {
+	breaker := newCircuitBreaker(&CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Second})
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	
+	breaker.allow()
+	breaker.done(http.StatusBadGateway, &WATIError{Code: http.StatusBadGateway})
+	
+	now = now.Add(time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("Expected trial request to be allowed, got %v", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected concurrent request to be rejected during trial, got %v", err)
+	}
+	
+	// Un 4xx indica que el servidor responde y cierra el circuito
+	breaker.done(http.StatusNotFound, &WATIError{Code: http.StatusNotFound})
+	if state := breaker.currentState(); state != CircuitClosed {
+		t.Errorf("Expected closed circuit, got %s", state)
+	}
+	
+	if newCircuitBreaker(nil).currentState() != CircuitClosed {
+		t.Error("Expected disabled breaker to report closed")
+	}
+}
+
+func TestClientDebugLoggingRedacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": true, "webhook": {"url": "https://example.com/hook", "secret": "whsec-response"}}`))
+	}))
+	defer server.Close()
+	
+	var lines []string
+	logger := LoggerFunc(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+	
+	client := NewClient(server.URL, "super-secret-token", WithLogger(logger), WithLogBodies(true))
+	
+	body := map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"secret": "whsec-request",
+		"nested": map[string]interface{}{"token": "super-secret-token"},
+	}
+	if err := client.DoRequest(context.Background(), "POST", "/api/v1/webhooks?apiKey=key-123", body, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	output := strings.Join(lines, "\n")
+	for _, leaked := range []string{"super-secret-token", "whsec-request", "whsec-response", "key-123"} {
+		if strings.Contains(output, leaked) {
+			t.Errorf("Expected %q to be redacted from logs:\n%s", leaked, output)
+		}
+	}
+	
+	if !strings.Contains(output, "POST /api/v1/webhooks") || !strings.Contains(output, "-> 200") {
+		t.Errorf("Expected request line with status in logs:\n%s", output)
+	}
+	if !strings.Contains(output, "https://example.com/hook") {
+		t.Errorf("Expected non-sensitive body fields in logs:\n%s", output)
+	}
+}
+
+func TestClientDebugLoggingWithoutBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	var lines []string
+	logger := LoggerFunc(func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	})
+	
+	client := NewClient(server.URL, "test-token", WithLogger(logger))
+	if err := client.DoRequest(context.Background(), "POST", "/test", map[string]string{"name": "Ana"}, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	if len(lines) != 1 {
+		t.Fatalf("Expected a single request line, got %q", lines)
+	}
+	if strings.Contains(lines[0], "Ana") {
+		t.Errorf("Expected no body without WithLogBodies, got %q", lines[0])
+	}
+}
+
+func TestDownloadMediaStreamsBody(t *testing.T) {
+	content := strings.Repeat("0123456789", 10000)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/getMedia" || r.URL.Query().Get("fileName") != "data/foto 1.jpg" {
+			t.Errorf("Unexpected request %s", r.URL.RequestURI())
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	var buf strings.Builder
+	written, err := client.Media().DownloadMedia(context.Background(), "data/foto 1.jpg", &buf)
+	if err != nil {
+		t.Fatalf("DownloadMedia() error = %v", err)
+	}
+	
+	if written != int64(len(content)) {
+		t.Errorf("Expected %d bytes written, got %d", len(content), written)
+	}
+	
+	if buf.String() != content {
+		t.Error("Expected downloaded content to match the response body")
+	}
+}
+
+func TestDownloadMediaNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"result": false, "error": "file not found"}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	var buf strings.Builder
+	written, err := client.Media().DownloadMedia(context.Background(), "missing.jpg", &buf)
+	if err == nil {
+		t.Fatal("Expected error for missing file")
+	}
+	
+	var watiErr *WATIError
+	if !errors.As(err, &watiErr) {
+		t.Fatalf("Expected *WATIError, got %T: %v", err, err)
+	}
+	
+	if watiErr.Code != http.StatusNotFound {
+		t.Errorf("Expected code 404, got %d", watiErr.Code)
+	}
+	
+	if written != 0 || buf.Len() != 0 {
+		t.Errorf("Expected nothing written, got %d bytes", written)
+	}
+}
+
+func TestDownloadMediaCancelledMidBody(t *testing.T) {
+	chunk := strings.Repeat("x", 4096)
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(len(chunk)*10))
+		w.Write([]byte(chunk))
+		w.(http.Flusher).Flush()
+		
+		// Simular un cuerpo lento que no termina hasta que el cliente corta
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	w := &cancelAfterWriter{cancel: cancel, limit: len(chunk)}
+	
+	start := time.Now()
+	written, err := client.Media().DownloadMedia(ctx, "slow.jpg", w)
+	if err == nil {
+		t.Fatal("Expected error when the context is cancelled during the download")
+	}
+	
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	
+	if written < int64(len(chunk)) || written >= int64(len(chunk)*10) {
+		t.Errorf("Expected a partial download, got %d bytes", written)
+	}
+	
+	if time.Since(start) > 2*time.Second {
+		t.Error("Expected the download to stop promptly after cancellation")
+	}
+}
+
+// cancelAfterWriter cancela el contexto una vez recibidos limit bytes
+type cancelAfterWriter struct {
+	cancel  context.CancelFunc
+	limit   int
+	written int
+}
+
+func (w *cancelAfterWriter) Write(p []byte) (int, error) {
+	w.written += len(p)
+	if w.written >= w.limit {
+		w.cancel()
+	}
+	return len(p), nil
+}
+
+func TestClientQuotaGuardConsumesCachedQuota(t *testing.T) {
+	var sendCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/getAccountInfo":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"result": true, "messagingLimit": 1000, "messagesSent": 996}`))
+		default:
+			atomic.AddInt32(&sendCalls, 1)
+			w.Write([]byte(`{"result": true}`))
+		}
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token", WithQuotaGuard(true))
+	
+	req := &messages.SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_octubre",
+		Recipients: []messages.TemplateMessageRecipient{
+			{WhatsappNumber: "5491100000001"},
+			{WhatsappNumber: "5491100000002"},
+			{WhatsappNumber: "5491100000003"},
+		},
+	}
+	
+	// Quedan 4 mensajes: el primer envío pasa y el segundo ya no alcanza
+	if _, err := client.Messages().SendTemplateMessages(context.Background(), req); err != nil {
+		t.Fatalf("SendTemplateMessages() error = %v", err)
+	}
+	
+	if _, err := client.Messages().SendTemplateMessages(context.Background(), req); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded on the second send, got %v", err)
+	}
+	
+	if got := atomic.LoadInt32(&sendCalls); got != 1 {
+		t.Errorf("Expected 1 send request, got %d", got)
+	}
+}
+
+func TestClientContactNotFoundSentinel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": true, "contacts": [{"id": "c1", "phone": "5491199999999"}]}`))
+	}))
+	defer server.Close()
+	
+	client := NewClient(server.URL, "test-token")
+	
+	_, err := client.Contacts().GetContactByPhone(context.Background(), "5491112345678")
+	if !errors.Is(err, ErrContactNotFound) {
+		t.Errorf("Expected wati.ErrContactNotFound, got %v", err)
+	}
+	
+	if !errors.Is(err, contacts.ErrContactNotFound) {
+		t.Errorf("Expected contacts.ErrContactNotFound, got %v", err)
+	}
+	
+	// El centinela raíz sigue siendo un WATIError
+	if ErrContactNotFound.Code != http.StatusNotFound || ErrContactNotFound.Type != "contact_error" {
+		t.Errorf("Unexpected ErrContactNotFound %+v", ErrContactNotFound)
+	}
+	
+	if errors.Is(err, ErrResourceNotFound) {
+		t.Error("Expected a missing contact not to match ErrResourceNotFound")
+	}
+}
+
+func TestClientDeprecationWarningUsesLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	var warnings []string
+	client := NewClient(server.URL, "test-token",
+		WithLogger(LoggerFunc(func(format string, args ...interface{}) {
+			if line := fmt.Sprintf(format, args...); strings.Contains(line, "deprecated") {
+				warnings = append(warnings, line)
+			}
+		})),
+	)
+	
+	var result BaseResponse
+	for i := 0; i < 2; i++ {
+		if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts", nil, &result); err != nil {
+			t.Fatalf("DoRequest() error = %v", err)
+		}
+	}
+	
+	if len(warnings) != 1 {
+		t.Errorf("Expected one deprecation warning through the logger, got %v", warnings)
+	}
+}
+
+func TestClientDryRunRecordsCallerEndpoint(t *testing.T) {
+	var responderEndpoint string
+	client := NewClient("https://live-server.wati.io/12345", "test-token",
+		WithAPIVersion("v2"),
+		WithDefaultQueryParams(map[string]string{"channel": "ventas"}),
+		WithDryRun(func(method, endpoint string, body []byte) ([]byte, int, error) {
+			responderEndpoint = endpoint
+			return []byte(`{"result": true}`), http.StatusOK, nil
+		}),
+	)
+	
+	if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts?pageSize=10", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	// El endpoint no depende de las opciones del base URL
+	if responderEndpoint != "/api/v1/getContacts?pageSize=10" {
+		t.Errorf("Expected the caller's endpoint in the responder, got %s", responderEndpoint)
+	}
+	
+	recorded := client.RecordedRequests()
+	if len(recorded) != 1 {
+		t.Fatalf("Expected 1 recorded request, got %d", len(recorded))
+	}
+	
+	if recorded[0].Endpoint != "/api/v1/getContacts?pageSize=10" {
+		t.Errorf("Expected the caller's endpoint, got %s", recorded[0].Endpoint)
+	}
+	
+	if recorded[0].URI != "/12345/api/v2/getContacts?pageSize=10&channel=ventas" {
+		t.Errorf("Expected the final request URI, got %s", recorded[0].URI)
+	}
+}
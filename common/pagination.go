@@ -0,0 +1,87 @@
+package common
+
+import (
+	"context"
+)
+
+// PaginatedResponse representa la información de paginación de las respuestas
+// de listado de WATI. Page empieza en 1.
+type PaginatedResponse struct {
+	Page       int `json:"page"`
+	PageSize   int `json:"pageSize"`
+	TotalPages int `json:"totalPages"`
+	TotalCount int `json:"totalCount"`
+}
+
+// LastPage retorna la última página. Si WATI no informa TotalPages se deduce
+// de TotalCount y PageSize; si tampoco es posible retorna 0.
+func (p PaginatedResponse) LastPage() int {
+	if p.TotalPages > 0 {
+		return p.TotalPages
+	}
+	
+	if p.TotalCount > 0 && p.PageSize > 0 {
+		return (p.TotalCount + p.PageSize - 1) / p.PageSize
+	}
+	
+	return 0
+}
+
+// HasNextPage indica si hay páginas después de Page. Sin Page ni una última
+// página conocida se asume que no hay más, para no pedir páginas sin fin.
+func (p PaginatedResponse) HasNextPage() bool {
+	return p.Page > 0 && p.Page < p.LastPage()
+}
+
+// NextPage retorna el número de la página siguiente, o 0 si no hay más
+func (p PaginatedResponse) NextPage() int {
+	if !p.HasNextPage() {
+		return 0
+	}
+	
+	return p.Page + 1
+}
+
+// Advance retorna la página a pedir después de requested, dada esta respuesta
+// con count elementos, y false si la iteración terminó (última página o página
+// vacía). Si la respuesta no informa Page se usa la página pedida. Es la regla
+// de avance que comparten ForEachPage y los iteradores de cada paquete.
+func (p PaginatedResponse) Advance(requested, count int) (int, bool) {
+	if p.Page == 0 {
+		p.Page = requested
+	}
+	
+	if count == 0 || !p.HasNextPage() {
+		return 0, false
+	}
+	
+	return p.NextPage(), true
+}
+
+// ForEachPage llama a fetch con páginas sucesivas a partir de start (o 1 si
+// start no es positivo). fetch retorna la paginación de la respuesta y la
+// cantidad de elementos de la página. La iteración avanza según Advance y
+// termina con error si fetch falla o ctx se cancela.
+func ForEachPage(ctx context.Context, start int, fetch func(page int) (PaginatedResponse, int, error)) error {
+	page := start
+	if page < 1 {
+		page = 1
+	}
+	
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		
+		pagination, count, err := fetch(page)
+		if err != nil {
+			return err
+		}
+		
+		next, more := pagination.Advance(page, count)
+		if !more {
+			return nil
+		}
+		page = next
+	}
+}
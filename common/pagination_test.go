@@ -0,0 +1,111 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPaginatedResponseNextPage(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination PaginatedResponse
+		wantNext   int
+	}{
+		{name: "first of three", pagination: PaginatedResponse{Page: 1, TotalPages: 3}, wantNext: 2},
+		{name: "last page", pagination: PaginatedResponse{Page: 3, TotalPages: 3}, wantNext: 0},
+		{name: "single page", pagination: PaginatedResponse{Page: 1, TotalPages: 1}, wantNext: 0},
+		{name: "no total pages, from count", pagination: PaginatedResponse{Page: 1, PageSize: 20, TotalCount: 45}, wantNext: 2},
+		{name: "no total pages, last by count", pagination: PaginatedResponse{Page: 3, PageSize: 20, TotalCount: 45}, wantNext: 0},
+		{name: "nothing reported", pagination: PaginatedResponse{Page: 1}, wantNext: 0},
+		{name: "no page", pagination: PaginatedResponse{TotalPages: 3}, wantNext: 0},
+		{name: "empty", pagination: PaginatedResponse{}, wantNext: 0},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pagination.NextPage(); got != tt.wantNext {
+				t.Errorf("NextPage() = %d, want %d", got, tt.wantNext)
+			}
+			if got := tt.pagination.HasNextPage(); got != (tt.wantNext > 0) {
+				t.Errorf("HasNextPage() = %v, want %v", got, tt.wantNext > 0)
+			}
+		})
+	}
+}
+
+func TestPaginatedResponseAdvance(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination PaginatedResponse
+		requested  int
+		count      int
+		wantNext   int
+		wantMore   bool
+	}{
+		{name: "next page", pagination: PaginatedResponse{Page: 1, TotalPages: 3}, requested: 1, count: 10, wantNext: 2, wantMore: true},
+		{name: "last page", pagination: PaginatedResponse{Page: 3, TotalPages: 3}, requested: 3, count: 10, wantMore: false},
+		{name: "empty page", pagination: PaginatedResponse{Page: 1, TotalPages: 3}, requested: 1, count: 0, wantMore: false},
+		{name: "page not reported", pagination: PaginatedResponse{TotalPages: 3}, requested: 2, count: 10, wantNext: 3, wantMore: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, more := tt.pagination.Advance(tt.requested, tt.count)
+			if next != tt.wantNext || more != tt.wantMore {
+				t.Errorf("Advance() = (%d, %v), want (%d, %v)", next, more, tt.wantNext, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestForEachPage(t *testing.T) {
+	t.Run("until last page", func(t *testing.T) {
+		var pages []int
+		err := ForEachPage(context.Background(), 0, func(page int) (PaginatedResponse, int, error) {
+			pages = append(pages, page)
+			// Sin Page en la respuesta se usa la página pedida
+			return PaginatedResponse{PageSize: 10, TotalCount: 25}, 10, nil
+		})
+		if err != nil {
+			t.Fatalf("ForEachPage() error = %v", err)
+		}
+		if len(pages) != 3 || pages[0] != 1 || pages[2] != 3 {
+			t.Errorf("Expected pages [1 2 3], got %v", pages)
+		}
+	})
+	
+	t.Run("stops on empty page", func(t *testing.T) {
+		calls := 0
+		err := ForEachPage(context.Background(), 2, func(page int) (PaginatedResponse, int, error) {
+			calls++
+			return PaginatedResponse{Page: page, TotalPages: 10}, 0, nil
+		})
+		if err != nil || calls != 1 {
+			t.Errorf("Expected a single call without error, got %d calls and %v", calls, err)
+		}
+	})
+	
+	t.Run("propagates errors", func(t *testing.T) {
+		fetchErr := errors.New("boom")
+		err := ForEachPage(context.Background(), 1, func(page int) (PaginatedResponse, int, error) {
+			return PaginatedResponse{}, 0, fetchErr
+		})
+		if !errors.Is(err, fetchErr) {
+			t.Errorf("Expected fetch error, got %v", err)
+		}
+	})
+	
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		
+		err := ForEachPage(ctx, 1, func(page int) (PaginatedResponse, int, error) {
+			t.Error("Expected no fetch with a canceled context")
+			return PaginatedResponse{}, 0, nil
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+}
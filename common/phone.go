@@ -0,0 +1,88 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Límites de longitud de un número E.164 sin el "+": código de país (1 a 3
+// dígitos) más el número nacional, hasta 15 dígitos en total
+const (
+	MinPhoneDigits = 7
+	MaxPhoneDigits = 15
+)
+
+// ErrInvalidPhoneNumber es el error base de ValidatePhoneNumber; usar
+// errors.Is para detectarlo
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+// ValidatePhoneNumber valida un número de teléfono en formato internacional y
+// retorna su forma normalizada: solo dígitos, con el código de país y sin "+".
+// Se ignoran espacios, guiones, puntos, paréntesis y el "+" inicial. No
+// verifica que el número exista ni el plan de numeración de cada país.
+func ValidatePhoneNumber(phone string) (string, error) {
+	trimmed := strings.TrimSpace(phone)
+	if trimmed == "" {
+		return "", fmt.Errorf("%w: phone number is required", ErrInvalidPhoneNumber)
+	}
+	
+	trimmed = strings.TrimPrefix(trimmed, "+")
+	
+	var normalized strings.Builder
+	for _, c := range trimmed {
+		switch {
+		case c >= '0' && c <= '9':
+			normalized.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+			// Separadores habituales de formato
+		default:
+			return "", fmt.Errorf("%w: %q must contain only digits", ErrInvalidPhoneNumber, phone)
+		}
+	}
+	
+	digits := normalized.String()
+	if len(digits) < MinPhoneDigits || len(digits) > MaxPhoneDigits {
+		return "", fmt.Errorf("%w: %q must have between %d and %d digits including the country code", ErrInvalidPhoneNumber, phone, MinPhoneDigits, MaxPhoneDigits)
+	}
+	
+	return digits, nil
+}
+
+// NormalizeWhatsappNumber valida *whatsappNumber con ValidatePhoneNumber y lo
+// reemplaza por su forma normalizada. Lo usan los Validate de las peticiones,
+// que por eso dejan el número del llamador normalizado.
+func NormalizeWhatsappNumber(whatsappNumber *string) error {
+	normalized, err := ValidatePhoneNumber(*whatsappNumber)
+	if err != nil {
+		return fmt.Errorf("invalid whatsappNumber: %w", err)
+	}
+	*whatsappNumber = normalized
+	
+	return nil
+}
+
+// ValidateE164 verifica que phone esté en formato E.164 estricto: "+" seguido
+// del código de país y el número, sin separadores ni cero inicial
+func ValidateE164(phone string) error {
+	digits, ok := strings.CutPrefix(phone, "+")
+	if !ok {
+		return fmt.Errorf("%w: %q must start with +", ErrInvalidPhoneNumber, phone)
+	}
+	
+	if len(digits) < MinPhoneDigits || len(digits) > MaxPhoneDigits {
+		return fmt.Errorf("%w: %q must have between %d and %d digits including the country code", ErrInvalidPhoneNumber, phone, MinPhoneDigits, MaxPhoneDigits)
+	}
+	
+	if digits[0] == '0' {
+		return fmt.Errorf("%w: %q country code cannot start with 0", ErrInvalidPhoneNumber, phone)
+	}
+	
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("%w: %q must contain only digits after +", ErrInvalidPhoneNumber, phone)
+		}
+	}
+	
+	return nil
+}
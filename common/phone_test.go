@@ -0,0 +1,100 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePhoneNumber(t *testing.T) {
+	tests := []struct {
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{phone: "5491112345678", want: "5491112345678"},
+		{phone: "+54 9 11 1234-5678", want: "5491112345678"},
+		{phone: "+1 (555) 123.4567", want: "15551234567"},
+		{phone: "+290 1234", want: "2901234"},
+		{phone: "", wantErr: true},
+		{phone: "   ", wantErr: true},
+		{phone: "abcdefghij", wantErr: true},
+		{phone: "12345", wantErr: true},
+		{phone: "1234567890123456", wantErr: true},
+		{phone: "++5491112345678", wantErr: true},
+		{phone: "54911x2345678", wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			got, err := ValidatePhoneNumber(tt.phone)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhoneNumber) {
+					t.Errorf("Expected ErrInvalidPhoneNumber, got %v", err)
+				}
+				return
+			}
+			
+			if err != nil {
+				t.Fatalf("ValidatePhoneNumber() error = %v", err)
+			}
+			
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeWhatsappNumber(t *testing.T) {
+	number := "+54 9 11 1234-5678"
+	if err := NormalizeWhatsappNumber(&number); err != nil {
+		t.Fatalf("NormalizeWhatsappNumber() error = %v", err)
+	}
+	
+	if number != "5491112345678" {
+		t.Errorf("Expected the number to be replaced by 5491112345678, got %s", number)
+	}
+	
+	invalid := "12345"
+	err := NormalizeWhatsappNumber(&invalid)
+	if !errors.Is(err, ErrInvalidPhoneNumber) || !strings.Contains(err.Error(), "whatsappNumber") {
+		t.Errorf("Expected a whatsappNumber ErrInvalidPhoneNumber, got %v", err)
+	}
+	
+	if invalid != "12345" {
+		t.Errorf("Expected an invalid number to be left unchanged, got %s", invalid)
+	}
+}
+
+func TestValidateE164(t *testing.T) {
+	tests := []struct {
+		phone   string
+		wantErr bool
+	}{
+		{phone: "+5491112345678"},
+		{phone: "+15551234567"},
+		{phone: "5491112345678", wantErr: true},
+		{phone: "+54 9 11 1234-5678", wantErr: true},
+		{phone: "+0123456789", wantErr: true},
+		{phone: "+12345", wantErr: true},
+		{phone: "+1234567890123456", wantErr: true},
+		{phone: "", wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			err := ValidateE164(tt.phone)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhoneNumber) {
+					t.Errorf("Expected ErrInvalidPhoneNumber, got %v", err)
+				}
+				return
+			}
+			
+			if err != nil {
+				t.Errorf("ValidateE164() error = %v", err)
+			}
+		})
+	}
+}
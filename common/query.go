@@ -0,0 +1,23 @@
+package common
+
+import (
+	"net/url"
+)
+
+// BuildEndpoint agrega params como query al endpoint, codificando cada valor y
+// ordenando los parámetros por nombre para que la URL sea siempre la misma
+// (por ejemplo, para caches). Los valores vacíos se omiten.
+func BuildEndpoint(endpoint string, params map[string]string) string {
+	query := url.Values{}
+	for key, value := range params {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	
+	if len(query) == 0 {
+		return endpoint
+	}
+	
+	return endpoint + "?" + query.Encode()
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestBuildEndpoint(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   string
+	}{
+		{name: "no params", params: nil, want: "/api/v1/getContacts"},
+		{name: "empty values", params: map[string]string{"name": ""}, want: "/api/v1/getContacts"},
+		{
+			name:   "sorted",
+			params: map[string]string{"pageSize": "20", "name": "Ana", "pageNumber": "1"},
+			want:   "/api/v1/getContacts?name=Ana&pageNumber=1&pageSize=20",
+		},
+		{
+			name:   "special characters",
+			params: map[string]string{"name": "Ana & Juan", "phone": "+5491112345678", "date": "2024-01-01 10:00"},
+			want:   "/api/v1/getContacts?date=2024-01-01+10%3A00&name=Ana+%26+Juan&phone=%2B5491112345678",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildEndpoint("/api/v1/getContacts", tt.params)
+			if got != tt.want {
+				t.Errorf("BuildEndpoint() = %s, want %s", got, tt.want)
+			}
+			
+			// Los valores deben recuperarse intactos
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("Invalid URL %s: %v", got, err)
+			}
+			for key, value := range tt.params {
+				if value != "" && u.Query().Get(key) != value {
+					t.Errorf("Expected %s=%q, got %q", key, value, u.Query().Get(key))
+				}
+			}
+		})
+	}
+}
@@ -1,19 +1,84 @@
 package wati
 
 import (
+	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// DefaultAPIVersion es la versión de la API usada por los endpoints del SDK
+const DefaultAPIVersion = "v1"
+
+// DefaultUserAgent es el user agent enviado cuando no se configura otro
+const DefaultUserAgent = "go-wati/1.0.0"
+
+// DefaultBackgroundTimeout es el tiempo máximo de las operaciones internas que
+// no reciben un contexto del llamador
+const DefaultBackgroundTimeout = 60 * time.Second
+
 // Config representa la configuración del cliente WATI
 type Config struct {
 	APIEndpoint string
 	Token       string
 	Timeout     time.Duration
-	RetryCount  int
+	MaxRetries  int
+	UserAgent   string
+	APIVersion  string
 	RateLimit   *RateLimitConfig
 	Debug       bool
+	
+	// HTTPClient es un cliente HTTP provisto por el usuario. Si se establece,
+	// Timeout no se aplica y el transporte queda bajo control del usuario.
+	HTTPClient *http.Client
+	
+	// Interceptores invocados en cada intento de petición, incluidos los reintentos
+	RequestInterceptors  []RequestInterceptor
+	ResponseInterceptors []ResponseInterceptor
+	
+	// TracerProvider habilita spans de OpenTelemetry por llamada. Si es nil el
+	// tracing queda deshabilitado.
+	TracerProvider trace.TracerProvider
+	
+	// DefaultQueryParams se agregan a la URL de cada petición salvo que la
+	// llamada ya incluya el mismo parámetro
+	DefaultQueryParams map[string]string
+	
+	// BackgroundTimeout limita las operaciones que el SDK ejecuta sin un
+	// contexto del llamador, como ValidateToken y RotateToken
+	BackgroundTimeout time.Duration
+	
+	// QuotaGuard verifica la cuota de la cuenta antes de los envíos masivos
+	QuotaGuard bool
+	
+	// DeprecationNotice se invoca cuando una respuesta incluye los headers
+	// Deprecation o Sunset
+	DeprecationNotice DeprecationNoticeFunc
+	
+	// DryRun, si se establece, reemplaza el envío por la red: cada petición se
+	// registra y se responde con lo que retorne
+	DryRun DryRunResponder
+	
+	// CircuitBreaker, si se establece, corta las peticiones tras fallos
+	// consecutivos del servidor
+	CircuitBreaker *CircuitBreakerConfig
+	
+	// Logger recibe el log de debug de cada petición. Si es nil y Debug está
+	// habilitado se usa el logger estándar.
+	Logger Logger
+	
+	// LogBodies agrega al log de debug los headers y cuerpos de las peticiones
+	// y respuestas, con las credenciales ocultas
+	LogBodies bool
 }
 
+// RequestInterceptor observa o modifica una petición antes de enviarla
+type RequestInterceptor func(req *http.Request)
+
+// ResponseInterceptor observa la respuesta de cada intento junto con su duración.
+// resp es nil cuando el intento falló a nivel de red.
+type ResponseInterceptor func(resp *http.Response, elapsed time.Duration)
+
 // RateLimitConfig configura los límites de velocidad
 type RateLimitConfig struct {
 	RequestsPerSecond int
@@ -23,8 +88,11 @@ type RateLimitConfig struct {
 // DefaultConfig retorna una configuración por defecto
 func DefaultConfig() *Config {
 	return &Config{
-		Timeout:    30 * time.Second,
-		RetryCount: 3,
+		Timeout:           30 * time.Second,
+		BackgroundTimeout: DefaultBackgroundTimeout,
+		MaxRetries:        3,
+		UserAgent:         DefaultUserAgent,
+		APIVersion:        DefaultAPIVersion,
 		RateLimit: &RateLimitConfig{
 			RequestsPerSecond: 10,
 			BurstSize:         20,
@@ -36,17 +104,32 @@ func DefaultConfig() *Config {
 // ClientOption es una función que modifica la configuración del cliente
 type ClientOption func(*Config)
 
-// WithTimeout establece el timeout para las peticiones HTTP
-func WithTimeout(timeout time.Duration) ClientOption {
+// WithTimeout establece el timeout en segundos para las peticiones HTTP.
+// No tiene efecto cuando se provee un cliente con WithHTTPClient.
+func WithTimeout(seconds int) ClientOption {
 	return func(c *Config) {
-		c.Timeout = timeout
+		c.Timeout = time.Duration(seconds) * time.Second
+	}
+}
+
+// WithRetries establece el número máximo de reintentos
+func WithRetries(retries int) ClientOption {
+	return func(c *Config) {
+		c.MaxRetries = retries
 	}
 }
 
 // WithRetryCount establece el número de reintentos
+//
+// Deprecated: usar WithRetries.
 func WithRetryCount(count int) ClientOption {
+	return WithRetries(count)
+}
+
+// WithUserAgent establece el user agent enviado en cada petición
+func WithUserAgent(userAgent string) ClientOption {
 	return func(c *Config) {
-		c.RetryCount = count
+		c.UserAgent = userAgent
 	}
 }
 
@@ -60,10 +143,128 @@ func WithRateLimit(requestsPerSecond int, burstSize int) ClientOption {
 	}
 }
 
-// WithDebug habilita o deshabilita el modo debug
+// WithDebug habilita o deshabilita el log de debug: método, endpoint, código
+// de estado y duración de cada petición, con el logger estándar salvo que se
+// indique otro con WithLogger
 func WithDebug(debug bool) ClientOption {
 	return func(c *Config) {
 		c.Debug = debug
 	}
 }
 
+// WithLogger habilita el log de debug usando logger
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
+
+// WithLogBodies agrega al log de debug los headers y cuerpos de peticiones y
+// respuestas. El header Authorization, el token y los campos sensibles (como
+// el secret de los webhooks) se ocultan siempre.
+func WithLogBodies(enabled bool) ClientOption {
+	return func(c *Config) {
+		c.LogBodies = enabled
+	}
+}
+
+// WithHTTPClient establece un cliente HTTP propio (proxy, TLS, pool de conexiones).
+// El SDK no modifica su timeout, por lo que WithTimeout no tiene efecto; el rate
+// limiting y los reintentos se siguen aplicando sobre este cliente.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Config) {
+		c.HTTPClient = httpClient
+	}
+}
+
+// WithRequestInterceptor agrega un interceptor invocado antes de cada intento de petición
+func WithRequestInterceptor(interceptor RequestInterceptor) ClientOption {
+	return func(c *Config) {
+		c.RequestInterceptors = append(c.RequestInterceptors, interceptor)
+	}
+}
+
+// WithResponseInterceptor agrega un interceptor invocado tras cada intento de petición
+func WithResponseInterceptor(interceptor ResponseInterceptor) ClientOption {
+	return func(c *Config) {
+		c.ResponseInterceptors = append(c.ResponseInterceptors, interceptor)
+	}
+}
+
+// WithAPIVersion establece la versión de la API (por ejemplo "v2") aplicada a
+// todos los endpoints /api/v1/... del cliente
+func WithAPIVersion(version string) ClientOption {
+	return func(c *Config) {
+		c.APIVersion = version
+	}
+}
+
+// WithTracerProvider habilita el tracing con OpenTelemetry: cada llamada a la API
+// genera un span y el contexto de traza se propaga en los headers salientes
+func WithTracerProvider(tp trace.TracerProvider) ClientOption {
+	return func(c *Config) {
+		c.TracerProvider = tp
+	}
+}
+
+// WithDefaultQueryParams establece parámetros de consulta (por ejemplo un tenant
+// o channelId) que se agregan a todas las peticiones sin pisar los de cada llamada
+func WithDefaultQueryParams(params map[string]string) ClientOption {
+	return func(c *Config) {
+		c.DefaultQueryParams = make(map[string]string, len(params))
+		for key, value := range params {
+			c.DefaultQueryParams[key] = value
+		}
+	}
+}
+
+// WithBackgroundTimeout establece el tiempo máximo de las operaciones que el SDK
+// ejecuta con un contexto propio. Un valor no positivo restaura el predeterminado.
+func WithBackgroundTimeout(d time.Duration) ClientOption {
+	return func(c *Config) {
+		c.BackgroundTimeout = d
+	}
+}
+
+// WithQuotaGuard habilita la verificación de cuota previa a los envíos masivos:
+// si la cuota restante informada por GetAccountInfo es menor que la cantidad de
+// destinatarios se retorna ErrQuotaExceeded sin enviar. La cuota se cachea
+// brevemente para no consultarla en cada envío.
+func WithQuotaGuard(enabled bool) ClientOption {
+	return func(c *Config) {
+		c.QuotaGuard = enabled
+	}
+}
+
+// WithDeprecationNotice registra un callback que se invoca cuando WATI marca un
+// endpoint como obsoleto con los headers Deprecation o Sunset. Con o sin
+// callback, el SDK registra un aviso en el log la primera vez por endpoint.
+func WithDeprecationNotice(notice DeprecationNoticeFunc) ClientOption {
+	return func(c *Config) {
+		c.DeprecationNotice = notice
+	}
+}
+
+// WithDryRun activa el modo dry-run para tests: las peticiones no se envían
+// por la red sino que se registran, accesibles con RecordedRequests, y se
+// responden con responder. Los interceptores, el rate limiting y los
+// reintentos siguen aplicándose.
+func WithDryRun(responder DryRunResponder) ClientOption {
+	return func(c *Config) {
+		c.DryRun = responder
+	}
+}
+
+// WithCircuitBreaker habilita un circuit breaker: tras failureThreshold llamadas
+// consecutivas que fallan con errores de red o respuestas 5xx, las peticiones
+// se rechazan de inmediato con ErrCircuitOpen durante cooldown. Pasada la
+// espera se permite una única petición de prueba; si tiene éxito el circuito
+// se cierra y si falla vuelve a abrirse.
+func WithCircuitBreaker(failureThreshold int, cooldown time.Duration) ClientOption {
+	return func(c *Config) {
+		c.CircuitBreaker = &CircuitBreakerConfig{
+			FailureThreshold: failureThreshold,
+			Cooldown:         cooldown,
+		}
+	}
+}
@@ -3,7 +3,12 @@ package contacts
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
 )
 
 // HTTPClient define la interfaz para realizar peticiones HTTP
@@ -11,6 +16,24 @@ type HTTPClient interface {
 	DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
 }
 
+// ErrContactNotFound indica que no existe un contacto con el criterio buscado.
+// errors.Is también lo hace coincidir con wati.ErrContactNotFound.
+var ErrContactNotFound error = contactNotFoundError{}
+
+// contactNotFoundError es el tipo de ErrContactNotFound
+type contactNotFoundError struct{}
+
+func (contactNotFoundError) Error() string {
+	return "contact not found"
+}
+
+// Is hace coincidir el error con el centinela del paquete raíz, un
+// *wati.WATIError que se detecta por su método para no depender de él
+func (contactNotFoundError) Is(target error) bool {
+	sentinel, ok := target.(interface{ IsContactNotFoundError() bool })
+	return ok && sentinel.IsContactNotFoundError()
+}
+
 // Service implementa ContactsService
 type Service struct {
 	client HTTPClient
@@ -29,19 +52,14 @@ func (s *Service) GetContacts(ctx context.Context, params *GetContactsParams) (*
 		params = &GetContactsParams{}
 	}
 	
+	if err := params.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
 	params.SetDefaults()
 	
 	// Construir endpoint con query parameters
-	endpoint := "/api/v1/getContacts"
-	queryParams := params.ToMap()
-	
-	if len(queryParams) > 0 {
-		var parts []string
-		for key, value := range queryParams {
-			parts = append(parts, fmt.Sprintf("%s=%s", key, value))
-		}
-		endpoint += "?" + strings.Join(parts, "&")
-	}
+	endpoint := common.BuildEndpoint("/api/v1/getContacts", params.ToMap())
 	
 	var response ContactsResponse
 	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
@@ -138,17 +156,130 @@ func (s *Service) DeleteContact(ctx context.Context, id string) error {
 	return nil
 }
 
-// SearchContacts busca contactos por query
-func (s *Service) SearchContacts(ctx context.Context, query string) (*ContactsResponse, error) {
+// DeleteContacts elimina varios contactos, hasta MaxContactsPerRequest por
+// llamada. Como WATI no ofrece un endpoint de eliminación en lote, se envía
+// una petición por contacto con hasta DeleteContactsConcurrency en paralelo.
+// El fallo de un contacto no interrumpe al resto; los resultados se informan
+// en el orden de ids. Si ctx se cancela, el resultado se retorna junto con el
+// error del contexto.
+func (s *Service) DeleteContacts(ctx context.Context, ids []string) (*BulkDeleteResponse, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one contact ID is required")
+	}
+	
+	if len(ids) > MaxContactsPerRequest {
+		return nil, fmt.Errorf("maximum %d contacts allowed per request, got %d", MaxContactsPerRequest, len(ids))
+	}
+	
+	// Validar todos los IDs antes de eliminar el primero
+	for i, id := range ids {
+		if strings.TrimSpace(id) == "" {
+			return nil, fmt.Errorf("validation error: contact ID at index %d is empty", i)
+		}
+	}
+	
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, DeleteContactsConcurrency)
+	var wg sync.WaitGroup
+	
+	for i, id := range ids {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			
+			errs[i] = s.DeleteContact(ctx, id)
+		}(i, id)
+	}
+	
+	wg.Wait()
+	
+	response := &BulkDeleteResponse{}
+	for i, err := range errs {
+		if err != nil {
+			response.FailureCount++
+			response.Errors = append(response.Errors, BulkDeleteError{
+				Index: i,
+				ID:    ids[i],
+				Error: err.Error(),
+			})
+			continue
+		}
+		
+		response.SuccessCount++
+		response.Deleted = append(response.Deleted, ids[i])
+	}
+	
+	return response, ctx.Err()
+}
+
+// SearchContacts busca contactos en el servidor. Sin opciones busca por nombre;
+// con varios campos en opts se hace una consulta por campo y se combinan los
+// resultados sin duplicados.
+func (s *Service) SearchContacts(ctx context.Context, query string, opts ...*SearchOptions) (*ContactsResponse, error) {
 	if query == "" {
 		return nil, fmt.Errorf("search query is required")
 	}
 	
-	params := &GetContactsParams{
-		Name: query,
+	options := &SearchOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
 	}
 	
-	return s.GetContacts(ctx, params)
+	fields := options.Fields
+	if len(fields) == 0 {
+		fields = []SearchField{SearchByName}
+	}
+	
+	var combined *ContactsResponse
+	seen := make(map[string]bool)
+	
+	for _, field := range fields {
+		params := &GetContactsParams{
+			PageSize:   options.PageSize,
+			PageNumber: options.PageNumber,
+		}
+		
+		switch field {
+		case SearchByName:
+			params.Name = query
+		case SearchByEmail:
+			params.Email = query
+		case SearchByPhone:
+			params.Phone = query
+		case SearchByTag:
+			params.Tag = query
+		default:
+			return nil, fmt.Errorf("unsupported search field: %s", field)
+		}
+		
+		response, err := s.GetContacts(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		
+		if combined == nil {
+			combined = &ContactsResponse{
+				BaseResponse:      response.BaseResponse,
+				PaginatedResponse: response.PaginatedResponse,
+			}
+		}
+		
+		for _, contact := range response.Contacts {
+			if contact.ID != "" && seen[contact.ID] {
+				continue
+			}
+			seen[contact.ID] = true
+			combined.Contacts = append(combined.Contacts, contact)
+		}
+	}
+	
+	if len(fields) > 1 {
+		combined.TotalCount = len(combined.Contacts)
+	}
+	
+	return combined, nil
 }
 
 // FilterContacts filtra contactos según criterios específicos
@@ -184,8 +315,8 @@ func (s *Service) AddContacts(ctx context.Context, contacts []*CreateContactRequ
 	}
 	
 	// WATI permite hasta 100 contactos por llamada
-	if len(contacts) > 100 {
-		return nil, fmt.Errorf("maximum 100 contacts allowed per request, got %d", len(contacts))
+	if len(contacts) > MaxContactsPerRequest {
+		return nil, fmt.Errorf("maximum %d contacts allowed per request, got %d", MaxContactsPerRequest, len(contacts))
 	}
 	
 	requestBody := struct {
@@ -213,56 +344,171 @@ func (s *Service) GetContactsByPage(ctx context.Context, page, pageSize int) (*C
 	return s.GetContacts(ctx, params)
 }
 
-// GetAllContacts obtiene todos los contactos paginando automáticamente
-func (s *Service) GetAllContacts(ctx context.Context) ([]Contact, error) {
-	var allContacts []Contact
-	page := 1
-	pageSize := 50
+// ContactIterator recorre los contactos página por página, pidiendo cada
+// página al servidor solo cuando se agotó la anterior
+type ContactIterator struct {
+	ctx     context.Context
+	service *Service
+	params  GetContactsParams
+	page    []Contact
+	index   int
+	current Contact
+	done    bool
+	err     error
+}
+
+// IterateContacts retorna un iterador sobre los contactos que coinciden con
+// params. Permite procesar cuentas grandes sin cargar todos los contactos en
+// memoria y detenerse antes de recorrerlos todos.
+func (s *Service) IterateContacts(ctx context.Context, params *GetContactsParams) (*ContactIterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	
-	for {
-		response, err := s.GetContactsByPage(ctx, page, pageSize)
+	iterator := &ContactIterator{
+		ctx:     ctx,
+		service: s,
+	}
+	if params != nil {
+		iterator.params = *params
+	}
+	iterator.params.SetDefaults()
+	
+	return iterator, nil
+}
+
+// Next avanza al siguiente contacto, obteniendo una nueva página si es
+// necesario. Retorna false al terminar o ante un error (ver Err).
+func (it *ContactIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		
+		response, err := it.service.GetContacts(it.ctx, &it.params)
 		if err != nil {
-			return nil, fmt.Errorf("error getting contacts page %d: %w", page, err)
+			it.err = fmt.Errorf("error getting contacts page %d: %w", it.params.PageNumber, err)
+			return false
 		}
 		
-		allContacts = append(allContacts, response.Contacts...)
+		it.page = response.Contacts
+		it.index = 0
+		
+		// Si no hay más páginas, terminar después de esta
+		next, more := response.PaginatedResponse.Advance(it.params.PageNumber, len(response.Contacts))
+		it.done = !more
+		it.params.PageNumber = next
+	}
+	
+	it.current = it.page[it.index]
+	it.index++
+	
+	return true
+}
+
+// Contact retorna el contacto actual
+func (it *ContactIterator) Contact() Contact {
+	return it.current
+}
+
+// Err retorna el error que detuvo la iteración, si lo hubo
+func (it *ContactIterator) Err() error {
+	return it.err
+}
+
+// GetAllContacts obtiene todos los contactos paginando automáticamente
+func (s *Service) GetAllContacts(ctx context.Context) ([]Contact, error) {
+	iterator, err := s.IterateContacts(ctx, &GetContactsParams{PageSize: 50})
+	if err != nil {
+		return nil, err
+	}
+	
+	var allContacts []Contact
+	for iterator.Next() {
+		allContacts = append(allContacts, iterator.Contact())
+	}
+	
+	if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+	
+	return allContacts, nil
+}
+
+// GetContactsModifiedSince obtiene los contactos modificados después de since,
+// para sincronizaciones incrementales. WATI no expone un filtro por fecha de
+// modificación, así que se pagina y se filtra por LastUpdated asumiendo que el
+// servidor ordena de más reciente a más antiguo: la paginación se detiene en el
+// primer contacto anterior a since. Los contactos sin LastUpdated válido se
+// incluyen para no perder cambios.
+func (s *Service) GetContactsModifiedSince(ctx context.Context, since time.Time, params *GetContactsParams) ([]Contact, error) {
+	iterator, err := s.IterateContacts(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	
+	var modified []Contact
+	for iterator.Next() {
+		contact := iterator.Contact()
 		
-		// Si no hay más páginas, terminar
-		if page >= response.TotalPages || len(response.Contacts) == 0 {
+		lastUpdated, err := contact.LastUpdatedTime()
+		if err == nil && !lastUpdated.After(since) {
 			break
 		}
 		
-		page++
+		modified = append(modified, contact)
 	}
 	
-	return allContacts, nil
+	if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+	
+	return modified, nil
 }
 
-// GetContactByPhone busca un contacto por número de teléfono
+// phoneLookupPageSize es la cantidad de resultados que GetContactByPhone
+// revisa: el filtro de WATI no es exacto, así que el contacto buscado puede no
+// ser el primero
+const phoneLookupPageSize = 20
+
+// GetContactByPhone busca un contacto por número de teléfono. Como el filtro
+// por teléfono de WATI puede retornar coincidencias parciales, solo se acepta
+// un contacto cuyo teléfono normalizado sea exactamente el buscado; si no hay
+// ninguno se retorna ErrContactNotFound.
 func (s *Service) GetContactByPhone(ctx context.Context, phone string) (*Contact, error) {
 	if phone == "" {
 		return nil, fmt.Errorf("phone number is required")
 	}
 	
-	// Buscar usando el endpoint de búsqueda
-	params := &GetContactsParams{
-		PageSize: 1,
+	normalized, err := common.ValidatePhoneNumber(phone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid phone number: %w", err)
 	}
 	
 	// Construir endpoint con el teléfono como filtro
-	endpoint := fmt.Sprintf("/api/v1/getContacts?phone=%s&pageSize=%d", phone, params.PageSize)
+	endpoint := common.BuildEndpoint("/api/v1/getContacts", map[string]string{
+		"phone":    normalized,
+		"pageSize": strconv.Itoa(phoneLookupPageSize),
+	})
 	
 	var response ContactsResponse
-	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
+	err = s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
 	if err != nil {
 		return nil, fmt.Errorf("error searching contact by phone %s: %w", phone, err)
 	}
 	
-	if len(response.Contacts) == 0 {
-		return nil, fmt.Errorf("contact with phone %s not found", phone)
+	for i := range response.Contacts {
+		if response.Contacts[i].hasPhone(normalized) {
+			return &response.Contacts[i], nil
+		}
 	}
 	
-	return &response.Contacts[0], nil
+	return nil, fmt.Errorf("%w with phone %s", ErrContactNotFound, phone)
 }
 
 // UpdateContactTags actualiza solo las etiquetas de un contacto
@@ -274,6 +520,69 @@ func (s *Service) UpdateContactTags(ctx context.Context, id string, tags []strin
 	return s.UpdateContact(ctx, id, updateReq)
 }
 
+// AddContactTags agrega etiquetas a un contacto sin reemplazar las que ya
+// tiene. WATI no expone endpoints para agregar etiquetas individuales, así que
+// se lee el contacto y se envía la unión sin duplicados, preservando el orden.
+func (s *Service) AddContactTags(ctx context.Context, id string, tags []string) (*Contact, error) {
+	contact, err := s.GetContact(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	updated := Contact{Tags: []string{}}
+	for _, tag := range contact.Tags {
+		updated.AddTag(tag)
+	}
+	for _, tag := range tags {
+		updated.AddTag(tag)
+	}
+	
+	return s.setContactTags(ctx, id, updated.Tags)
+}
+
+// RemoveContactTags elimina etiquetas de un contacto conservando las demás
+func (s *Service) RemoveContactTags(ctx context.Context, id string, tags []string) (*Contact, error) {
+	contact, err := s.GetContact(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	
+	updated := Contact{Tags: []string{}}
+	for _, tag := range contact.Tags {
+		updated.AddTag(tag)
+	}
+	for _, tag := range tags {
+		updated.RemoveTag(tag)
+	}
+	
+	return s.setContactTags(ctx, id, updated.Tags)
+}
+
+// setContactTags reemplaza las etiquetas de un contacto. A diferencia de
+// UpdateContactTags, envía la lista aunque esté vacía para poder quitar la
+// última etiqueta.
+func (s *Service) setContactTags(ctx context.Context, id string, tags []string) (*Contact, error) {
+	endpoint := fmt.Sprintf("/api/v1/updateContact/%s", id)
+	
+	request := struct {
+		Tags []string `json:"tags"`
+	}{
+		Tags: tags,
+	}
+	
+	var response struct {
+		BaseResponse
+		Contact Contact `json:"contact"`
+	}
+	
+	err := s.client.DoRequest(ctx, "PUT", endpoint, request, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error updating contact %s: %w", id, err)
+	}
+	
+	return &response.Contact, nil
+}
+
 // UpdateContactCustomParams actualiza solo los parámetros personalizados de un contacto
 func (s *Service) UpdateContactCustomParams(ctx context.Context, id string, customParams []CustomParam) (*Contact, error) {
 	updateReq := &UpdateContactRequest{
@@ -283,3 +592,34 @@ func (s *Service) UpdateContactCustomParams(ctx context.Context, id string, cust
 	return s.UpdateContact(ctx, id, updateReq)
 }
 
+// OptInContact registra el consentimiento del contacto para recibir mensajes
+func (s *Service) OptInContact(ctx context.Context, id string) (*Contact, error) {
+	optedIn := true
+	updateReq := &UpdateContactRequest{
+		OptedIn: &optedIn,
+	}
+	
+	return s.UpdateContact(ctx, id, updateReq)
+}
+
+// OptOutContact retira el consentimiento del contacto y deshabilita los
+// broadcasts para que no vuelva a recibir campañas
+func (s *Service) OptOutContact(ctx context.Context, id string) (*Contact, error) {
+	optedIn := false
+	allowBroadcast := false
+	updateReq := &UpdateContactRequest{
+		OptedIn:        &optedIn,
+		AllowBroadcast: &allowBroadcast,
+	}
+	
+	return s.UpdateContact(ctx, id, updateReq)
+}
+
+// SetBroadcastAllowed habilita o deshabilita los broadcasts para un contacto
+func (s *Service) SetBroadcastAllowed(ctx context.Context, id string, allowed bool) (*Contact, error) {
+	updateReq := &UpdateContactRequest{
+		AllowBroadcast: &allowed,
+	}
+	
+	return s.UpdateContact(ctx, id, updateReq)
+}
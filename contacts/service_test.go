@@ -0,0 +1,611 @@
+package contacts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockHTTPClient implementa HTTPClient para testing
+type MockHTTPClient struct {
+	DoRequestFunc func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
+}
+
+func (m *MockHTTPClient) DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	if m.DoRequestFunc != nil {
+		return m.DoRequestFunc(ctx, method, endpoint, body, result)
+	}
+	return nil
+}
+
+// queryOf retorna los query parameters de un endpoint
+func queryOf(t *testing.T, endpoint string) url.Values {
+	t.Helper()
+	
+	_, rawQuery, _ := strings.Cut(endpoint, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		t.Fatalf("invalid query in endpoint %s: %v", endpoint, err)
+	}
+	
+	return query
+}
+
+func TestSearchContactsDefaultsToName(t *testing.T) {
+	var endpoints []string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			endpoints = append(endpoints, endpoint)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	if _, err := service.SearchContacts(context.Background(), "Juan"); err != nil {
+		t.Fatalf("SearchContacts() error = %v", err)
+	}
+	
+	if len(endpoints) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(endpoints))
+	}
+	
+	if got := queryOf(t, endpoints[0]).Get("name"); got != "Juan" {
+		t.Errorf("Expected name=Juan, got %q", got)
+	}
+}
+
+func TestSearchContactsByFields(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			query := queryOf(t, endpoint)
+			response := result.(*ContactsResponse)
+			
+			// El mismo contacto aparece por email y por teléfono
+			if query.Get("email") == "+5491112345678@mail.com" {
+				response.Contacts = []Contact{{ID: "1"}, {ID: "2"}}
+			}
+			if query.Get("phone") == "+5491112345678@mail.com" {
+				response.Contacts = []Contact{{ID: "2"}, {ID: "3"}}
+			}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	response, err := service.SearchContacts(context.Background(), "+5491112345678@mail.com", &SearchOptions{
+		Fields: []SearchField{SearchByEmail, SearchByPhone},
+	})
+	if err != nil {
+		t.Fatalf("SearchContacts() error = %v", err)
+	}
+	
+	var ids []string
+	for _, contact := range response.Contacts {
+		ids = append(ids, contact.ID)
+	}
+	
+	if got := strings.Join(ids, ","); got != "1,2,3" {
+		t.Errorf("Expected merged contacts 1,2,3, got %s", got)
+	}
+	
+	if response.TotalCount != 3 {
+		t.Errorf("Expected TotalCount 3, got %d", response.TotalCount)
+	}
+}
+
+func TestSearchContactsUnsupportedField(t *testing.T) {
+	service := NewService(&MockHTTPClient{})
+	
+	_, err := service.SearchContacts(context.Background(), "x", &SearchOptions{Fields: []SearchField{"address"}})
+	if err == nil {
+		t.Error("Expected error for unsupported search field")
+	}
+}
+
+// pagedContactsClient retorna páginas de contactos según el pageNumber pedido
+func pagedContactsClient(t *testing.T, pages [][]Contact, requested *[]string) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			pageNumber := queryOf(t, endpoint).Get("pageNumber")
+			*requested = append(*requested, pageNumber)
+			
+			page, _ := strconv.Atoi(pageNumber)
+			response := result.(*ContactsResponse)
+			response.TotalPages = len(pages)
+			if page >= 1 && page <= len(pages) {
+				response.Contacts = pages[page-1]
+			}
+			return nil
+		},
+	}
+}
+
+func TestIterateContacts(t *testing.T) {
+	pages := [][]Contact{
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "3"}, {ID: "4"}},
+		{{ID: "5"}},
+	}
+	var requested []string
+	service := NewService(pagedContactsClient(t, pages, &requested))
+	
+	iterator, err := service.IterateContacts(context.Background(), &GetContactsParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("IterateContacts() error = %v", err)
+	}
+	
+	var ids []string
+	for iterator.Next() {
+		ids = append(ids, iterator.Contact().ID)
+	}
+	
+	if err := iterator.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	
+	if strings.Join(ids, ",") != "1,2,3,4,5" {
+		t.Errorf("Expected contacts 1..5, got %v", ids)
+	}
+	
+	if strings.Join(requested, ",") != "1,2,3" {
+		t.Errorf("Expected pages 1,2,3 to be requested, got %v", requested)
+	}
+}
+
+func TestIterateContactsWithoutTotalPages(t *testing.T) {
+	var requested []string
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			u, _ := url.Parse(endpoint)
+			page := u.Query().Get("pageNumber")
+			requested = append(requested, page)
+			
+			// WATI informa solo el total de contactos, sin page ni totalPages
+			id := "a" + page
+			return json.Unmarshal([]byte(`{"result":true,"pageSize":1,"totalCount":2,"contacts":[{"id":"`+id+`"}]}`), result)
+		},
+	})
+	
+	iterator, err := service.IterateContacts(context.Background(), &GetContactsParams{PageSize: 1})
+	if err != nil {
+		t.Fatalf("IterateContacts() error = %v", err)
+	}
+	
+	var ids []string
+	for iterator.Next() {
+		ids = append(ids, iterator.Contact().ID)
+	}
+	
+	if strings.Join(ids, ",") != "a1,a2" {
+		t.Errorf("Expected contacts a1,a2, got %v", ids)
+	}
+	if strings.Join(requested, ",") != "1,2" {
+		t.Errorf("Expected pages 1,2 to be requested, got %v", requested)
+	}
+}
+
+func TestIterateContactsStopEarly(t *testing.T) {
+	pages := [][]Contact{
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "3"}, {ID: "4"}},
+		{{ID: "5"}},
+	}
+	var requested []string
+	service := NewService(pagedContactsClient(t, pages, &requested))
+	
+	iterator, err := service.IterateContacts(context.Background(), &GetContactsParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("IterateContacts() error = %v", err)
+	}
+	
+	for iterator.Next() {
+		if iterator.Contact().ID == "3" {
+			break
+		}
+	}
+	
+	if len(requested) != 2 {
+		t.Errorf("Expected only 2 pages to be requested, got %v", requested)
+	}
+}
+
+func TestIterateContactsContextCanceled(t *testing.T) {
+	pages := [][]Contact{
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "3"}, {ID: "4"}},
+	}
+	var requested []string
+	service := NewService(pagedContactsClient(t, pages, &requested))
+	
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	iterator, err := service.IterateContacts(ctx, &GetContactsParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("IterateContacts() error = %v", err)
+	}
+	
+	count := 0
+	for iterator.Next() {
+		count++
+		cancel()
+	}
+	
+	if count != 2 {
+		t.Errorf("Expected iteration to stop after the first page, got %d contacts", count)
+	}
+	
+	if !errors.Is(iterator.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", iterator.Err())
+	}
+	
+	if len(requested) != 1 {
+		t.Errorf("Expected only 1 page to be requested, got %v", requested)
+	}
+}
+
+func TestGetAllContacts(t *testing.T) {
+	pages := [][]Contact{
+		{{ID: "1"}, {ID: "2"}},
+		{{ID: "3"}},
+	}
+	var requested []string
+	service := NewService(pagedContactsClient(t, pages, &requested))
+	
+	contacts, err := service.GetAllContacts(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllContacts() error = %v", err)
+	}
+	
+	if len(contacts) != 3 {
+		t.Errorf("Expected 3 contacts, got %d", len(contacts))
+	}
+}
+
+func TestGetContactsModifiedSince(t *testing.T) {
+	pages := [][]Contact{
+		{{ID: "1", LastUpdated: "2024-03-10T12:00:00Z"}, {ID: "2", LastUpdated: "2024-03-09T08:30:00.123"}},
+		{{ID: "3", LastUpdated: ""}, {ID: "4", LastUpdated: "2024-03-01T00:00:00Z"}},
+		{{ID: "5", LastUpdated: "2024-02-01T00:00:00Z"}},
+	}
+	var requested []string
+	service := NewService(pagedContactsClient(t, pages, &requested))
+	
+	since := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	modified, err := service.GetContactsModifiedSince(context.Background(), since, &GetContactsParams{PageSize: 2})
+	if err != nil {
+		t.Fatalf("GetContactsModifiedSince() error = %v", err)
+	}
+	
+	var ids []string
+	for _, contact := range modified {
+		ids = append(ids, contact.ID)
+	}
+	
+	if strings.Join(ids, ",") != "1,2,3" {
+		t.Errorf("Expected contacts 1,2,3, got %v", ids)
+	}
+	
+	if strings.Join(requested, ",") != "1,2" {
+		t.Errorf("Expected to stop after page 2, got pages %v", requested)
+	}
+}
+
+// tagsClient simula un contacto con etiquetas y registra las enviadas al actualizar
+func tagsClient(t *testing.T, current []string, sent *[]string) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			switch method {
+			case "GET":
+				data, _ := json.Marshal(map[string]interface{}{"contact": Contact{ID: "c1", Tags: current}})
+				return json.Unmarshal(data, result)
+			case "PUT":
+				data, err := json.Marshal(body)
+				if err != nil {
+					t.Fatalf("invalid body: %v", err)
+				}
+				var request struct {
+					Tags []string `json:"tags"`
+				}
+				if err := json.Unmarshal(data, &request); err != nil {
+					t.Fatalf("invalid body: %v", err)
+				}
+				if !strings.Contains(string(data), `"tags"`) {
+					t.Errorf("Expected tags field in body, got %s", data)
+				}
+				*sent = request.Tags
+			}
+			return nil
+		},
+	}
+}
+
+func TestAddContactTags(t *testing.T) {
+	var sent []string
+	service := NewService(tagsClient(t, []string{"vip", "lead", "vip"}, &sent))
+	
+	if _, err := service.AddContactTags(context.Background(), "c1", []string{"lead", "2024", "new", "2024"}); err != nil {
+		t.Fatalf("AddContactTags() error = %v", err)
+	}
+	
+	if strings.Join(sent, ",") != "vip,lead,2024,new" {
+		t.Errorf("Expected tags vip,lead,2024,new, got %v", sent)
+	}
+}
+
+func TestRemoveContactTags(t *testing.T) {
+	var sent []string
+	service := NewService(tagsClient(t, []string{"vip", "lead", "2024"}, &sent))
+	
+	if _, err := service.RemoveContactTags(context.Background(), "c1", []string{"lead", "missing"}); err != nil {
+		t.Fatalf("RemoveContactTags() error = %v", err)
+	}
+	
+	if strings.Join(sent, ",") != "vip,2024" {
+		t.Errorf("Expected tags vip,2024, got %v", sent)
+	}
+}
+
+func TestRemoveContactTagsLastTag(t *testing.T) {
+	sent := []string{"unchanged"}
+	service := NewService(tagsClient(t, []string{"vip"}, &sent))
+	
+	if _, err := service.RemoveContactTags(context.Background(), "c1", []string{"vip"}); err != nil {
+		t.Fatalf("RemoveContactTags() error = %v", err)
+	}
+	
+	if sent == nil || len(sent) != 0 {
+		t.Errorf("Expected an empty tag list to be sent, got %v", sent)
+	}
+}
+
+func TestContactConsent(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(s *Service) (*Contact, error)
+		want string
+	}{
+		{
+			name: "opt in",
+			call: func(s *Service) (*Contact, error) { return s.OptInContact(context.Background(), "c1") },
+			want: `{"optedIn":true}`,
+		},
+		{
+			name: "opt out",
+			call: func(s *Service) (*Contact, error) { return s.OptOutContact(context.Background(), "c1") },
+			want: `{"optedIn":false,"allowBroadcast":false}`,
+		},
+		{
+			name: "broadcast allowed",
+			call: func(s *Service) (*Contact, error) { return s.SetBroadcastAllowed(context.Background(), "c1", true) },
+			want: `{"allowBroadcast":true}`,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent string
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					if method != "PUT" || endpoint != "/api/v1/updateContact/c1" {
+						t.Errorf("Unexpected request %s %s", method, endpoint)
+					}
+					data, _ := json.Marshal(body)
+					sent = string(data)
+					return nil
+				},
+			}
+			
+			if _, err := tt.call(NewService(mockClient)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			
+			if sent != tt.want {
+				t.Errorf("Expected body %s, got %s", tt.want, sent)
+			}
+		})
+	}
+}
+
+func TestGetContactsSorting(t *testing.T) {
+	var endpoints []string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			endpoints = append(endpoints, endpoint)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.GetContacts(context.Background(), &GetContactsParams{
+		SortBy:    ContactSortByLastUpdated,
+		SortOrder: SortOrderDesc,
+	})
+	if err != nil {
+		t.Fatalf("GetContacts() error = %v", err)
+	}
+	
+	query := queryOf(t, endpoints[0])
+	if query.Get("sortBy") != "lastUpdated" || query.Get("sortOrder") != "desc" {
+		t.Errorf("Expected sort params to be sent, got %s", endpoints[0])
+	}
+	
+	invalid := []*GetContactsParams{
+		{SortBy: "phone"},
+		{SortBy: ContactSortByName, SortOrder: "up"},
+		{SortOrder: SortOrderAsc},
+	}
+	for _, params := range invalid {
+		if _, err := service.GetContacts(context.Background(), params); err == nil {
+			t.Errorf("Expected validation error for %+v", params)
+		}
+	}
+	
+	if len(endpoints) != 1 {
+		t.Errorf("Expected invalid params to be rejected before the request, got %d requests", len(endpoints))
+	}
+}
+
+func TestGetContactsEncodesQuery(t *testing.T) {
+	var gotEndpoint string
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return nil
+		},
+	})
+	
+	_, err := service.GetContacts(context.Background(), &GetContactsParams{
+		Name: "Ana & Juan",
+		Tag:  "vip+gold",
+	})
+	if err != nil {
+		t.Fatalf("GetContacts() error = %v", err)
+	}
+	
+	want := "/api/v1/getContacts?name=Ana+%26+Juan&pageNumber=1&pageSize=20&tag=vip%2Bgold"
+	if gotEndpoint != want {
+		t.Errorf("Expected endpoint %s, got %s", want, gotEndpoint)
+	}
+}
+
+func TestDeleteContacts(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		deleted []string
+	)
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if method != "DELETE" {
+				t.Errorf("Expected DELETE, got %s", method)
+			}
+			if endpoint == "/api/v1/deleteContact/c2" {
+				return fmt.Errorf("contact not found")
+			}
+			
+			mu.Lock()
+			deleted = append(deleted, endpoint)
+			mu.Unlock()
+			return nil
+		},
+	})
+	
+	response, err := service.DeleteContacts(context.Background(), []string{"c1", "c2", "c3"})
+	if err != nil {
+		t.Fatalf("DeleteContacts() error = %v", err)
+	}
+	
+	if len(deleted) != 2 {
+		t.Errorf("Expected 2 delete requests to succeed, got %v", deleted)
+	}
+	if response.SuccessCount != 2 || response.FailureCount != 1 {
+		t.Errorf("Expected 2 successes and 1 failure, got %d and %d", response.SuccessCount, response.FailureCount)
+	}
+	if len(response.Deleted) != 2 || response.Deleted[0] != "c1" || response.Deleted[1] != "c3" {
+		t.Errorf("Expected deleted [c1 c3] in order, got %v", response.Deleted)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Index != 1 || response.Errors[0].ID != "c2" {
+		t.Errorf("Expected failure for c2 at index 1, got %+v", response.Errors)
+	}
+}
+
+func TestDeleteContactsValidation(t *testing.T) {
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("Expected no request for invalid IDs")
+			return nil
+		},
+	})
+	
+	tooMany := make([]string, MaxContactsPerRequest+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("c%d", i)
+	}
+	
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{name: "no IDs", ids: nil},
+		{name: "empty ID", ids: []string{"c1", ""}},
+		{name: "blank ID", ids: []string{"c1", "  "}},
+		{name: "too many", ids: tooMany},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.DeleteContacts(context.Background(), tt.ids); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestGetContactByPhoneExactMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantID   string
+	}{
+		{
+			name:     "exact match after fuzzy results",
+			response: `{"result":true,"contacts":[{"id":"c1","phone":"5491112345678"},{"id":"c2","phone":"+1 555 123 4567"}]}`,
+			wantID:   "c2",
+		},
+		{
+			name:     "match by WhatsApp ID",
+			response: `{"result":true,"contacts":[{"id":"c3","wAid":"15551234567"}]}`,
+			wantID:   "c3",
+		},
+		{
+			name:     "only partial matches",
+			response: `{"result":true,"contacts":[{"id":"c1","phone":"155512345670"},{"id":"c4","phone":"5551234567"}]}`,
+		},
+		{
+			name:     "no results",
+			response: `{"result":true,"contacts":[]}`,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEndpoint string
+			service := NewService(&MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					gotEndpoint = endpoint
+					return json.Unmarshal([]byte(tt.response), result)
+				},
+			})
+			
+			contact, err := service.GetContactByPhone(context.Background(), "+1 (555) 123-4567")
+			
+			if !strings.Contains(gotEndpoint, "phone=15551234567") {
+				t.Errorf("Expected normalized phone in endpoint, got %s", gotEndpoint)
+			}
+			
+			if tt.wantID == "" {
+				if !errors.Is(err, ErrContactNotFound) {
+					t.Errorf("Expected ErrContactNotFound, got %v", err)
+				}
+				return
+			}
+			
+			if err != nil {
+				t.Fatalf("GetContactByPhone() error = %v", err)
+			}
+			if contact.ID != tt.wantID {
+				t.Errorf("Expected contact %s, got %s", tt.wantID, contact.ID)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strconv"
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
 )
 
 // Contact representa un contacto en WATI
@@ -32,6 +34,61 @@ type Contact struct {
 	CurrentFlowNodeId string        `json:"currentFlowNodeId,omitempty"`
 }
 
+// LastUpdatedTime interpreta LastUpdated, aceptando RFC3339 con o sin zona
+// horaria (en cuyo caso se asume UTC)
+func (c *Contact) LastUpdatedTime() (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, c.LastUpdated); err == nil {
+		return t, nil
+	}
+	
+	t, err := time.Parse("2006-01-02T15:04:05.999999999", c.LastUpdated)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid lastUpdated %q: %w", c.LastUpdated, err)
+	}
+	
+	return t, nil
+}
+
+// hasPhone indica si el teléfono o el ID de WhatsApp del contacto, una vez
+// normalizados, coinciden exactamente con phone (ya normalizado)
+func (c *Contact) hasPhone(phone string) bool {
+	for _, candidate := range []string{c.Phone, c.WAId} {
+		if normalized, err := common.ValidatePhoneNumber(candidate); err == nil && normalized == phone {
+			return true
+		}
+	}
+	
+	return false
+}
+
+// HasTag verifica si el contacto tiene una etiqueta específica
+func (c *Contact) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// AddTag agrega una etiqueta al contacto si todavía no la tiene
+func (c *Contact) AddTag(tag string) {
+	if tag != "" && !c.HasTag(tag) {
+		c.Tags = append(c.Tags, tag)
+	}
+}
+
+// RemoveTag elimina una etiqueta del contacto
+func (c *Contact) RemoveTag(tag string) {
+	tags := c.Tags[:0]
+	for _, t := range c.Tags {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	c.Tags = tags
+}
+
 // CustomParam representa un parámetro personalizado del contacto
 type CustomParam struct {
 	Name  string `json:"name"`
@@ -43,8 +100,49 @@ type GetContactsParams struct {
 	PageSize    int    `json:"pageSize,omitempty"`
 	PageNumber  int    `json:"pageNumber,omitempty"`
 	Name        string `json:"name,omitempty"`
+	Email       string `json:"email,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Tag         string `json:"tag,omitempty"`
 	Attribute   string `json:"attribute,omitempty"`
 	CreatedDate string `json:"createdDate,omitempty"`
+	SortBy      string `json:"sortBy,omitempty"`
+	SortOrder   string `json:"sortOrder,omitempty"`
+}
+
+// Campos y sentidos de ordenamiento aceptados por GetContacts
+const (
+	ContactSortByCreated     = "created"
+	ContactSortByLastUpdated = "lastUpdated"
+	ContactSortByName        = "name"
+	
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// MaxContactsPerRequest es la cantidad máxima de contactos por operación en
+// lote (AddContacts y DeleteContacts)
+const MaxContactsPerRequest = 100
+
+// DeleteContactsConcurrency es la cantidad de eliminaciones simultáneas de
+// DeleteContacts. WATI no tiene un endpoint de eliminación en lote, así que
+// cada contacto se elimina con su propia petición.
+const DeleteContactsConcurrency = 5
+
+// SearchField es un campo de contacto por el que se puede buscar
+type SearchField string
+
+const (
+	SearchByName  SearchField = "name"
+	SearchByEmail SearchField = "email"
+	SearchByPhone SearchField = "phone"
+	SearchByTag   SearchField = "tag"
+)
+
+// SearchOptions configura SearchContacts. Sin Fields se busca por nombre.
+type SearchOptions struct {
+	Fields     []SearchField `json:"fields,omitempty"`
+	PageSize   int           `json:"pageSize,omitempty"`
+	PageNumber int           `json:"pageNumber,omitempty"`
 }
 
 // ContactsResponse representa la respuesta de la lista de contactos
@@ -73,6 +171,7 @@ type UpdateContactRequest struct {
 	Email          *string       `json:"email,omitempty"`
 	CustomParams   []CustomParam `json:"customParams,omitempty"`
 	Tags           []string      `json:"tags,omitempty"`
+	OptedIn        *bool         `json:"optedIn,omitempty"`
 	AllowBroadcast *bool         `json:"allowBroadcast,omitempty"`
 	AllowSMS       *bool         `json:"allowSMS,omitempty"`
 }
@@ -103,6 +202,22 @@ type BulkContactResponse struct {
 	} `json:"errors,omitempty"`
 }
 
+// BulkDeleteResponse representa el resultado de DeleteContacts
+type BulkDeleteResponse struct {
+	SuccessCount int               `json:"successCount"`
+	FailureCount int               `json:"failureCount"`
+	Deleted      []string          `json:"deleted"`
+	Errors       []BulkDeleteError `json:"errors,omitempty"`
+}
+
+// BulkDeleteError describe un contacto que no pudo eliminarse; Index es su
+// posición en la lista de IDs
+type BulkDeleteError struct {
+	Index int    `json:"index"`
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
 // BaseResponse representa la respuesta base de la API
 type BaseResponse struct {
 	Result  bool   `json:"result"`
@@ -111,12 +226,7 @@ type BaseResponse struct {
 }
 
 // PaginatedResponse representa una respuesta paginada
-type PaginatedResponse struct {
-	Page       int `json:"page"`
-	PageSize   int `json:"pageSize"`
-	TotalPages int `json:"totalPages"`
-	TotalCount int `json:"totalCount"`
-}
+type PaginatedResponse = common.PaginatedResponse
 
 // Validate valida los datos del contacto
 func (c *CreateContactRequest) Validate() error {
@@ -128,10 +238,11 @@ func (c *CreateContactRequest) Validate() error {
 		return fmt.Errorf("phone is required")
 	}
 	
-	// Validación básica del número de teléfono
-	if len(c.Phone) < 10 {
-		return fmt.Errorf("phone number must be at least 10 digits")
+	phone, err := common.ValidatePhoneNumber(c.Phone)
+	if err != nil {
+		return fmt.Errorf("invalid phone: %w", err)
 	}
+	c.Phone = phone
 	
 	return nil
 }
@@ -152,6 +263,18 @@ func (p *GetContactsParams) ToMap() map[string]string {
 		params["name"] = p.Name
 	}
 	
+	if p.Email != "" {
+		params["email"] = p.Email
+	}
+	
+	if p.Phone != "" {
+		params["phone"] = p.Phone
+	}
+	
+	if p.Tag != "" {
+		params["tag"] = p.Tag
+	}
+	
 	if p.Attribute != "" {
 		params["attribute"] = p.Attribute
 	}
@@ -160,9 +283,47 @@ func (p *GetContactsParams) ToMap() map[string]string {
 		params["createdDate"] = p.CreatedDate
 	}
 	
+	if p.SortBy != "" {
+		params["sortBy"] = p.SortBy
+	}
+	
+	if p.SortOrder != "" {
+		params["sortOrder"] = p.SortOrder
+	}
+	
 	return params
 }
 
+// Validate valida los parámetros de ordenamiento
+func (p *GetContactsParams) Validate() error {
+	if p.SortBy != "" {
+		validFields := []string{ContactSortByCreated, ContactSortByLastUpdated, ContactSortByName}
+		isValid := false
+		for _, field := range validFields {
+			if p.SortBy == field {
+				isValid = true
+				break
+			}
+		}
+		
+		if !isValid {
+			return fmt.Errorf("invalid sortBy %q. Valid fields are: %v", p.SortBy, validFields)
+		}
+	}
+	
+	if p.SortOrder != "" {
+		if p.SortBy == "" {
+			return fmt.Errorf("sortBy is required when sortOrder is set")
+		}
+		
+		if p.SortOrder != SortOrderAsc && p.SortOrder != SortOrderDesc {
+			return fmt.Errorf("invalid sortOrder %q. Valid orders are: %s, %s", p.SortOrder, SortOrderAsc, SortOrderDesc)
+		}
+	}
+	
+	return nil
+}
+
 // SetDefaults establece valores por defecto para GetContactsParams
 func (p *GetContactsParams) SetDefaults() {
 	if p.PageSize <= 0 {
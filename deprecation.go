@@ -0,0 +1,43 @@
+package wati
+
+import (
+	"log"
+	"net/http"
+	"strings"
+)
+
+// DeprecationNoticeFunc recibe el endpoint que WATI marcó como obsoleto y el
+// valor del header Sunset (vacío si solo se envió Deprecation)
+type DeprecationNoticeFunc func(endpoint, sunset string)
+
+// reportDeprecation revisa los headers Deprecation y Sunset de una respuesta.
+// El aviso por log se emite una sola vez por endpoint para no inundar los logs,
+// con el Logger configurado o, si no hay uno, con el logger estándar; el
+// callback configurado con WithDeprecationNotice se invoca en cada respuesta.
+func (c *Client) reportDeprecation(endpoint string, header http.Header) {
+	deprecation := strings.TrimSpace(header.Get("Deprecation"))
+	sunset := strings.TrimSpace(header.Get("Sunset"))
+	if deprecation == "" && sunset == "" {
+		return
+	}
+	
+	// La query no identifica al endpoint
+	endpoint, _, _ = strings.Cut(endpoint, "?")
+	
+	if _, warned := c.deprecationWarned.LoadOrStore(endpoint, true); !warned {
+		var logger Logger = log.Default()
+		if c.logger != nil {
+			logger = c.logger
+		}
+		
+		if sunset != "" {
+			logger.Printf("WARNING: WATI endpoint %s is deprecated and will be removed on %s", endpoint, sunset)
+		} else {
+			logger.Printf("WARNING: WATI endpoint %s is deprecated", endpoint)
+		}
+	}
+	
+	if c.config.DeprecationNotice != nil {
+		c.config.DeprecationNotice(endpoint, sunset)
+	}
+}
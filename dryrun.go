@@ -0,0 +1,82 @@
+package wati
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DryRunResponder genera la respuesta de una petición en modo dry-run a partir
+// del método, el endpoint tal como lo indicó el llamador (ruta y query, sin la
+// ruta del base URL, la versión de API ni los parámetros por defecto) y el
+// cuerpo enviado. Retorna el cuerpo y el código de estado de la respuesta; un
+// error se trata como un fallo de red.
+type DryRunResponder func(method, endpoint string, body []byte) ([]byte, int, error)
+
+// RecordedRequest es una petición capturada en modo dry-run. Endpoint es el
+// indicado por el llamador, estable aunque cambien las opciones del base URL;
+// URI es la ruta y query finales que se habrían enviado.
+type RecordedRequest struct {
+	Method   string
+	Endpoint string
+	URI      string
+	Body     []byte
+}
+
+// RecordedRequests retorna las peticiones capturadas en modo dry-run, en el
+// orden en que se realizaron. Los reintentos se registran como peticiones
+// separadas.
+func (c *Client) RecordedRequests() []RecordedRequest {
+	c.dryRunMu.Lock()
+	defer c.dryRunMu.Unlock()
+	
+	recorded := make([]RecordedRequest, len(c.dryRunRequests))
+	copy(recorded, c.dryRunRequests)
+	return recorded
+}
+
+// ResetRecordedRequests descarta las peticiones capturadas en modo dry-run
+func (c *Client) ResetRecordedRequests() {
+	c.dryRunMu.Lock()
+	defer c.dryRunMu.Unlock()
+	
+	c.dryRunRequests = nil
+}
+
+// dryRun registra req y responde con el DryRunResponder configurado en lugar
+// de enviarla por la red
+func (c *Client) dryRun(req *http.Request, endpoint string) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error reading dry-run request body: %w", err)
+		}
+	}
+	
+	c.dryRunMu.Lock()
+	c.dryRunRequests = append(c.dryRunRequests, RecordedRequest{
+		Method:   req.Method,
+		Endpoint: endpoint,
+		URI:      req.URL.RequestURI(),
+		Body:     body,
+	})
+	c.dryRunMu.Unlock()
+	
+	respBody, statusCode, err := c.config.DryRun(req.Method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
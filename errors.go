@@ -1,8 +1,13 @@
 package wati
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
+	
+	"github.com/diogenes-moreira/wati-sdk/messages"
 )
 
 // WATIError representa un error específico de la API de WATI
@@ -10,18 +15,61 @@ type WATIError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Type    string `json:"type"`
+	
+	// RetryAfter es la espera indicada por el header Retry-After (típicamente en
+	// respuestas 429). Es cero si la respuesta no lo incluía.
+	RetryAfter time.Duration `json:"retryAfter,omitempty"`
+	
+	// AllowedMethods lista los métodos aceptados según el header Allow de una
+	// respuesta 405. Es nil si la respuesta no lo incluía.
+	AllowedMethods []string `json:"allowedMethods,omitempty"`
+	
+	// ValidationErrors contiene los errores por campo cuando la API los informa
+	ValidationErrors []ValidationError `json:"validationErrors,omitempty"`
+	
+	// Details contiene el resto de los campos del cuerpo de error (códigos,
+	// identificadores de traza, etc.)
+	Details map[string]interface{} `json:"details,omitempty"`
 }
 
+// APIError es un alias de WATIError
+type APIError = WATIError
+
 // Error implementa la interfaz error
 func (e *WATIError) Error() string {
+	if len(e.AllowedMethods) > 0 {
+		return fmt.Sprintf("WATI API Error %d: %s (allowed methods: %s)", e.Code, e.Message, strings.Join(e.AllowedMethods, ", "))
+	}
 	return fmt.Sprintf("WATI API Error %d: %s", e.Code, e.Message)
 }
 
+// Is permite comparar con los errores predefinidos mediante errors.Is. Dos
+// WATIError coinciden si tienen el mismo código y tipo, sin importar el mensaje.
+func (e *WATIError) Is(target error) bool {
+	t, ok := target.(*WATIError)
+	if !ok || e == nil || t == nil {
+		return false
+	}
+	
+	return e.Code == t.Code && e.Type == t.Type
+}
+
 // IsRetryable indica si el error es reintentable
 func (e *WATIError) IsRetryable() bool {
 	return e.Code >= 500 || e.Code == 429
 }
 
+// HasValidationErrors indica si la API informó errores por campo
+func (e *WATIError) HasValidationErrors() bool {
+	return len(e.ValidationErrors) > 0
+}
+
+// GetRetryAfter retorna cuánto esperar antes de reintentar según la API, o cero
+// si la respuesta no lo indicaba
+func (e *WATIError) GetRetryAfter() time.Duration {
+	return e.RetryAfter
+}
+
 // IsAuthenticationError indica si es un error de autenticación
 func (e *WATIError) IsAuthenticationError() bool {
 	return e.Code == 401
@@ -37,11 +85,28 @@ func (e *WATIError) IsNotFoundError() bool {
 	return e.Code == 404
 }
 
+// IsConflictError indica si la API rechazó la petición por un conflicto con el
+// estado actual del recurso
+func (e *WATIError) IsConflictError() bool {
+	return e.Code == 409
+}
+
+// IsContactNotFoundError indica si el error equivale a ErrContactNotFound. Lo
+// usa contacts.ErrContactNotFound para coincidir también con el centinela raíz.
+func (e *WATIError) IsContactNotFoundError() bool {
+	return e != nil && e.Code == ErrContactNotFound.Code && e.Type == ErrContactNotFound.Type
+}
+
 // IsRateLimitError indica si es un error de límite de velocidad
 func (e *WATIError) IsRateLimitError() bool {
 	return e.Code == 429
 }
 
+// IsMethodNotAllowedError indica si la API rechazó el método HTTP usado
+func (e *WATIError) IsMethodNotAllowedError() bool {
+	return e.Code == 405
+}
+
 // IsServerError indica si es un error del servidor
 func (e *WATIError) IsServerError() bool {
 	return e.Code >= 500
@@ -108,8 +173,22 @@ var (
 		Message: "Contact not found",
 		Type:    "contact_error",
 	}
+	
+	ErrCircuitOpen = &WATIError{
+		Code:    503,
+		Message: "Circuit breaker open - requests to WATI are paused after repeated failures",
+		Type:    "circuit_open",
+	}
 )
 
+// ErrQuotaExceeded se retorna cuando WithQuotaGuard bloquea un envío masivo
+// porque la cuota restante no alcanza para todos los destinatarios
+var ErrQuotaExceeded = messages.ErrQuotaExceeded
+
+// ErrClientClosed se retorna en las peticiones iniciadas después de Close.
+// Envuelve context.Canceled, así que errors.Is(err, context.Canceled) es true.
+var ErrClientClosed = fmt.Errorf("wati client closed: %w", context.Canceled)
+
 // NewWATIError crea un nuevo error de WATI basado en el código de estado HTTP
 func NewWATIError(statusCode int, message string) *WATIError {
 	errorType := "unknown"
@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -5,8 +7,8 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/tu-usuario/go-wati"
-	"github.com/tu-usuario/go-wati/messages"
+	"github.com/diogenes-moreira/wati-sdk"
+	"github.com/diogenes-moreira/wati-sdk/messages"
 )
 
 func main() {
@@ -63,7 +65,7 @@ func main() {
 	
 	buttonTitles := []string{"Sí, me interesa", "No, gracias", "Más información"}
 	
-	response3, err := client.Messages().SendQuickReplyButtons(
+	_, err = client.Messages().SendQuickReplyButtons(
 		ctx,
 		"1234567890",
 		"¿Te interesa nuestro nuevo producto?",
@@ -85,7 +87,7 @@ func main() {
 		"Información": {"Horarios", "Ubicación", "Contacto"},
 	}
 	
-	response4, err := client.Messages().SendListMenu(
+	_, err = client.Messages().SendListMenu(
 		ctx,
 		"1234567890",
 		"¿En qué podemos ayudarte hoy?",
@@ -1,15 +1,16 @@
+//go:build ignore
+
 package main
 
 import (
 	"context"
 	"fmt"
 	"log"
-	"os"
 	"strings"
 
-	"github.com/tu-usuario/go-wati"
-	"github.com/tu-usuario/go-wati/chatbots"
-	"github.com/tu-usuario/go-wati/media"
+	"github.com/diogenes-moreira/wati-sdk"
+	"github.com/diogenes-moreira/wati-sdk/chatbots"
+	"github.com/diogenes-moreira/wati-sdk/media"
 )
 
 func main() {
@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -6,8 +8,8 @@ import (
 	"log"
 	"time"
 
-	"github.com/tu-usuario/go-wati"
-	"github.com/tu-usuario/go-wati/contacts"
+	"github.com/diogenes-moreira/wati-sdk"
+	"github.com/diogenes-moreira/wati-sdk/contacts"
 )
 
 func main() {
@@ -24,11 +26,11 @@ func main() {
 	fmt.Println("=== Creando nuevo contacto ===")
 	
 	newContact := &contacts.CreateContactRequest{
-		WhatsappNumber: "1234567890",
-		FirstName:      "Juan",
-		LastName:       "Pérez",
-		Email:          "juan.perez@email.com",
-		Tags:           []string{"cliente", "premium"},
+		Phone:     "1234567890",
+		FirstName: "Juan",
+		LastName:  "Pérez",
+		Email:     "juan.perez@email.com",
+		Tags:      []string{"cliente", "premium"},
 		CustomParams: []contacts.CustomParam{
 			{Name: "empresa", Value: "Tech Corp"},
 			{Name: "cargo", Value: "Gerente"},
@@ -107,8 +109,9 @@ func main() {
 	fmt.Println("\n=== Actualizando contacto ===")
 	
 	if foundContact != nil {
+		email := "juan.perez.nuevo@email.com"
 		updateData := &contacts.UpdateContactRequest{
-			Email: "juan.perez.nuevo@email.com",
+			Email: &email,
 			Tags:  []string{"cliente", "premium", "actualizado"},
 			CustomParams: []contacts.CustomParam{
 				{Name: "empresa", Value: "New Tech Corp"},
@@ -130,25 +133,25 @@ func main() {
 	
 	bulkContacts := []*contacts.CreateContactRequest{
 		{
-			WhatsappNumber: "1111111111",
-			FirstName:      "María",
-			LastName:       "García",
-			Email:          "maria.garcia@email.com",
-			Tags:           []string{"cliente", "nuevo"},
+			Phone:     "1111111111",
+			FirstName: "María",
+			LastName:  "García",
+			Email:     "maria.garcia@email.com",
+			Tags:      []string{"cliente", "nuevo"},
 		},
 		{
-			WhatsappNumber: "2222222222",
-			FirstName:      "Carlos",
-			LastName:       "López",
-			Email:          "carlos.lopez@email.com",
-			Tags:           []string{"prospecto"},
+			Phone:     "2222222222",
+			FirstName: "Carlos",
+			LastName:  "López",
+			Email:     "carlos.lopez@email.com",
+			Tags:      []string{"prospecto"},
 		},
 		{
-			WhatsappNumber: "3333333333",
-			FirstName:      "Ana",
-			LastName:       "Martínez",
-			Email:          "ana.martinez@email.com",
-			Tags:           []string{"cliente", "premium"},
+			Phone:     "3333333333",
+			FirstName: "Ana",
+			LastName:  "Martínez",
+			Email:     "ana.martinez@email.com",
+			Tags:      []string{"cliente", "premium"},
 		},
 	}
 	
@@ -175,9 +178,7 @@ func main() {
 		tagCount := make(map[string]int)
 		for _, contact := range allContacts {
 			for _, tag := range contact.Tags {
-				if tagStr, ok := tag.(string); ok {
-					tagCount[tagStr]++
-				}
+				tagCount[tag]++
 			}
 		}
 		
@@ -1,3 +1,5 @@
+//go:build ignore
+
 package main
 
 import (
@@ -9,8 +11,8 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/tu-usuario/go-wati"
-	"github.com/tu-usuario/go-wati/webhooks"
+	"github.com/diogenes-moreira/wati-sdk"
+	"github.com/diogenes-moreira/wati-sdk/webhooks"
 )
 
 func main() {
@@ -241,12 +243,13 @@ func processIncomingMessage(client wati.WATIClient, data webhooks.MessageReceive
 	switch messageText {
 	case "hola", "Hola", "HOLA":
 		// Responder con botones de opciones
-		return client.Messages().SendQuickReplyButtons(
+		_, err := client.Messages().SendQuickReplyButtons(
 			ctx,
 			data.From,
 			"¡Hola! ¿En qué puedo ayudarte?",
 			[]string{"Información", "Soporte", "Ventas"},
 		)
+		return err
 		
 	case "menu", "Menu", "MENU":
 		// Responder with lista de opciones
@@ -256,22 +259,24 @@ func processIncomingMessage(client wati.WATIClient, data webhooks.MessageReceive
 			"Empresa": {"Sobre nosotros", "Contacto", "Ubicación"},
 		}
 		
-		return client.Messages().SendListMenu(
+		_, err := client.Messages().SendListMenu(
 			ctx,
 			data.From,
 			"¿Qué te interesa conocer?",
 			"Ver opciones",
 			menuItems,
 		)
+		return err
 		
 	case "gracias", "Gracias", "GRACIAS":
 		// Respuesta simple
-		return client.Messages().SendSimpleTemplateMessage(
+		_, err := client.Messages().SendSimpleTemplateMessage(
 			ctx,
 			data.From,
 			"thank_you",
 			"agradecimientos",
 		)
+		return err
 	}
 	
 	return nil
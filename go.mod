@@ -2,4 +2,14 @@ module github.com/diogenes-moreira/wati-sdk
 
 go 1.21
 
-require golang.org/x/time v0.5.0
+require (
+	go.opentelemetry.io/otel v1.29.0
+	go.opentelemetry.io/otel/trace v1.29.0
+	golang.org/x/time v0.5.0
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/otel/metric v1.29.0 // indirect
+)
@@ -2,11 +2,17 @@ package wati
 
 import (
 	"context"
+	"crypto/tls"
 	"io"
+	"net"
+	"net/http"
+	"time"
 	
-	"github.com/tu-usuario/go-wati/contacts"
-	"github.com/tu-usuario/go-wati/messages"
-	"github.com/tu-usuario/go-wati/chatbots"
+	"github.com/diogenes-moreira/wati-sdk/chatbots"
+	"github.com/diogenes-moreira/wati-sdk/contacts"
+	"github.com/diogenes-moreira/wati-sdk/media"
+	"github.com/diogenes-moreira/wati-sdk/messages"
+	"github.com/diogenes-moreira/wati-sdk/webhooks"
 )
 
 // ContactsService define la interfaz para el servicio de contactos
@@ -17,11 +23,28 @@ type ContactsService interface {
 	AddContact(ctx context.Context, contact *contacts.CreateContactRequest) (*contacts.Contact, error)
 	UpdateContact(ctx context.Context, id string, contact *contacts.UpdateContactRequest) (*contacts.Contact, error)
 	DeleteContact(ctx context.Context, id string) error
+	DeleteContacts(ctx context.Context, ids []string) (*contacts.BulkDeleteResponse, error)
+	
+	// Etiquetas
+	UpdateContactTags(ctx context.Context, id string, tags []string) (*contacts.Contact, error)
+	AddContactTags(ctx context.Context, id string, tags []string) (*contacts.Contact, error)
+	RemoveContactTags(ctx context.Context, id string, tags []string) (*contacts.Contact, error)
+	
+	// Consentimiento
+	OptInContact(ctx context.Context, id string) (*contacts.Contact, error)
+	OptOutContact(ctx context.Context, id string) (*contacts.Contact, error)
+	SetBroadcastAllowed(ctx context.Context, id string, allowed bool) (*contacts.Contact, error)
 	
 	// Búsqueda y filtrado
-	SearchContacts(ctx context.Context, query string) (*contacts.ContactsResponse, error)
+	SearchContacts(ctx context.Context, query string, opts ...*contacts.SearchOptions) (*contacts.ContactsResponse, error)
+	GetContactByPhone(ctx context.Context, phone string) (*contacts.Contact, error)
 	FilterContacts(ctx context.Context, filter *contacts.ContactFilter) (*contacts.ContactsResponse, error)
 	
+	// Paginación automática
+	GetAllContacts(ctx context.Context) ([]contacts.Contact, error)
+	IterateContacts(ctx context.Context, params *contacts.GetContactsParams) (*contacts.ContactIterator, error)
+	GetContactsModifiedSince(ctx context.Context, since time.Time, params *contacts.GetContactsParams) ([]contacts.Contact, error)
+	
 	// Operaciones en lote
 	AddContacts(ctx context.Context, contacts []*contacts.CreateContactRequest) (*contacts.BulkContactResponse, error)
 }
@@ -31,21 +54,50 @@ type MessagesService interface {
 	// Mensajes de plantilla
 	SendTemplateMessage(ctx context.Context, req *messages.SendTemplateMessageRequest) (*messages.MessageResponse, error)
 	SendTemplateMessages(ctx context.Context, req *messages.SendTemplateMessagesRequest) (*messages.BulkMessageResponse, error)
+	SendTemplateMessagesBatched(ctx context.Context, req *messages.SendTemplateMessagesRequest) (*messages.BulkMessageResponse, error)
+	SendTemplateMessagesConcurrent(ctx context.Context, req *messages.SendTemplateMessagesRequest, concurrency int) (*messages.BulkMessageResponse, error)
+	SendTemplateMessageWithParams(ctx context.Context, phone, templateName, broadcastName string, params map[string]string) (*messages.MessageResponse, error)
+	SendTemplateMessageOrdered(ctx context.Context, phone, templateName, broadcastName string, params []string) (*messages.MessageResponse, error)
+	SendImageTemplate(ctx context.Context, phone, templateName, broadcastName, imageURL string, bodyParams []messages.Parameter) (*messages.MessageResponse, error)
+	SendTemplateMessageWithRetryLater(ctx context.Context, req *messages.SendTemplateMessageRequest, retryAfter time.Duration, callback messages.SendResultFunc)
+	SendSimpleTemplateMessage(ctx context.Context, phone, templateName, broadcastName string) (*messages.MessageResponse, error)
+	
+	// Mensajes de sesión
+	SendSessionMessage(ctx context.Context, whatsappNumber, text string) (*messages.MessageResponse, error)
+	SendSessionFile(ctx context.Context, whatsappNumber string, file io.Reader, fileName, caption string) (*messages.MessageResponse, error)
 	
 	// Mensajes interactivos
 	SendInteractiveListMessage(ctx context.Context, req *messages.InteractiveListMessageRequest) (*messages.MessageResponse, error)
 	SendInteractiveButtonMessage(ctx context.Context, req *messages.InteractiveButtonMessageRequest) (*messages.MessageResponse, error)
+	SendInteractiveCTAMessage(ctx context.Context, req *messages.InteractiveCTAMessageRequest) (*messages.MessageResponse, error)
+	SendQuickReplyButtons(ctx context.Context, phone, bodyText string, buttonTitles []string, options ...messages.MessageOption) (*messages.MessageResponse, error)
+	SendListMenu(ctx context.Context, phone, bodyText, buttonText string, menuItems map[string][]string, options ...messages.MessageOption) (*messages.MessageResponse, error)
+	
+	// Mensajes de productos y catálogo
+	SendProductMessage(ctx context.Context, req *messages.ProductMessageRequest) (*messages.MessageResponse, error)
 	
 	// Gestión de plantillas
 	GetMessageTemplates(ctx context.Context) (*messages.TemplatesResponse, error)
 	GetMessageTemplate(ctx context.Context, name string) (*messages.Template, error)
+	GetActiveTemplates(ctx context.Context) ([]messages.Template, error)
+	WaitForTemplateApproval(ctx context.Context, name string, timeout time.Duration) (*messages.Template, error)
 	
 	// Historial de mensajes
 	GetMessages(ctx context.Context, params *messages.GetMessagesParams) (*messages.MessagesResponse, error)
+	GetMessagesByDateRange(ctx context.Context, fromDate, toDate string, params *messages.GetMessagesParams) (*messages.MessagesResponse, error)
+	GetMessagesBetween(ctx context.Context, from, to time.Time, params *messages.GetMessagesParams) (*messages.MessagesResponse, error)
+	GetMessagesByPhone(ctx context.Context, phone string, params *messages.GetMessagesParams) (*messages.MessagesResponse, error)
+	GetAllMessages(ctx context.Context, params *messages.GetMessagesParams) ([]messages.Message, error)
+	IterateMessages(ctx context.Context, params *messages.GetMessagesParams) *messages.MessageIterator
 	GetMessage(ctx context.Context, id string) (*messages.Message, error)
+	GetConversation(ctx context.Context, whatsappNumber string, resolveMedia bool) ([]messages.Message, error)
+	ExportConversation(ctx context.Context, whatsappNumber string, w io.Writer, format string) error
 	
 	// Estado de mensajes
-	GetMessageStatus(ctx context.Context, id string) (*MessageStatus, error)
+	GetMessageStatus(ctx context.Context, id string) (*messages.MessageStatus, error)
+	MarkMessageRead(ctx context.Context, messageID string) error
+	WaitForMessageStatus(ctx context.Context, id string, timeout time.Duration) (*messages.MessageStatus, error)
+	SendAndWaitForDelivery(ctx context.Context, req *messages.SendTemplateMessageRequest, timeout time.Duration) (*messages.MessageStatus, error)
 }
 
 // ChatbotsService define la interfaz para el servicio de chatbots
@@ -55,29 +107,95 @@ type ChatbotsService interface {
 	StartChatbot(ctx context.Context, req *chatbots.StartChatbotRequest) (*chatbots.ChatbotResponse, error)
 	StopChatbot(ctx context.Context, id string) error
 	UpdateChatStatus(ctx context.Context, req *chatbots.UpdateChatStatusRequest) (*chatbots.ChatStatusResponse, error)
+	GetChatStatus(ctx context.Context, whatsappNumber string) (*chatbots.ChatStatusResponse, error)
+	RemoveTagsFromChat(ctx context.Context, whatsappNumber string, tags []string) (*chatbots.ChatStatusResponse, error)
+	
+	// Gestión de chatbots
+	CreateChatbot(ctx context.Context, req *chatbots.CreateChatbotRequest) (*chatbots.Chatbot, error)
+	GetActiveChatbots(ctx context.Context) ([]chatbots.Chatbot, error)
+	GetChatbotByName(ctx context.Context, name string) (*chatbots.Chatbot, error)
+	GetChatbotsByKeyword(ctx context.Context, keyword string) ([]chatbots.Chatbot, error)
+	UpdateChatbotKeywords(ctx context.Context, id string, keywords []string) (*chatbots.Chatbot, error)
+	StartChatbotForContact(ctx context.Context, chatbotID, whatsappNumber string) (*chatbots.ChatbotResponse, error)
+	
+	// Atención de chats
+	TransferChatToHuman(ctx context.Context, whatsappNumber, userID string, notes string) (*chatbots.ChatStatusResponse, error)
+	CloseChatSession(ctx context.Context, whatsappNumber string, notes string) (*chatbots.ChatStatusResponse, error)
+	
+	// Flujos de conversación
+	GetFlows(ctx context.Context) (*chatbots.FlowsResponse, error)
+	GetFlow(ctx context.Context, id string) (*chatbots.ChatFlow, error)
+	CreateFlow(ctx context.Context, req *chatbots.CreateFlowRequest) (*chatbots.ChatFlow, error)
+	UpdateFlow(ctx context.Context, id string, req *chatbots.UpdateFlowRequest) (*chatbots.ChatFlow, error)
+	DeleteFlow(ctx context.Context, id string) error
+	
+	// Sesiones de chatbot
+	GetChatSession(ctx context.Context, whatsappNumber string) (*chatbots.ChatSession, error)
+	ListChatSessions(ctx context.Context, params *chatbots.ListChatSessionsParams) (*chatbots.ChatSessionsResponse, error)
+	GetChatbotSessions(ctx context.Context, chatbotID string, params *chatbots.GetSessionsParams) (*chatbots.ChatSessionsResponse, error)
+	
+	// Variables de flujo de la sesión
+	GetSessionVariables(ctx context.Context, whatsappNumber string) (map[string]interface{}, error)
+	SetSessionVariable(ctx context.Context, whatsappNumber, key string, value interface{}) error
 }
 
 // MediaService define la interfaz para el servicio de media
 type MediaService interface {
-	GetMediaByFileName(ctx context.Context, fileName string) (*MediaResponse, error)
-	UploadMedia(ctx context.Context, file io.Reader, fileName string, mediaType string) (*UploadResponse, error)
+	GetMediaByFileName(ctx context.Context, fileName string) (*media.MediaResponse, error)
+	UploadMedia(ctx context.Context, file io.Reader, fileName string, mediaType string) (*media.UploadResponse, error)
+	UploadMediaWithRequest(ctx context.Context, req *media.UploadRequest) (*media.UploadResponse, error)
+	UploadMediaReliable(ctx context.Context, req *media.UploadRequest, opts *media.ReliableUploadOptions) (*media.UploadResponse, error)
+	UploadBatch(ctx context.Context, reqs []*media.UploadRequest, concurrency int, opts ...*media.BatchUploadOptions) (*media.BatchUploadResponse, error)
 	DeleteMedia(ctx context.Context, fileName string) error
+	DownloadMedia(ctx context.Context, fileName string, w io.Writer) (int64, error)
 	GetMediaURL(ctx context.Context, fileName string) (string, error)
+	
+	// Subidas por tipo
+	UploadImage(ctx context.Context, file io.Reader, fileName string, caption string) (*media.UploadResponse, error)
+	UploadDocument(ctx context.Context, file io.Reader, fileName string, caption string) (*media.UploadResponse, error)
+	ValidateUpload(fileName string, size int64, mimeType string) error
+	
+	// Listado y estadísticas
+	ListMedia(ctx context.Context, params *media.GetMediaParams) (*media.MediaListResponse, error)
+	GetImages(ctx context.Context, params *media.GetMediaParams) (*media.MediaListResponse, error)
+	GetMediaStats(ctx context.Context) (*media.MediaStatsResponse, error)
 }
 
 // WebhooksService define la interfaz para el servicio de webhooks
 type WebhooksService interface {
 	// Configuración de webhooks
-	RegisterWebhook(ctx context.Context, url string, events []WebhookEventType) error
+	RegisterWebhook(ctx context.Context, url string, events []webhooks.WebhookEventType) error
 	UnregisterWebhook(ctx context.Context, url string) error
-	ListWebhooks(ctx context.Context) (*WebhooksResponse, error)
+	ListWebhooks(ctx context.Context) (*webhooks.WebhooksResponse, error)
+	RetryWebhookDelivery(ctx context.Context, webhookURL, deliveryID string) error
 	
 	// Manejo de eventos
-	HandleWebhook(payload []byte, signature string) (*WebhookEvent, error)
+	HandleWebhook(payload []byte, signature string) (*webhooks.WebhookEvent, error)
 	ValidateWebhookSignature(payload []byte, signature string) bool
+	WebhookHandler() http.HandlerFunc
+	RegisterHandler(eventType webhooks.WebhookEventType, handler webhooks.WebhookHandler)
+	RegisterDefaultHandler(handler webhooks.WebhookHandler)
+	UnregisterHandler(eventType webhooks.WebhookEventType)
+	UnregisterAllHandlers(eventType webhooks.WebhookEventType)
+	SetSecret(secret string)
+	SetRequireSignature(require bool)
+	SetMaxEventAge(d time.Duration)
+	SetSignatureAlgo(algo webhooks.SignatureAlgo, encoding webhooks.SignatureEncoding)
+	SetSignatureHeader(name string)
+	SetTLSConfig(config *tls.Config)
 	
 	// Servidor de webhooks
-	StartWebhookServer(port int, handlers map[WebhookEventType]WebhookHandler) error
+	StartWebhookServer(port int, handlers map[webhooks.WebhookEventType]webhooks.WebhookHandler) error
+	StartWebhookServerTLS(port int, certFile, keyFile string, handlers map[webhooks.WebhookEventType]webhooks.WebhookHandler) error
+	StartWebhookServerAsync(port, workers int) error
+	StartWebhookServerWithListener(listener net.Listener, handlers map[webhooks.WebhookEventType]webhooks.WebhookHandler) error
+	StartWebhookServerAsyncWithListener(listener net.Listener, workers int) error
 	StopWebhookServer() error
+	TestWebhook(ctx context.Context, webhookURL string) error
+	TestWebhookEvent(ctx context.Context, webhookURL string, event *webhooks.WebhookEvent) error
+	QueueDepth() int
+	DroppedEvents() uint64
+	GetServerStatus() bool
+	GetServerPort() int
 }
 
@@ -0,0 +1,144 @@
+package wati
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Logger es la interfaz mínima que usa el cliente para el logging de debug.
+// *log.Logger y logrus la implementan; para zap, slog u otros basta con
+// adaptar una función con LoggerFunc.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggerFunc adapta una función a la interfaz Logger, por ejemplo
+// wati.LoggerFunc(sugar.Debugf) con zap
+type LoggerFunc func(format string, args ...interface{})
+
+// Printf implementa Logger
+func (f LoggerFunc) Printf(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// redacted reemplaza los valores sensibles en los logs
+const redacted = "[REDACTED]"
+
+// sensitiveFields son los campos JSON y parámetros de query cuyo valor nunca se
+// registra, comparados sin distinguir mayúsculas
+var sensitiveFields = map[string]bool{
+	"authorization": true,
+	"token":         true,
+	"accesstoken":   true,
+	"apikey":        true,
+	"secret":        true,
+	"password":      true,
+}
+
+// logAttempt registra un intento de petición con su resultado y duración
+func (c *Client) logAttempt(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+	if c.logger == nil {
+		return
+	}
+	
+	target := c.redactURL(req.URL)
+	if err != nil {
+		c.logger.Printf("wati: %s %s failed after %s: %s", req.Method, target, elapsed, c.redactString(err.Error()))
+		return
+	}
+	
+	c.logger.Printf("wati: %s %s -> %d (%s)", req.Method, target, resp.StatusCode, elapsed)
+	
+	if c.config.LogBodies {
+		c.logger.Printf("wati: %s %s request headers: %v", req.Method, target, redactHeader(req.Header))
+	}
+}
+
+// logBody registra el cuerpo de una petición o respuesta si LogBodies está
+// habilitado
+func (c *Client) logBody(kind, method, endpoint string, body []byte) {
+	if c.logger == nil || !c.config.LogBodies || len(body) == 0 {
+		return
+	}
+	
+	target := c.redactString(endpoint)
+	if u, err := url.Parse(endpoint); err == nil {
+		target = c.redactURL(u)
+	}
+	
+	c.logger.Printf("wati: %s %s %s body: %s", method, target, kind, c.redactBody(body))
+}
+
+// redactString oculta el token del cliente donde aparezca
+func (c *Client) redactString(s string) string {
+	if c.config.Token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.config.Token, redacted)
+}
+
+// redactURL retorna la ruta y query de u con los parámetros sensibles ocultos
+func (c *Client) redactURL(u *url.URL) string {
+	query := u.Query()
+	for key := range query {
+		if sensitiveFields[strings.ToLower(key)] {
+			query.Set(key, redacted)
+		}
+	}
+	
+	target := u.Path
+	if len(query) > 0 {
+		target += "?" + strings.ReplaceAll(query.Encode(), url.QueryEscape(redacted), redacted)
+	}
+	return c.redactString(target)
+}
+
+// redactBody oculta los campos sensibles de un cuerpo JSON, en cualquier nivel
+// de anidamiento, y el token del cliente. Un cuerpo que no es JSON solo se
+// limpia del token.
+func (c *Client) redactBody(body []byte) string {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return c.redactString(string(body))
+	}
+	
+	cleaned, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return c.redactString(string(body))
+	}
+	return c.redactString(string(cleaned))
+}
+
+// redactValue reemplaza los valores de los campos sensibles de value
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item)
+		}
+	}
+	
+	return value
+}
+
+// redactHeader retorna una copia de header con las credenciales ocultas
+func redactHeader(header http.Header) http.Header {
+	cleaned := header.Clone()
+	for key := range cleaned {
+		if sensitiveFields[strings.ToLower(key)] {
+			cleaned.Set(key, redacted)
+		}
+	}
+	return cleaned
+}
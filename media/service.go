@@ -1,14 +1,21 @@
 package media
 
 import (
-	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
 )
 
 // HTTPClient define la interfaz para realizar peticiones HTTP
@@ -16,6 +23,12 @@ type HTTPClient interface {
 	DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
 }
 
+// StreamingHTTPClient define la interfaz para peticiones HTTP cuyo cuerpo se
+// transmite en streaming (subidas y descargas de archivos)
+type StreamingHTTPClient interface {
+	DoStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error)
+}
+
 // Service implementa MediaService
 type Service struct {
 	client HTTPClient
@@ -62,50 +75,452 @@ func (s *Service) UploadMediaWithRequest(ctx context.Context, req *UploadRequest
 		return nil, fmt.Errorf("request is required")
 	}
 	
+	if err := detectRequestMediaType(req); err != nil {
+		return nil, err
+	}
+	
+	req.SetDefaults()
+	
 	if err := req.Validate(); err != nil {
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 	
-	// Crear multipart form
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
+	response, _, err := s.uploadMultipart(ctx, req)
+	return response, err
+}
+
+// uploadMultipart envía req como multipart. El canal retornado se cierra
+// cuando la goroutine que lee req.File terminó.
+func (s *Service) uploadMultipart(ctx context.Context, req *UploadRequest) (*UploadResponse, <-chan struct{}, error) {
+	// Construir el multipart form en streaming: el writer escribe en el pipe
+	// mientras la petición HTTP lee del otro extremo, de modo que el uso de
+	// memoria no depende del tamaño del archivo
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	done := make(chan struct{})
+	
+	go func() {
+		defer close(done)
+		pw.CloseWithError(writeMultipartBody(writer, req))
+	}()
+	
+	// Realizar petición HTTP personalizada para multipart
+	response, err := s.doMultipartRequest(ctx, "POST", "/api/v1/uploadMedia", pr, writer.FormDataContentType())
+	
+	// Desbloquear al writer si la petición terminó sin consumir todo el cuerpo
+	pr.Close()
+	
+	if err != nil {
+		return nil, done, fmt.Errorf("error uploading media: %w", err)
+	}
+	
+	return response, done, nil
+}
+
+// sniffLength es la cantidad de bytes que http.DetectContentType examina
+const sniffLength = 512
+
+// genericMimeTypes son los tipos detectados que no alcanzan para decidir el
+// tipo de media (por ejemplo, los documentos de Office se detectan como zip);
+// en esos casos se usa la extensión del archivo
+var genericMimeTypes = map[string]bool{
+	"application/octet-stream": true,
+	"application/zip":          true,
+	"application/ogg":          true,
+}
+
+// DetectMimeType detecta el tipo MIME de un archivo a partir de sus primeros
+// 512 bytes, sin confiar en la extensión. El lector queda en la posición en
+// que estaba antes de la llamada.
+func DetectMimeType(r io.ReadSeeker) (string, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("error reading file position: %w", err)
+	}
+	
+	buf := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("error reading file content: %w", err)
+	}
+	
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return "", fmt.Errorf("error rewinding file: %w", err)
+	}
+	
+	// Descartar parámetros como "; charset=utf-8"
+	mimeType, _, _ := strings.Cut(http.DetectContentType(buf[:n]), ";")
+	
+	return strings.TrimSpace(mimeType), nil
+}
+
+// detectRequestMediaType completa MediaType a partir del contenido del archivo
+// cuando no se especificó y el archivo permite volver al inicio. Si el
+// contenido no es concluyente, SetDefaults recurre a la extensión. Un tipo
+// detectado que no está soportado solo se rechaza si contradice la extensión:
+// un .mkv se detecta como video/webm y un .txt puede detectarse como
+// text/html, y en esos casos se usa la extensión.
+func detectRequestMediaType(req *UploadRequest) error {
+	if req.MediaType != "" {
+		return nil
+	}
+	
+	seeker, ok := req.File.(io.ReadSeeker)
+	if !ok {
+		return nil
+	}
 	
+	mimeType, err := DetectMimeType(seeker)
+	if err != nil {
+		return fmt.Errorf("error detecting MIME type: %w", err)
+	}
+	
+	if genericMimeTypes[mimeType] {
+		return nil
+	}
+	
+	mediaType := GetMediaTypeFromMimeType(mimeType)
+	if !IsSupportedMimeType(mediaType, mimeType) {
+		extMimeType := GetMimeTypeFromExtension(filepath.Ext(req.FileName))
+		if IsSupportedMimeType(GetMediaTypeFromMimeType(extMimeType), extMimeType) && mimeFamily(extMimeType) == mimeFamily(mimeType) {
+			return nil
+		}
+		return fmt.Errorf("validation error: unsupported content type %s for %s", mimeType, req.FileName)
+	}
+	
+	req.MediaType = string(mediaType)
+	return nil
+}
+
+// mimeFamily retorna el tipo principal de un tipo MIME, por ejemplo "video"
+func mimeFamily(mimeType string) string {
+	family, _, _ := strings.Cut(mimeType, "/")
+	return family
+}
+
+// writeMultipartBody escribe el archivo y los campos adicionales en el multipart writer
+func writeMultipartBody(writer *multipart.Writer, req *UploadRequest) error {
 	// Agregar el archivo
 	part, err := writer.CreateFormFile("file", req.FileName)
 	if err != nil {
-		return nil, fmt.Errorf("error creating form file: %w", err)
+		return fmt.Errorf("error creating form file: %w", err)
+	}
+	
+	file := req.File
+	if req.Progress != nil {
+		total, ok := readerSize(req.File)
+		if !ok {
+			total = -1
+		}
+		file = &progressReader{reader: req.File, total: total, progress: req.Progress}
 	}
 	
-	_, err = io.Copy(part, req.File)
+	_, err = io.Copy(part, file)
 	if err != nil {
-		return nil, fmt.Errorf("error copying file data: %w", err)
+		return fmt.Errorf("error copying file data: %w", err)
 	}
 	
 	// Agregar campos adicionales
 	if req.MediaType != "" {
-		writer.WriteField("mediaType", req.MediaType)
+		if err := writer.WriteField("mediaType", req.MediaType); err != nil {
+			return fmt.Errorf("error writing mediaType field: %w", err)
+		}
 	}
 	
 	if req.Caption != "" {
-		writer.WriteField("caption", req.Caption)
+		if err := writer.WriteField("caption", req.Caption); err != nil {
+			return fmt.Errorf("error writing caption field: %w", err)
+		}
 	}
 	
 	if req.Description != "" {
-		writer.WriteField("description", req.Description)
+		if err := writer.WriteField("description", req.Description); err != nil {
+			return fmt.Errorf("error writing description field: %w", err)
+		}
+	}
+	
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing multipart writer: %w", err)
+	}
+	
+	return nil
+}
+
+// retryableError lo implementan *wati.WATIError y *wati.NetworkError; se
+// declara aquí para no depender del paquete raíz
+type retryableError interface {
+	IsRetryable() bool
+}
+
+// isTransientError indica si err es un error de red, un rate limit o un 5xx
+func isTransientError(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable) && retryable.IsRetryable()
+}
+
+// UploadMediaReliable sube un archivo reintentando la subida multipart
+// completa ante errores transitorios (de red, rate limit o 5xx). El progreso de
+// cada intento se reporta en req.Progress. Como cada intento vuelve a leer el archivo desde
+// el principio, req.File debe implementar io.Seeker o opts.Source debe estar
+// definido; si no, falla antes de enviar nada.
+func (s *Service) UploadMediaReliable(ctx context.Context, req *UploadRequest, opts *ReliableUploadOptions) (*UploadResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
 	}
 	
-	err = writer.Close()
+	options := ReliableUploadOptions{}
+	if opts != nil {
+		options = *opts
+	}
+	options.SetDefaults()
+	
+	rewind, err := uploadSource(req, options.Source)
 	if err != nil {
-		return nil, fmt.Errorf("error closing multipart writer: %w", err)
+		return nil, err
 	}
 	
-	// Realizar petición HTTP personalizada para multipart
-	response, err := s.doMultipartRequest(ctx, "POST", "/api/v1/uploadMedia", &buf, writer.FormDataContentType())
+	attemptReq := *req
+	if err := detectRequestMediaType(&attemptReq); err != nil {
+		return nil, err
+	}
+	attemptReq.SetDefaults()
+	
+	var lastErr error
+	for attempt := 1; attempt <= options.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			timer := time.NewTimer(options.Backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, fmt.Errorf("upload retry canceled after %v: %w", lastErr, ctx.Err())
+			case <-timer.C:
+			}
+		}
+		
+		file, err := rewind()
+		if err != nil {
+			return nil, err
+		}
+		
+		attemptReq.File = file
+		
+		if options.OnAttempt != nil {
+			options.OnAttempt(attempt)
+		}
+		
+		if err := attemptReq.Validate(); err != nil {
+			return nil, fmt.Errorf("validation error: %w", err)
+		}
+		
+		response, done, err := s.uploadMultipart(ctx, &attemptReq)
+		if err == nil {
+			return response, nil
+		}
+		
+		if !isTransientError(err) {
+			return nil, err
+		}
+		lastErr = err
+		
+		// No rebobinar mientras el intento fallido siga leyendo el archivo
+		<-done
+	}
+	
+	return nil, fmt.Errorf("upload failed after %d attempts: %w", options.MaxAttempts, lastErr)
+}
+
+// uploadSource retorna una función que entrega el archivo listo para leerse
+// desde el principio en cada intento
+func uploadSource(req *UploadRequest, source func() io.Reader) (func() (io.Reader, error), error) {
+	if source != nil {
+		return func() (io.Reader, error) {
+			file := source()
+			if file == nil {
+				return nil, fmt.Errorf("upload source returned a nil reader")
+			}
+			return file, nil
+		}, nil
+	}
+	
+	if req.File == nil {
+		return nil, fmt.Errorf("validation error: file is required")
+	}
+	
+	seeker, ok := req.File.(io.Seeker)
+	if !ok {
+		return nil, fmt.Errorf("upload source cannot be re-read: File must implement io.Seeker or Source must be set")
+	}
+	
+	// Rebobinar a la posición inicial, no necesariamente al byte 0
+	start, err := seeker.Seek(0, io.SeekCurrent)
 	if err != nil {
-		return nil, fmt.Errorf("error uploading media: %w", err)
+		return nil, fmt.Errorf("upload source cannot be re-read: %w", err)
+	}
+	
+	return func() (io.Reader, error) {
+		if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("error rewinding upload source: %w", err)
+		}
+		return req.File, nil
+	}, nil
+}
+
+// progressReader reporta a UploadRequest.Progress los bytes leídos del archivo
+type progressReader struct {
+	reader   io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+// Read implementa io.Reader
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 {
+		pr.sent += int64(n)
+		pr.progress(pr.sent, pr.total)
+	}
+	return n, err
+}
+
+// readerSize retorna los bytes que quedan por leer de r si puede conocerse sin
+// leerlo
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case interface{ Size() int64 }:
+		return v.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		
+		// Descontar lo ya leído, ya que la subida empieza en la posición actual
+		size := info.Size()
+		if seeker, ok := r.(io.Seeker); ok {
+			if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				size -= offset
+			}
+		}
+		return size, true
+	}
+	
+	return 0, false
+}
+
+// UploadBatch sube varios archivos en paralelo, con hasta concurrency subidas
+// simultáneas. Cada subida sigue pasando por el rate limiter del cliente, así
+// que la concurrencia no supera los límites de WATI. El fallo de un archivo no
+// interrumpe al resto salvo que se indique StopOnError; los resultados y
+// errores se informan en el orden de reqs, con Index referido a su posición.
+//
+// Si ctx se cancela, o un archivo falla con StopOnError, las subidas en curso
+// se abortan y se retorna el resultado parcial de las completadas junto con el
+// error.
+func (s *Service) UploadBatch(ctx context.Context, reqs []*UploadRequest, concurrency int, opts ...*BatchUploadOptions) (*BatchUploadResponse, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("validation error: at least one upload request is required")
+	}
+	
+	options := &BatchUploadOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
+	}
+	
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	type uploadResult struct {
+		response *UploadResponse
+		err      error
+		done     bool
+	}
+	results := make([]uploadResult, len(reqs))
+	
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	
+dispatch:
+	for i, req := range reqs {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		
+		wg.Add(1)
+		go func(i int, req *UploadRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			
+			response, err := s.UploadMediaWithRequest(ctx, req)
+			
+			mu.Lock()
+			defer mu.Unlock()
+			
+			// Una subida abortada por la cancelación no cuenta como completada
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			
+			results[i] = uploadResult{response: response, err: err, done: true}
+			if err != nil && options.StopOnError && firstErr == nil {
+				firstErr = fmt.Errorf("error uploading file %d: %w", i, err)
+				cancel()
+			}
+		}(i, req)
+	}
+	
+	wg.Wait()
+	
+	batch := &BatchUploadResponse{}
+	for i, result := range results {
+		if !result.done {
+			continue
+		}
+		
+		var fileName string
+		if reqs[i] != nil {
+			fileName = reqs[i].FileName
+		}
+		
+		if result.err != nil {
+			batch.FailureCount++
+			batch.Errors = append(batch.Errors, BatchUploadError{
+				Index:    i,
+				FileName: fileName,
+				Error:    result.err.Error(),
+			})
+			continue
+		}
+		
+		batch.SuccessCount++
+		batch.Uploads = append(batch.Uploads, BatchUploadResult{
+			Index:    i,
+			FileName: fileName,
+			Response: result.response,
+		})
+	}
+	
+	if firstErr != nil {
+		return batch, firstErr
 	}
 	
-	return response, nil
+	if err := ctx.Err(); err != nil {
+		return batch, err
+	}
+	
+	return batch, nil
 }
 
 // DeleteMedia elimina un archivo de media
@@ -135,6 +550,52 @@ func (s *Service) GetMediaURL(ctx context.Context, fileName string) (string, err
 	return media.Media.URL, nil
 }
 
+// DownloadMedia descarga el contenido de un archivo de media y lo escribe en w
+// sin cargarlo completo en memoria. Retorna la cantidad de bytes escritos.
+func (s *Service) DownloadMedia(ctx context.Context, fileName string, w io.Writer) (int64, error) {
+	if fileName == "" {
+		return 0, fmt.Errorf("fileName is required")
+	}
+	
+	if w == nil {
+		return 0, fmt.Errorf("writer is required")
+	}
+	
+	streamer, ok := s.client.(StreamingHTTPClient)
+	if !ok {
+		return 0, fmt.Errorf("HTTP client does not support streaming requests")
+	}
+	
+	endpoint := "/api/v1/getMedia?fileName=" + url.QueryEscape(fileName)
+	
+	resp, err := streamer.DoStreamRequest(ctx, "GET", endpoint, nil, "")
+	if err != nil {
+		return 0, fmt.Errorf("error downloading media file %s: %w", fileName, err)
+	}
+	defer resp.Body.Close()
+	
+	written, err := io.Copy(w, &contextReader{ctx: ctx, r: resp.Body})
+	if err != nil {
+		return written, fmt.Errorf("error downloading media file %s: %w", fileName, err)
+	}
+	
+	return written, nil
+}
+
+// contextReader interrumpe la lectura cuando el contexto es cancelado
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read implementa io.Reader
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.r.Read(p)
+}
+
 // ListMedia obtiene una lista de archivos de media con parámetros opcionales
 func (s *Service) ListMedia(ctx context.Context, params *GetMediaParams) (*MediaListResponse, error) {
 	if params == nil {
@@ -144,16 +605,7 @@ func (s *Service) ListMedia(ctx context.Context, params *GetMediaParams) (*Media
 	params.SetDefaults()
 	
 	// Construir endpoint con query parameters
-	endpoint := "/api/v1/media"
-	queryParams := params.ToMap()
-	
-	if len(queryParams) > 0 {
-		var parts []string
-		for key, value := range queryParams {
-			parts = append(parts, fmt.Sprintf("%s=%s", key, value))
-		}
-		endpoint += "?" + strings.Join(parts, "&")
-	}
+	endpoint := common.BuildEndpoint("/api/v1/media", params.ToMap())
 	
 	var response MediaListResponse
 	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
@@ -252,29 +704,50 @@ func (s *Service) GetDocuments(ctx context.Context, params *GetMediaParams) (*Me
 	return s.GetMediaByType(ctx, MediaTypeDocument, params)
 }
 
-// SearchMedia busca archivos de media por nombre
-func (s *Service) SearchMedia(ctx context.Context, query string, params *GetMediaParams) (*MediaListResponse, error) {
-	// Esta funcionalidad dependería de si WATI soporta búsqueda por nombre
-	// Por ahora, obtenemos todos y filtramos localmente
-	response, err := s.ListMedia(ctx, params)
+// SearchMedia busca archivos de media por nombre. WATI no ofrece un endpoint de
+// búsqueda, así que se recorren todas las páginas de ListMedia (a partir de
+// params.PageNumber y con sus filtros) y se filtra localmente: el costo es una
+// petición por página, O(n) sobre el total de archivos. La respuesta reúne
+// todas las coincidencias en una sola página.
+func (s *Service) SearchMedia(ctx context.Context, query string, params *GetMediaParams, opts ...*SearchOptions) (*MediaListResponse, error) {
+	options := SearchOptions{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = *opts[0]
+	}
+	
+	pageParams := GetMediaParams{}
+	if params != nil {
+		pageParams = *params
+	}
+	pageParams.SetDefaults()
+	
+	result := &MediaListResponse{}
+	err := common.ForEachPage(ctx, pageParams.PageNumber, func(page int) (PaginatedResponse, int, error) {
+		pageParams.PageNumber = page
+		response, err := s.ListMedia(ctx, &pageParams)
+		if err != nil {
+			return PaginatedResponse{}, 0, fmt.Errorf("error searching media page %d: %w", page, err)
+		}
+		result.BaseResponse = response.BaseResponse
+		
+		for _, media := range response.Media {
+			if options.matches(media, query) {
+				result.Media = append(result.Media, media)
+			}
+		}
+		
+		return response.PaginatedResponse, len(response.Media), nil
+	})
 	if err != nil {
 		return nil, err
 	}
 	
-	var filteredMedia []MediaFile
-	queryLower := strings.ToLower(query)
+	result.Page = 1
+	result.PageSize = len(result.Media)
+	result.TotalPages = 1
+	result.TotalCount = len(result.Media)
 	
-	for _, media := range response.Media {
-		if strings.Contains(strings.ToLower(media.FileName), queryLower) ||
-		   strings.Contains(strings.ToLower(media.OriginalName), queryLower) {
-			filteredMedia = append(filteredMedia, media)
-		}
-	}
-	
-	response.Media = filteredMedia
-	response.TotalCount = len(filteredMedia)
-	
-	return response, nil
+	return result, nil
 }
 
 // ValidateUpload valida un archivo antes de subirlo
@@ -346,32 +819,22 @@ func (s *Service) WaitForMediaReady(ctx context.Context, fileName string, maxWai
 	return nil, fmt.Errorf("timeout waiting for media to be ready: %s", fileName)
 }
 
-// doMultipartRequest realiza una petición HTTP multipart personalizada
+// doMultipartRequest realiza una petición HTTP multipart en streaming
 func (s *Service) doMultipartRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*UploadResponse, error) {
-	// Esta función necesitaría acceso directo al cliente HTTP
-	// Por simplicidad, usaremos el método estándar con una estructura especial
-	
-	// Crear una estructura que represente el multipart data
-	multipartData := struct {
-		ContentType string `json:"contentType"`
-		Body        []byte `json:"body"`
-	}{
-		ContentType: contentType,
+	streamer, ok := s.client.(StreamingHTTPClient)
+	if !ok {
+		return nil, fmt.Errorf("HTTP client does not support streaming requests")
 	}
 	
-	// Leer el cuerpo
-	if body != nil {
-		bodyBytes, err := io.ReadAll(body)
-		if err != nil {
-			return nil, fmt.Errorf("error reading multipart body: %w", err)
-		}
-		multipartData.Body = bodyBytes
+	resp, err := streamer.DoStreamRequest(ctx, method, endpoint, body, contentType)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 	
 	var response UploadResponse
-	err := s.client.DoRequest(ctx, method, endpoint, multipartData, &response)
-	if err != nil {
-		return nil, err
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling upload response: %w", err)
 	}
 	
 	return &response, nil
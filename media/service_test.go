@@ -0,0 +1,623 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockHTTPClient implementa HTTPClient y StreamingHTTPClient para testing
+type MockHTTPClient struct {
+	DoRequestFunc       func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
+	DoStreamRequestFunc func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error)
+}
+
+func (m *MockHTTPClient) DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	if m.DoRequestFunc != nil {
+		return m.DoRequestFunc(ctx, method, endpoint, body, result)
+	}
+	return nil
+}
+
+func (m *MockHTTPClient) DoStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	if m.DoStreamRequestFunc != nil {
+		return m.DoStreamRequestFunc(ctx, method, endpoint, body, contentType)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+}
+
+func TestUploadRequestSetDefaults(t *testing.T) {
+	tests := []struct {
+		fileName  string
+		mediaType string
+		want      string
+	}{
+		{fileName: "photo.jpg", want: string(MediaTypeImage)},
+		{fileName: "PHOTO.JPEG", want: string(MediaTypeImage)},
+		{fileName: "invoice.pdf", want: string(MediaTypeDocument)},
+		{fileName: "archive.xyz", want: string(MediaTypeDocument)},
+		{fileName: "noextension", want: string(MediaTypeDocument)},
+		{fileName: "photo.jpg", mediaType: string(MediaTypeSticker), want: string(MediaTypeSticker)},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.fileName, func(t *testing.T) {
+			req := &UploadRequest{FileName: tt.fileName, MediaType: tt.mediaType}
+			req.SetDefaults()
+			
+			if req.MediaType != tt.want {
+				t.Errorf("Expected media type %s, got %s", tt.want, req.MediaType)
+			}
+		})
+	}
+}
+
+func TestUploadMediaInfersMediaType(t *testing.T) {
+	var mediaType string
+	mockClient := &MockHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			_, params, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				t.Fatalf("Invalid content type %s: %v", contentType, err)
+			}
+			
+			form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+			if err != nil {
+				t.Fatalf("Invalid multipart body: %v", err)
+			}
+			mediaType = form.Value["mediaType"][0]
+			
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	if _, err := service.UploadMedia(context.Background(), strings.NewReader("%PDF"), "invoice.pdf", ""); err != nil {
+		t.Fatalf("UploadMedia() error = %v", err)
+	}
+	
+	if mediaType != string(MediaTypeDocument) {
+		t.Errorf("Expected inferred media type document, got %q", mediaType)
+	}
+}
+
+// transientError simula un error de red reintentable
+type transientError struct{}
+
+func (transientError) Error() string     { return "connection reset" }
+func (transientError) IsRetryable() bool { return true }
+
+func TestUploadMediaReliableRetriesAfterMidStreamFailure(t *testing.T) {
+	content := strings.Repeat("x", 64*1024)
+	
+	attempts := 0
+	var uploaded string
+	mockClient := &MockHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			attempts++
+			if attempts == 1 {
+				// Leer parte del cuerpo y cortar la conexión
+				if _, err := io.ReadFull(body, make([]byte, 1024)); err != nil {
+					t.Fatalf("Error reading partial body: %v", err)
+				}
+				return nil, transientError{}
+			}
+			
+			_, params, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				t.Fatalf("Invalid content type %s: %v", contentType, err)
+			}
+			
+			form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+			if err != nil {
+				t.Fatalf("Invalid multipart body: %v", err)
+			}
+			
+			file, err := form.File["file"][0].Open()
+			if err != nil {
+				t.Fatalf("Error opening uploaded file: %v", err)
+			}
+			data, _ := io.ReadAll(file)
+			uploaded = string(data)
+			
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	// El progreso se reporta en UploadRequest.Progress y vuelve a cero en cada intento
+	var attempt int
+	progress := map[int]int64{}
+	totals := map[int]int64{}
+	_, err := service.UploadMediaReliable(context.Background(), &UploadRequest{
+		File:     strings.NewReader(content),
+		FileName: "manual.pdf",
+		Progress: func(bytesSent, total int64) {
+			progress[attempt] = bytesSent
+			totals[attempt] = total
+		},
+	}, &ReliableUploadOptions{
+		Backoff: time.Millisecond,
+		OnAttempt: func(n int) {
+			attempt = n
+		},
+	})
+	if err != nil {
+		t.Fatalf("UploadMediaReliable() error = %v", err)
+	}
+	
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+	
+	if uploaded != content {
+		t.Errorf("Expected the full file on retry, got %d bytes", len(uploaded))
+	}
+	
+	if progress[1] >= int64(len(content)) {
+		t.Errorf("Expected partial progress for attempt 1, got %d", progress[1])
+	}
+	
+	if progress[2] != int64(len(content)) || totals[2] != int64(len(content)) {
+		t.Errorf("Expected progress %d of %d for attempt 2, got %d of %d", len(content), len(content), progress[2], totals[2])
+	}
+}
+
+func TestUploadMediaReliableRequiresRereadableSource(t *testing.T) {
+	called := false
+	mockClient := &MockHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			called = true
+			return nil, transientError{}
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	// io.MultiReader no implementa io.Seeker
+	_, err := service.UploadMediaReliable(context.Background(), &UploadRequest{
+		File:     io.MultiReader(strings.NewReader("%PDF")),
+		FileName: "manual.pdf",
+	}, nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot be re-read") {
+		t.Errorf("Expected re-read error, got %v", err)
+	}
+	
+	if called {
+		t.Error("Expected no upload attempt without a re-readable source")
+	}
+}
+
+func TestUploadMediaReliableWithSourceGivesUp(t *testing.T) {
+	attempts := 0
+	mockClient := &MockHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			attempts++
+			return nil, transientError{}
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.UploadMediaReliable(context.Background(), &UploadRequest{FileName: "manual.pdf"}, &ReliableUploadOptions{
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+		Source:      func() io.Reader { return io.MultiReader(strings.NewReader("%PDF")) },
+	})
+	if err == nil {
+		t.Fatal("Expected error after exhausting attempts")
+	}
+	
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+// pngHeader es la firma de un archivo PNG
+const pngHeader = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func TestDetectMimeType(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "png", content: pngHeader, want: "image/png"},
+		{name: "pdf", content: "%PDF-1.7\n", want: "application/pdf"},
+		{name: "text drops charset", content: "hola mundo", want: "text/plain"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := strings.NewReader(tt.content)
+			
+			got, err := DetectMimeType(reader)
+			if err != nil {
+				t.Fatalf("DetectMimeType() error = %v", err)
+			}
+			
+			if got != tt.want {
+				t.Errorf("DetectMimeType() = %s, want %s", got, tt.want)
+			}
+			
+			if reader.Len() != len(tt.content) {
+				t.Errorf("Expected reader to be rewound, %d bytes left", reader.Len())
+			}
+		})
+	}
+}
+
+func TestUploadMediaDetectsContentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     string
+		wantErr  bool
+	}{
+		{name: "renamed pdf", fileName: "photo.jpg", content: "%PDF-1.7\n", want: string(MediaTypeDocument)},
+		{name: "no extension", fileName: "photo", content: pngHeader, want: string(MediaTypeImage)},
+		{name: "generic content uses extension", fileName: "report.docx", content: "PK\x03\x04", want: string(MediaTypeDocument)},
+		{name: "unsupported content", fileName: "page.pdf", content: "<html><body></body></html>", wantErr: true},
+		{name: "matroska detected as webm", fileName: "movie.mkv", content: "\x1A\x45\xDF\xA3\x01\x00\x00\x00", want: string(MediaTypeVideo)},
+		{name: "text detected as html", fileName: "notes.txt", content: "<html> exported notes", want: string(MediaTypeDocument)},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mediaType, uploaded string
+			mockClient := &MockHTTPClient{
+				DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+					_, params, err := mime.ParseMediaType(contentType)
+					if err != nil {
+						t.Fatalf("Invalid content type %s: %v", contentType, err)
+					}
+					
+					form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+					if err != nil {
+						t.Fatalf("Invalid multipart body: %v", err)
+					}
+					mediaType = form.Value["mediaType"][0]
+					
+					file, _ := form.File["file"][0].Open()
+					data, _ := io.ReadAll(file)
+					uploaded = string(data)
+					
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+				},
+			}
+			
+			service := NewService(mockClient)
+			
+			_, err := service.UploadMedia(context.Background(), strings.NewReader(tt.content), tt.fileName, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UploadMedia() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			
+			if mediaType != tt.want {
+				t.Errorf("Expected media type %s, got %s", tt.want, mediaType)
+			}
+			
+			if uploaded != tt.content {
+				t.Errorf("Expected the full file after sniffing, got %q", uploaded)
+			}
+		})
+	}
+}
+
+// mediaPagesClient sirve pages como páginas sucesivas de ListMedia
+func mediaPagesClient(pages [][]MediaFile, requested *[]string) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			*requested = append(*requested, endpoint)
+			
+			page := len(*requested)
+			response := result.(*MediaListResponse)
+			response.Page = page
+			response.TotalPages = len(pages)
+			response.Media = pages[page-1]
+			return nil
+		},
+	}
+}
+
+func TestSearchMediaPaginates(t *testing.T) {
+	pages := [][]MediaFile{
+		{{FileName: "logo.png"}, {FileName: "Producto-A.jpg"}},
+		{{FileName: "factura.pdf"}, {FileName: "x1.jpg", OriginalName: "producto-b.jpg"}},
+		{{FileName: "producto-c.mp4"}},
+	}
+	
+	tests := []struct {
+		name string
+		opts *SearchOptions
+		want []string
+	}{
+		{name: "default", want: []string{"Producto-A.jpg", "x1.jpg", "producto-c.mp4"}},
+		{name: "case sensitive", opts: &SearchOptions{CaseSensitive: true}, want: []string{"x1.jpg", "producto-c.mp4"}},
+		{name: "file name only", opts: &SearchOptions{ExcludeOriginalName: true}, want: []string{"Producto-A.jpg", "producto-c.mp4"}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested []string
+			service := NewService(mediaPagesClient(pages, &requested))
+			
+			response, err := service.SearchMedia(context.Background(), "producto", nil, tt.opts)
+			if err != nil {
+				t.Fatalf("SearchMedia() error = %v", err)
+			}
+			
+			if len(requested) != len(pages) {
+				t.Errorf("Expected %d page requests, got %d", len(pages), len(requested))
+			}
+			
+			if len(response.Media) != len(tt.want) || response.TotalCount != len(tt.want) {
+				t.Fatalf("Expected %v, got %+v", tt.want, response.Media)
+			}
+			for i, media := range response.Media {
+				if media.FileName != tt.want[i] {
+					t.Errorf("Expected %s at %d, got %s", tt.want[i], i, media.FileName)
+				}
+			}
+		})
+	}
+}
+
+// batchUploadClient falla las subidas de los archivos en failing y registra
+// el máximo de subidas simultáneas
+func batchUploadClient(t *testing.T, failing map[string]bool, uploaded *[]string, maxActive *int32) *MockHTTPClient {
+	var (
+		mu     sync.Mutex
+		active int32
+	)
+	
+	return &MockHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			_, params, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				t.Errorf("Invalid content type %s: %v", contentType, err)
+				return nil, err
+			}
+			
+			form, err := multipart.NewReader(body, params["boundary"]).ReadForm(1 << 20)
+			if err != nil {
+				return nil, err
+			}
+			fileName := form.File["file"][0].Filename
+			
+			mu.Lock()
+			active++
+			if active > *maxActive {
+				*maxActive = active
+			}
+			*uploaded = append(*uploaded, fileName)
+			mu.Unlock()
+			
+			time.Sleep(10 * time.Millisecond)
+			
+			mu.Lock()
+			active--
+			mu.Unlock()
+			
+			if failing[fileName] {
+				return nil, fmt.Errorf("upload rejected")
+			}
+			
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+		},
+	}
+}
+
+func TestUploadBatch(t *testing.T) {
+	var (
+		uploaded  []string
+		maxActive int32
+	)
+	service := NewService(batchUploadClient(t, map[string]bool{"b.pdf": true}, &uploaded, &maxActive))
+	
+	reqs := []*UploadRequest{
+		{File: strings.NewReader("a"), FileName: "a.pdf"},
+		{File: strings.NewReader("b"), FileName: "b.pdf"},
+		{File: strings.NewReader("c"), FileName: "c.pdf"},
+		{File: strings.NewReader("d"), FileName: "d.pdf"},
+	}
+	
+	response, err := service.UploadBatch(context.Background(), reqs, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	if len(uploaded) != 4 {
+		t.Errorf("Expected all 4 files to be uploaded, got %v", uploaded)
+	}
+	if maxActive > 2 {
+		t.Errorf("Expected at most 2 concurrent uploads, got %d", maxActive)
+	}
+	
+	if response.SuccessCount != 3 || response.FailureCount != 1 {
+		t.Errorf("Expected 3 successes and 1 failure, got %d and %d", response.SuccessCount, response.FailureCount)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Index != 1 || response.Errors[0].FileName != "b.pdf" {
+		t.Errorf("Expected failure at index 1 for b.pdf, got %+v", response.Errors)
+	}
+	
+	wantIndexes := []int{0, 2, 3}
+	if len(response.Uploads) != len(wantIndexes) {
+		t.Fatalf("Expected %d uploads, got %+v", len(wantIndexes), response.Uploads)
+	}
+	for i, upload := range response.Uploads {
+		if upload.Index != wantIndexes[i] {
+			t.Errorf("Expected upload %d to have index %d, got %d", i, wantIndexes[i], upload.Index)
+		}
+	}
+}
+
+func TestUploadBatchStopOnError(t *testing.T) {
+	var (
+		uploaded  []string
+		maxActive int32
+	)
+	service := NewService(batchUploadClient(t, map[string]bool{"b.pdf": true}, &uploaded, &maxActive))
+	
+	reqs := []*UploadRequest{
+		{File: strings.NewReader("a"), FileName: "a.pdf"},
+		{File: strings.NewReader("b"), FileName: "b.pdf"},
+		{File: strings.NewReader("c"), FileName: "c.pdf"},
+	}
+	
+	response, err := service.UploadBatch(context.Background(), reqs, 1, &BatchUploadOptions{StopOnError: true})
+	if err == nil {
+		t.Fatal("Expected error with StopOnError")
+	}
+	
+	if len(uploaded) != 2 {
+		t.Errorf("Expected upload to stop after b.pdf, got %v", uploaded)
+	}
+	if response.SuccessCount != 1 || response.FailureCount != 1 {
+		t.Errorf("Expected 1 success and 1 failure, got %d and %d", response.SuccessCount, response.FailureCount)
+	}
+}
+
+func TestUploadMediaProgress(t *testing.T) {
+	content := strings.Repeat("a", 100*1024)
+	
+	tests := []struct {
+		name      string
+		file      io.Reader
+		wantTotal int64
+	}{
+		{name: "known size", file: strings.NewReader(content), wantTotal: int64(len(content))},
+		{name: "unknown size", file: io.MultiReader(strings.NewReader(content)), wantTotal: -1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockHTTPClient{
+				DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+					io.Copy(io.Discard, body)
+					return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+				},
+			}
+			
+			var calls []int64
+			req := &UploadRequest{
+				File:     tt.file,
+				FileName: "manual.pdf",
+				Progress: func(bytesSent, total int64) {
+					if total != tt.wantTotal {
+						t.Errorf("Expected total %d, got %d", tt.wantTotal, total)
+					}
+					calls = append(calls, bytesSent)
+				},
+			}
+			
+			service := NewService(mockClient)
+			if _, err := service.UploadMediaWithRequest(context.Background(), req); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			
+			if len(calls) < 2 {
+				t.Fatalf("Expected several progress calls, got %d", len(calls))
+			}
+			for i := 1; i < len(calls); i++ {
+				if calls[i] <= calls[i-1] {
+					t.Errorf("Expected increasing progress, got %v", calls)
+					break
+				}
+			}
+			if last := calls[len(calls)-1]; last != int64(len(content)) {
+				t.Errorf("Expected final progress %d, got %d", len(content), last)
+			}
+		})
+	}
+}
+
+// assertJSONRoundTrip verifica que value se codifica y decodifica sin cambios
+func assertJSONRoundTrip[T any](t *testing.T, value T) {
+	t.Helper()
+	
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	
+	var decoded T
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	
+	if !reflect.DeepEqual(value, decoded) {
+		t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", decoded, value)
+	}
+}
+
+func TestMediaFileTimestampFormats(t *testing.T) {
+	want := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	
+	tests := []struct {
+		name      string
+		createdAt string
+		want      time.Time
+	}{
+		{name: "RFC3339", createdAt: `"2024-01-15T10:30:00Z"`, want: want},
+		{name: "RFC3339 with offset", createdAt: `"2024-01-15T07:30:00-03:00"`, want: want},
+		{name: "without zone", createdAt: `"2024-01-15T10:30:00"`, want: want},
+		{name: "with fraction", createdAt: `"2024-01-15T10:30:00.000"`, want: want},
+		{name: "space separated", createdAt: `"2024-01-15 10:30:00"`, want: want},
+		{name: "epoch seconds", createdAt: `1705314600`, want: want},
+		{name: "epoch milliseconds", createdAt: `1705314600000`, want: want},
+		{name: "epoch as text", createdAt: `"1705314600"`, want: want},
+		{name: "null", createdAt: `null`},
+		{name: "empty", createdAt: `""`},
+		{name: "unknown format", createdAt: `"15/01/2024"`},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := `{"id":"m1","fileName":"a.jpg","createdAt":` + tt.createdAt + `,"status":"READY"}`
+			
+			var file MediaFile
+			if err := json.Unmarshal([]byte(data), &file); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			
+			if !file.CreatedAt.Equal(tt.want) {
+				t.Errorf("Expected createdAt %v, got %v", tt.want, file.CreatedAt)
+			}
+			if file.ID != "m1" || file.FileName != "a.jpg" || file.Status != "READY" {
+				t.Errorf("Expected other fields to be decoded, got %+v", file)
+			}
+		})
+	}
+}
+
+func TestMediaFileJSONRoundTrip(t *testing.T) {
+	assertJSONRoundTrip(t, MediaFile{
+		ID:        "m1",
+		FileName:  "a.jpg",
+		MimeType:  "image/jpeg",
+		Size:      1024,
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 1, 16, 8, 0, 0, 0, time.UTC),
+		Status:    "READY",
+	})
+	
+	assertJSONRoundTrip(t, MediaListResponse{
+		Media: []MediaFile{{ID: "m1", FileName: "a.jpg"}},
+	})
+}
@@ -1,9 +1,15 @@
 package media
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
 )
 
 // MediaFile representa un archivo de media en WATI
@@ -25,6 +31,67 @@ type MediaFile struct {
 	UploadedBy  string    `json:"uploadedBy,omitempty"`
 }
 
+// mediaTimeLayouts son los formatos de fecha que WATI usa en los archivos de
+// media; sin zona horaria se asume UTC
+var mediaTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05",
+}
+
+// UnmarshalJSON decodifica MediaFile aceptando CreatedAt y UpdatedAt en
+// RFC3339, sin zona horaria o como epoch Unix (en segundos o milisegundos,
+// como número o texto). Una fecha en un formato desconocido queda en cero en
+// lugar de hacer fallar toda la respuesta.
+func (m *MediaFile) UnmarshalJSON(data []byte) error {
+	type mediaFileAlias MediaFile
+	aux := struct {
+		*mediaFileAlias
+		CreatedAt json.RawMessage `json:"createdAt"`
+		UpdatedAt json.RawMessage `json:"updatedAt"`
+	}{mediaFileAlias: (*mediaFileAlias)(m)}
+	
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	
+	m.CreatedAt = parseMediaTime(aux.CreatedAt)
+	m.UpdatedAt = parseMediaTime(aux.UpdatedAt)
+	
+	return nil
+}
+
+// parseMediaTime interpreta una fecha de media en cualquiera de los formatos
+// soportados, o retorna la fecha cero
+func parseMediaTime(raw json.RawMessage) time.Time {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		// No es texto: puede ser un epoch numérico
+		value = string(raw)
+	}
+	value = strings.TrimSpace(value)
+	
+	if value == "" || value == "null" {
+		return time.Time{}
+	}
+	
+	if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// Los epochs en milisegundos tienen 13 dígitos a partir de 2001
+		if epoch > 1e12 || epoch < -1e12 {
+			return time.UnixMilli(epoch).UTC()
+		}
+		return time.Unix(epoch, 0).UTC()
+	}
+	
+	for _, layout := range mediaTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	
+	return time.Time{}
+}
+
 // MediaResponse representa la respuesta de obtener media
 type MediaResponse struct {
 	BaseResponse
@@ -52,6 +119,74 @@ type UploadRequest struct {
 	MediaType   string    `json:"mediaType"`
 	Caption     string    `json:"caption,omitempty"`
 	Description string    `json:"description,omitempty"`
+	
+	// Progress, si se indica, se llama a medida que se envía el archivo
+	Progress ProgressFunc `json:"-"`
+}
+
+// ProgressFunc recibe los bytes del archivo enviados hasta el momento y el
+// tamaño total, o -1 si no puede conocerse sin leer el archivo. Se llama desde
+// la goroutine que escribe el cuerpo de la petición.
+type ProgressFunc func(bytesSent, total int64)
+
+// ReliableUploadOptions configura UploadMediaReliable
+type ReliableUploadOptions struct {
+	// MaxAttempts es el número total de intentos (por defecto 3)
+	MaxAttempts int
+	// Backoff es la espera entre intentos (por defecto 1s)
+	Backoff time.Duration
+	// OnAttempt, si se indica, se llama al comenzar cada intento (desde 1). El
+	// progreso se sigue reportando en UploadRequest.Progress y vuelve a cero
+	// en cada intento.
+	OnAttempt func(attempt int)
+	// Source crea un lector nuevo del archivo para cada intento. Si es nil,
+	// UploadRequest.File debe implementar io.Seeker para poder rebobinarlo.
+	Source func() io.Reader
+}
+
+// BatchUploadOptions configura UploadBatch
+type BatchUploadOptions struct {
+	// StopOnError aborta las subidas pendientes ante el primer fallo
+	StopOnError bool
+}
+
+// BatchUploadResponse representa el resultado de una subida múltiple
+type BatchUploadResponse struct {
+	SuccessCount int                 `json:"successCount"`
+	FailureCount int                 `json:"failureCount"`
+	Uploads      []BatchUploadResult `json:"uploads"`
+	Errors       []BatchUploadError  `json:"errors,omitempty"`
+}
+
+// BatchUploadResult describe un archivo subido en una subida múltiple; Index
+// es su posición en la lista de peticiones
+type BatchUploadResult struct {
+	Index    int             `json:"index"`
+	FileName string          `json:"fileName"`
+	Response *UploadResponse `json:"response"`
+}
+
+// BatchUploadError describe un archivo que falló en una subida múltiple;
+// Index es su posición en la lista de peticiones
+type BatchUploadError struct {
+	Index    int    `json:"index"`
+	FileName string `json:"fileName"`
+	Error    string `json:"error"`
+}
+
+// SuccessRate retorna la proporción (entre 0 y 1) de archivos subidos con éxito
+func (r *BatchUploadResponse) SuccessRate() float64 {
+	total := r.SuccessCount + r.FailureCount
+	if total == 0 {
+		return 0
+	}
+	
+	return float64(r.SuccessCount) / float64(total)
+}
+
+// HasFailures indica si algún archivo de la subida múltiple falló
+func (r *BatchUploadResponse) HasFailures() bool {
+	return r.FailureCount > 0 || len(r.Errors) > 0
 }
 
 // MediaFilter representa filtros para búsqueda de media
@@ -73,6 +208,13 @@ type GetMediaParams struct {
 	Status     string `json:"status,omitempty"`
 }
 
+// SearchOptions configura SearchMedia. Por defecto la búsqueda no distingue
+// mayúsculas y compara tanto FileName como OriginalName.
+type SearchOptions struct {
+	CaseSensitive       bool `json:"caseSensitive,omitempty"`
+	ExcludeOriginalName bool `json:"excludeOriginalName,omitempty"`
+}
+
 // MediaStats representa estadísticas de media
 type MediaStats struct {
 	TotalFiles    int   `json:"totalFiles"`
@@ -98,12 +240,13 @@ type BaseResponse struct {
 }
 
 // PaginatedResponse representa una respuesta paginada
-type PaginatedResponse struct {
-	Page       int `json:"page"`
-	PageSize   int `json:"pageSize"`
-	TotalPages int `json:"totalPages"`
-	TotalCount int `json:"totalCount"`
-}
+type PaginatedResponse = common.PaginatedResponse
+
+// Valores por defecto de UploadMediaReliable
+const (
+	DefaultUploadAttempts = 3
+	DefaultUploadBackoff  = time.Second
+)
 
 // MediaType representa los tipos de media soportados
 type MediaType string
@@ -172,6 +315,26 @@ var MaxFileSizes = map[MediaType]int64{
 	MediaTypeSticker:  500 * 1024,        // 500KB
 }
 
+// SetDefaults infiere MediaType a partir de la extensión de FileName cuando no
+// se especificó. Las extensiones desconocidas se suben como documento.
+func (r *UploadRequest) SetDefaults() {
+	if r.MediaType == "" && r.FileName != "" {
+		ext := filepath.Ext(r.FileName)
+		r.MediaType = string(GetMediaTypeFromMimeType(GetMimeTypeFromExtension(ext)))
+	}
+}
+
+// SetDefaults establece valores por defecto para ReliableUploadOptions
+func (o *ReliableUploadOptions) SetDefaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultUploadAttempts
+	}
+	
+	if o.Backoff <= 0 {
+		o.Backoff = DefaultUploadBackoff
+	}
+}
+
 // Validate valida la petición de subida
 func (r *UploadRequest) Validate() error {
 	if r.File == nil {
@@ -195,6 +358,30 @@ func (r *UploadRequest) Validate() error {
 	return nil
 }
 
+// matches indica si el nombre del archivo contiene query según las opciones
+func (o *SearchOptions) matches(media MediaFile, query string) bool {
+	names := []string{media.FileName}
+	if !o.ExcludeOriginalName {
+		names = append(names, media.OriginalName)
+	}
+	
+	if !o.CaseSensitive {
+		query = strings.ToLower(query)
+	}
+	
+	for _, name := range names {
+		if !o.CaseSensitive {
+			name = strings.ToLower(name)
+		}
+		
+		if strings.Contains(name, query) {
+			return true
+		}
+	}
+	
+	return false
+}
+
 // IsValidMediaType verifica si un tipo de media es válido
 func IsValidMediaType(mediaType MediaType) bool {
 	_, exists := SupportedMimeTypes[mediaType]
@@ -0,0 +1,213 @@
+package messages
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// templatePlaceholder reconoce los parámetros posicionales {{1}}, {{2}}, ...
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\d+)\s*\}\}`)
+
+// TemplateMessageBuilder construye un SendTemplateMessageRequest con métodos
+// encadenables. Cada método valida su argumento y el primer error se retorna
+// en Build, de modo que los errores aparecen antes de llamar a WATI:
+//
+//	req, err := messages.NewTemplateMessageBuilder().
+//		To("5491112345678").
+//		Template("order_update").
+//		Broadcast("orders").
+//		BodyParam("Juan").
+//		BodyParam("#1234").
+//		ButtonURLParam(0, "1234").
+//		Build()
+type TemplateMessageBuilder struct {
+	req      SendTemplateMessageRequest
+	buttons  map[int]string
+	template *Template
+	err      error
+}
+
+// NewTemplateMessageBuilder crea un builder vacío
+func NewTemplateMessageBuilder() *TemplateMessageBuilder {
+	return &TemplateMessageBuilder{
+		buttons: make(map[int]string),
+	}
+}
+
+// To establece el número de WhatsApp del destinatario
+func (b *TemplateMessageBuilder) To(phone string) *TemplateMessageBuilder {
+	b.req.WhatsappNumber = phone
+	return b
+}
+
+// Template establece el nombre de la plantilla
+func (b *TemplateMessageBuilder) Template(name string) *TemplateMessageBuilder {
+	b.req.TemplateName = name
+	return b
+}
+
+// ForTemplate establece la plantilla a partir de su definición, lo que permite
+// a Build verificar que se completaron todos sus parámetros
+func (b *TemplateMessageBuilder) ForTemplate(template *Template) *TemplateMessageBuilder {
+	if template == nil {
+		return b.fail(fmt.Errorf("template is required"))
+	}
+	
+	b.template = template
+	b.req.TemplateName = template.Name
+	return b
+}
+
+// Broadcast establece el nombre del broadcast
+func (b *TemplateMessageBuilder) Broadcast(name string) *TemplateMessageBuilder {
+	b.req.BroadcastName = name
+	return b
+}
+
+// Channel establece el número emisor en cuentas con varios canales
+func (b *TemplateMessageBuilder) Channel(channelNumber string) *TemplateMessageBuilder {
+	b.req.ChannelNumber = channelNumber
+	return b
+}
+
+// HeaderParam agrega el siguiente parámetro posicional del header
+func (b *TemplateMessageBuilder) HeaderParam(value string) *TemplateMessageBuilder {
+	position := len(b.req.HeaderParameters) + 1
+	if strings.TrimSpace(value) == "" {
+		return b.fail(fmt.Errorf("header parameter %d is empty", position))
+	}
+	
+	b.req.HeaderParameters = append(b.req.HeaderParameters, Parameter{
+		Name:  strconv.Itoa(position),
+		Value: value,
+	})
+	return b
+}
+
+// BodyParam agrega el siguiente parámetro posicional del cuerpo ({{1}}, {{2}}, ...)
+func (b *TemplateMessageBuilder) BodyParam(value string) *TemplateMessageBuilder {
+	position := len(b.req.Parameters) + 1
+	if strings.TrimSpace(value) == "" {
+		return b.fail(fmt.Errorf("body parameter %d is empty", position))
+	}
+	
+	b.req.Parameters = append(b.req.Parameters, Parameter{
+		Name:  strconv.Itoa(position),
+		Value: value,
+	})
+	return b
+}
+
+// ButtonURLParam establece el sufijo dinámico de la URL del botón index (base 0)
+func (b *TemplateMessageBuilder) ButtonURLParam(index int, value string) *TemplateMessageBuilder {
+	if index < 0 {
+		return b.fail(fmt.Errorf("button index must be non-negative, got %d", index))
+	}
+	
+	if strings.TrimSpace(value) == "" {
+		return b.fail(fmt.Errorf("URL parameter for button %d is empty", index))
+	}
+	
+	if _, exists := b.buttons[index]; exists {
+		return b.fail(fmt.Errorf("URL parameter for button %d already set", index))
+	}
+	
+	b.buttons[index] = value
+	return b
+}
+
+// Build valida y retorna la petición. Con ForTemplate verifica además que la
+// cantidad de parámetros coincida con los placeholders de la plantilla.
+func (b *TemplateMessageBuilder) Build() (*SendTemplateMessageRequest, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	
+	req := b.req
+	req.Parameters = append([]Parameter(nil), b.req.Parameters...)
+	req.HeaderParameters = append([]Parameter(nil), b.req.HeaderParameters...)
+	req.ButtonParameters = nil
+	
+	indexes := make([]int, 0, len(b.buttons))
+	for index := range b.buttons {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		req.ButtonParameters = append(req.ButtonParameters, ButtonParameter{
+			Index: index,
+			Value: b.buttons[index],
+		})
+	}
+	
+	if b.template != nil {
+		if err := b.checkTemplate(&req); err != nil {
+			return nil, err
+		}
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	
+	return &req, nil
+}
+
+// fail registra el primer error de construcción
+func (b *TemplateMessageBuilder) fail(err error) *TemplateMessageBuilder {
+	if b.err == nil {
+		b.err = err
+	}
+	return b
+}
+
+// checkTemplate compara los parámetros de req con los placeholders de la plantilla
+func (b *TemplateMessageBuilder) checkTemplate(req *SendTemplateMessageRequest) error {
+	buttonCount := 0
+	for _, component := range b.template.Components {
+		switch strings.ToUpper(component.Type) {
+		case "HEADER":
+			if want := countPlaceholders(component.Text); len(req.HeaderParameters) != want {
+				return fmt.Errorf("template %s expects %d header parameters, got %d", b.template.Name, want, len(req.HeaderParameters))
+			}
+		case "BODY":
+			if want := countPlaceholders(component.Text); len(req.Parameters) != want {
+				return fmt.Errorf("template %s expects %d body parameters, got %d", b.template.Name, want, len(req.Parameters))
+			}
+		case "BUTTONS":
+			buttonCount = len(component.Buttons)
+			for i, button := range component.Buttons {
+				_, set := b.buttons[i]
+				dynamic := countPlaceholders(button.URL) > 0
+				if dynamic && !set {
+					return fmt.Errorf("template %s expects a URL parameter for button %d", b.template.Name, i)
+				}
+				if !dynamic && set {
+					return fmt.Errorf("template %s button %d has no URL parameter", b.template.Name, i)
+				}
+			}
+		}
+	}
+	
+	for _, button := range req.ButtonParameters {
+		if button.Index >= buttonCount {
+			return fmt.Errorf("template %s has no button %d", b.template.Name, button.Index)
+		}
+	}
+	
+	return nil
+}
+
+// countPlaceholders retorna el mayor placeholder posicional de text
+func countPlaceholders(text string) int {
+	count := 0
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+		if n, err := strconv.Atoi(match[1]); err == nil && n > count {
+			count = n
+		}
+	}
+	return count
+}
@@ -0,0 +1,108 @@
+package messages
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTemplateMessageBuilder(t *testing.T) {
+	req, err := NewTemplateMessageBuilder().
+		To("+54 9 11 1234-5678").
+		Template("order_update").
+		Broadcast("orders").
+		HeaderParam("Pedido #1234").
+		BodyParam("Juan").
+		BodyParam("#1234").
+		ButtonURLParam(0, "1234").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	
+	if req.WhatsappNumber != "5491112345678" || req.TemplateName != "order_update" || req.BroadcastName != "orders" {
+		t.Errorf("Unexpected request: %+v", req)
+	}
+	
+	payload, _ := json.Marshal(req)
+	for _, want := range []string{
+		`"parameters":[{"name":"1","value":"Juan"},{"name":"2","value":"#1234"}]`,
+		`"header_parameters":[{"name":"1","value":"Pedido #1234"}]`,
+		`"button_parameters":[{"index":0,"value":"1234"}]`,
+	} {
+		if !strings.Contains(string(payload), want) {
+			t.Errorf("Expected payload to contain %s, got %s", want, payload)
+		}
+	}
+}
+
+func TestTemplateMessageBuilderErrors(t *testing.T) {
+	base := func() *TemplateMessageBuilder {
+		return NewTemplateMessageBuilder().To("1234567890").Template("order_update").Broadcast("orders")
+	}
+	
+	tests := []struct {
+		name    string
+		builder *TemplateMessageBuilder
+		wantErr string
+	}{
+		{name: "missing template", builder: NewTemplateMessageBuilder().To("1234567890").Broadcast("orders"), wantErr: "template_name"},
+		{name: "invalid phone", builder: base().To("abc"), wantErr: "whatsappNumber"},
+		{name: "empty body param", builder: base().BodyParam("Juan").BodyParam(""), wantErr: "body parameter 2 is empty"},
+		{name: "empty header param", builder: base().HeaderParam(" "), wantErr: "header parameter 1 is empty"},
+		{name: "negative button index", builder: base().ButtonURLParam(-1, "x"), wantErr: "non-negative"},
+		{name: "duplicate button", builder: base().ButtonURLParam(0, "a").ButtonURLParam(0, "b"), wantErr: "already set"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestTemplateMessageBuilderForTemplate(t *testing.T) {
+	template := &Template{
+		Name: "order_update",
+		Components: []TemplateComponent{
+			{Type: "HEADER", Format: "TEXT", Text: "Pedido {{1}}"},
+			{Type: "BODY", Text: "Hola {{1}}, tu pedido {{2}} está en camino."},
+			{Type: "BUTTONS", Buttons: []TemplateButton{
+				{Type: "URL", Text: "Seguir", URL: "https://ejemplo.com/pedidos/{{1}}"},
+				{Type: "QUICK_REPLY", Text: "Ayuda"},
+			}},
+		},
+	}
+	
+	base := func() *TemplateMessageBuilder {
+		return NewTemplateMessageBuilder().To("1234567890").ForTemplate(template).Broadcast("orders").HeaderParam("#1234")
+	}
+	
+	if _, err := base().BodyParam("Juan").BodyParam("#1234").ButtonURLParam(0, "1234").Build(); err != nil {
+		t.Errorf("Expected complete parameters to build, got %v", err)
+	}
+	
+	tests := []struct {
+		name    string
+		builder *TemplateMessageBuilder
+		wantErr string
+	}{
+		{name: "missing body param", builder: base().BodyParam("Juan").ButtonURLParam(0, "1234"), wantErr: "expects 2 body parameters, got 1"},
+		{name: "missing header param", builder: NewTemplateMessageBuilder().To("1234567890").ForTemplate(template).Broadcast("orders"), wantErr: "expects 1 header parameters"},
+		{name: "missing button param", builder: base().BodyParam("Juan").BodyParam("#1234"), wantErr: "URL parameter for button 0"},
+		{name: "static button param", builder: base().BodyParam("Juan").BodyParam("#1234").ButtonURLParam(0, "1234").ButtonURLParam(1, "x"), wantErr: "button 1 has no URL parameter"},
+		{name: "unknown button", builder: base().BodyParam("Juan").BodyParam("#1234").ButtonURLParam(0, "1234").ButtonURLParam(5, "x"), wantErr: "has no button 5"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -2,8 +2,23 @@ package messages
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
+	"github.com/diogenes-moreira/wati-sdk/contacts"
+	"github.com/diogenes-moreira/wati-sdk/media"
 )
 
 // HTTPClient define la interfaz para realizar peticiones HTTP
@@ -11,16 +26,67 @@ type HTTPClient interface {
 	DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
 }
 
+// ContactLookup obtiene contactos por teléfono; lo implementa contacts.Service
+type ContactLookup interface {
+	GetContactByPhone(ctx context.Context, phone string) (*contacts.Contact, error)
+}
+
+// QuotaChecker informa cuántos mensajes iniciados por el negocio quedan
+// disponibles en la cuenta. Un valor negativo indica que no hay un límite
+// conocido.
+type QuotaChecker interface {
+	RemainingQuota(ctx context.Context) (int, error)
+}
+
+// QuotaConsumer lo implementan los QuotaChecker que cachean la cuota, para
+// descontar los mensajes enviados y que envíos consecutivos no superen juntos
+// la cuota cacheada
+type QuotaConsumer interface {
+	ConsumeQuota(n int)
+}
+
+// ErrQuotaExceeded indica que un envío masivo se bloqueó antes de empezar porque
+// la cuota restante no alcanza para todos los destinatarios
+var ErrQuotaExceeded = errors.New("messaging quota exceeded")
+
 // Service implementa MessagesService
 type Service struct {
-	client HTTPClient
+	client   HTTPClient
+	contacts ContactLookup
+	quota    QuotaChecker
+}
+
+// Option configura el servicio de mensajes
+type Option func(*Service)
+
+// WithContactLookup establece el servicio de contactos usado para filtrar
+// destinatarios con SkipOptedOut
+func WithContactLookup(lookup ContactLookup) Option {
+	return func(s *Service) {
+		s.contacts = lookup
+	}
+}
+
+// WithQuotaChecker habilita la verificación de cuota previa a los envíos
+// masivos: si la cuota restante es menor que la cantidad de destinatarios se
+// retorna ErrQuotaExceeded sin enviar ningún mensaje
+func WithQuotaChecker(checker QuotaChecker) Option {
+	return func(s *Service) {
+		s.quota = checker
+	}
 }
 
 // NewService crea una nueva instancia del servicio de mensajes
-func NewService(client HTTPClient) *Service {
-	return &Service{
+func NewService(client HTTPClient, options ...Option) *Service {
+	service := &Service{
 		client: client,
 	}
+	
+	for _, option := range options {
+		option(service)
+	}
+	
+	return service
 }
 
 // SendTemplateMessage envía un mensaje de plantilla a un contacto
@@ -42,6 +108,62 @@ func (s *Service) SendTemplateMessage(ctx context.Context, req *SendTemplateMess
 	return &response, nil
 }
 
+// SendResultFunc recibe el resultado final de un envío diferido
+type SendResultFunc func(response *MessageResponse, err error)
+
+// retryableError lo implementa *wati.WATIError; se declara aquí para no
+// depender del paquete raíz
+type retryableError interface {
+	IsRetryable() bool
+}
+
+// retryAfterError lo implementa *wati.WATIError cuando la API indica Retry-After
+type retryAfterError interface {
+	GetRetryAfter() time.Duration
+}
+
+// isTransientError indica si err es un rate limit o un error 5xx de la API
+func isTransientError(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable) && retryable.IsRetryable()
+}
+
+// SendTemplateMessageWithRetryLater envía un mensaje de plantilla y, si falla
+// con un error transitorio (rate limit o 5xx) después de los reintentos del
+// cliente, programa un único reintento en una goroutine pasados retryAfter (o
+// el Retry-After de la API, si es mayor). callback recibe siempre el resultado
+// final exactamente una vez: de inmediato si no hace falta reintentar, o desde
+// la goroutine tras el reintento. Cancelar ctx descarta el reintento programado
+// y callback recibe el error del contexto.
+func (s *Service) SendTemplateMessageWithRetryLater(ctx context.Context, req *SendTemplateMessageRequest, retryAfter time.Duration, callback SendResultFunc) {
+	if callback == nil {
+		callback = func(*MessageResponse, error) {}
+	}
+	
+	response, err := s.SendTemplateMessage(ctx, req)
+	if err == nil || !isTransientError(err) {
+		callback(response, err)
+		return
+	}
+	
+	var hint retryAfterError
+	if errors.As(err, &hint) && hint.GetRetryAfter() > retryAfter {
+		retryAfter = hint.GetRetryAfter()
+	}
+	
+	go func() {
+		timer := time.NewTimer(retryAfter)
+		defer timer.Stop()
+		
+		select {
+		case <-ctx.Done():
+			callback(nil, fmt.Errorf("scheduled retry canceled after %v: %w", err, ctx.Err()))
+		case <-timer.C:
+			callback(s.SendTemplateMessage(ctx, req))
+		}
+	}()
+}
+
 // SendTemplateMessages envía mensajes de plantilla a múltiples contactos
 func (s *Service) SendTemplateMessages(ctx context.Context, req *SendTemplateMessagesRequest) (*BulkMessageResponse, error) {
 	if req == nil {
@@ -52,15 +174,486 @@ func (s *Service) SendTemplateMessages(ctx context.Context, req *SendTemplateMes
 		return nil, fmt.Errorf("validation error: %w", err)
 	}
 	
+	var skipped []TemplateMessageRecipient
+	var positions []int
+	if req.SkipOptedOut {
+		filtered, excluded, kept, err := s.filterOptedOut(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		
+		// Si todos los destinatarios están excluidos no hay nada que enviar
+		if len(filtered.Recipients) == 0 {
+			response := &BulkMessageResponse{
+				SkippedCount:      len(excluded),
+				SkippedRecipients: excluded,
+			}
+			response.Result = true
+			return response, nil
+		}
+		
+		req, skipped, positions = filtered, excluded, kept
+	}
+	
+	if err := s.checkQuota(ctx, len(req.Recipients)); err != nil {
+		return nil, err
+	}
+	
+	response, err := s.sendTemplateBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	
+	response.restorePositions(skipped, positions)
+	return response, nil
+}
+
+// sendTemplateBatch envía un lote ya validado y filtrado, sin verificar la
+// cuota, y la descuenta del QuotaChecker si este lo admite
+func (s *Service) sendTemplateBatch(ctx context.Context, req *SendTemplateMessagesRequest) (*BulkMessageResponse, error) {
 	var response BulkMessageResponse
 	err := s.client.DoRequest(ctx, "POST", "/api/v1/sendTemplateMessages", req, &response)
 	if err != nil {
 		return nil, fmt.Errorf("error sending template messages: %w", err)
 	}
 	
+	if consumer, ok := s.quota.(QuotaConsumer); ok {
+		consumer.ConsumeQuota(len(req.Recipients))
+	}
+	
+	return &response, nil
+}
+
+// restorePositions traduce los índices de Errors, referidos a los
+// destinatarios enviados, a su posición en la petición original y registra los
+// destinatarios excluidos. Con positions nil los índices no cambian.
+func (r *BulkMessageResponse) restorePositions(skipped []TemplateMessageRecipient, positions []int) {
+	if positions != nil {
+		for i, sendError := range r.Errors {
+			if sendError.Index >= 0 && sendError.Index < len(positions) {
+				r.Errors[i].Index = positions[sendError.Index]
+			}
+		}
+	}
+	
+	r.SkippedCount = len(skipped)
+	r.SkippedRecipients = skipped
+}
+
+// prepareBatches valida req y lo divide en lotes de MaxRecipientsPerRequest.
+// Con SkipOptedOut los destinatarios dados de baja se excluyen antes de
+// dividir, de modo que la cuota se verifica una sola vez y solo con los
+// destinatarios que se envían. Retorna también los excluidos y la posición
+// original de cada destinatario enviado, para restorePositions.
+func (s *Service) prepareBatches(ctx context.Context, req *SendTemplateMessagesRequest) ([]*SendTemplateMessagesRequest, []TemplateMessageRecipient, []int, error) {
+	batches, err := splitTemplateMessages(req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	
+	recipients := len(req.Recipients)
+	var skipped []TemplateMessageRecipient
+	var positions []int
+	if req.SkipOptedOut {
+		filtered, excluded, kept, err := s.filterOptedOut(ctx, req)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		filtered.SkipOptedOut = false
+		
+		batches = nil
+		if len(filtered.Recipients) > 0 {
+			if batches, err = splitTemplateMessages(filtered); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		recipients, skipped, positions = len(filtered.Recipients), excluded, kept
+	}
+	
+	if err := s.checkQuota(ctx, recipients); err != nil {
+		return nil, nil, nil, err
+	}
+	
+	return batches, skipped, positions, nil
+}
+
+// checkQuota verifica que la cuota restante alcance para recipients mensajes.
+// Sin QuotaChecker configurado no se verifica nada.
+func (s *Service) checkQuota(ctx context.Context, recipients int) error {
+	if s.quota == nil {
+		return nil
+	}
+	
+	remaining, err := s.quota.RemainingQuota(ctx)
+	if err != nil {
+		return fmt.Errorf("error checking messaging quota: %w", err)
+	}
+	
+	if remaining >= 0 && remaining < recipients {
+		return fmt.Errorf("%w: %d recipients, %d messages remaining", ErrQuotaExceeded, recipients, remaining)
+	}
+	
+	return nil
+}
+
+// filterOptedOut retorna una copia de req sin los destinatarios cuyo contacto
+// no admite broadcasts, los destinatarios excluidos y la posición original de
+// cada destinatario conservado. Los números que todavía no son contactos se
+// conservan, ya que no registran una baja.
+func (s *Service) filterOptedOut(ctx context.Context, req *SendTemplateMessagesRequest) (*SendTemplateMessagesRequest, []TemplateMessageRecipient, []int, error) {
+	if s.contacts == nil {
+		return nil, nil, nil, fmt.Errorf("SkipOptedOut requires a contacts service, see WithContactLookup")
+	}
+	
+	filtered := *req
+	filtered.Recipients = make([]TemplateMessageRecipient, 0, len(req.Recipients))
+	positions := make([]int, 0, len(req.Recipients))
+	
+	var skipped []TemplateMessageRecipient
+	for i, recipient := range req.Recipients {
+		contact, err := s.contacts.GetContactByPhone(ctx, recipient.WhatsappNumber)
+		if err != nil && !errors.Is(err, contacts.ErrContactNotFound) {
+			return nil, nil, nil, fmt.Errorf("error checking opt-in for recipient %d: %w", i, err)
+		}
+		
+		if err == nil && !contact.AllowBroadcast {
+			skipped = append(skipped, recipient)
+			continue
+		}
+		
+		filtered.Recipients = append(filtered.Recipients, recipient)
+		positions = append(positions, i)
+	}
+	
+	return &filtered, skipped, positions, nil
+}
+
+// SendTemplateMessagesBatched envía mensajes de plantilla a cualquier cantidad
+// de destinatarios dividiéndolos en lotes de MaxRecipientsPerRequest que se
+// envían en secuencia, respetando el rate limit del cliente. Los resultados se
+// combinan en una sola respuesta con los índices de Errors referidos a
+// req.Recipients.
+//
+// Si un lote falla por completo y req.StopOnBatchError es true se retorna el
+// resultado parcial junto con el error; si no, los destinatarios del lote se
+// informan en Errors y se continúa con el siguiente.
+func (s *Service) SendTemplateMessagesBatched(ctx context.Context, req *SendTemplateMessagesRequest) (*BulkMessageResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	batches, skipped, positions, err := s.prepareBatches(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	
+	merged := &BulkMessageResponse{}
+	merged.Result = true
+	// También los resultados parciales se refieren a la petición original
+	defer merged.restorePositions(skipped, positions)
+	
+	for i, batch := range batches {
+		if err := ctx.Err(); err != nil {
+			return merged, err
+		}
+		
+		response, err := s.sendTemplateBatch(ctx, batch)
+		if err != nil && req.StopOnBatchError {
+			return merged, fmt.Errorf("error sending batch %d: %w", i, err)
+		}
+		
+		merged.merge(i*MaxRecipientsPerRequest, batch.Recipients, response, err)
+	}
+	
+	return merged, nil
+}
+
+// SendTemplateMessagesConcurrent es como SendTemplateMessagesBatched pero
+// envía hasta concurrency lotes en paralelo. Cada petición sigue pasando por el
+// rate limiter del cliente, así que la concurrencia no supera los límites de
+// WATI. Los resultados se combinan en el orden de los lotes, con los índices de
+// Errors referidos a req.Recipients.
+//
+// Si ctx se cancela, o un lote falla con req.StopOnBatchError, los lotes en
+// curso se abortan y se retorna el resultado parcial de los lotes completados
+// junto con el error.
+func (s *Service) SendTemplateMessagesConcurrent(ctx context.Context, req *SendTemplateMessagesRequest, concurrency int) (*BulkMessageResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	
+	batches, skipped, positions, err := s.prepareBatches(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	type batchResult struct {
+		response *BulkMessageResponse
+		err      error
+		done     bool
+	}
+	results := make([]batchResult, len(batches))
+	
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	
+dispatch:
+	for i, batch := range batches {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		
+		wg.Add(1)
+		go func(i int, batch *SendTemplateMessagesRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			
+			response, err := s.sendTemplateBatch(ctx, batch)
+			
+			mu.Lock()
+			defer mu.Unlock()
+			
+			// Un lote abortado por la cancelación no cuenta como completado
+			if err != nil && ctx.Err() != nil {
+				return
+			}
+			
+			results[i] = batchResult{response: response, err: err, done: true}
+			if err != nil && req.StopOnBatchError && firstErr == nil {
+				firstErr = fmt.Errorf("error sending batch %d: %w", i, err)
+				cancel()
+			}
+		}(i, batch)
+	}
+	
+	wg.Wait()
+	
+	merged := &BulkMessageResponse{}
+	merged.Result = true
+	for i, result := range results {
+		if !result.done {
+			continue
+		}
+		if result.err != nil && req.StopOnBatchError {
+			continue
+		}
+		merged.merge(i*MaxRecipientsPerRequest, batches[i].Recipients, result.response, result.err)
+	}
+	merged.restorePositions(skipped, positions)
+	
+	if firstErr != nil {
+		return merged, firstErr
+	}
+	
+	if err := ctx.Err(); err != nil {
+		return merged, err
+	}
+	
+	return merged, nil
+}
+
+// splitTemplateMessages divide req en peticiones de hasta MaxRecipientsPerRequest
+// destinatarios y las valida todas antes de enviar la primera
+func splitTemplateMessages(req *SendTemplateMessagesRequest) ([]*SendTemplateMessagesRequest, error) {
+	if len(req.Recipients) == 0 {
+		return nil, fmt.Errorf("validation error: at least one recipient is required")
+	}
+	
+	var batches []*SendTemplateMessagesRequest
+	for start := 0; start < len(req.Recipients); start += MaxRecipientsPerRequest {
+		end := start + MaxRecipientsPerRequest
+		if end > len(req.Recipients) {
+			end = len(req.Recipients)
+		}
+		
+		batch := *req
+		batch.Recipients = req.Recipients[start:end:end]
+		if err := batch.Validate(); err != nil {
+			return nil, fmt.Errorf("validation error in recipients %d-%d: %w", start, end-1, err)
+		}
+		
+		batches = append(batches, &batch)
+	}
+	
+	return batches, nil
+}
+
+// merge agrega a r el resultado de un lote cuyo primer destinatario está en la
+// posición offset de la petición original. Si el lote falló, todos sus
+// destinatarios se informan como errores.
+func (r *BulkMessageResponse) merge(offset int, recipients []TemplateMessageRecipient, response *BulkMessageResponse, err error) {
+	if err != nil {
+		r.Result = false
+		r.FailureCount += len(recipients)
+		for i, recipient := range recipients {
+			r.Errors = append(r.Errors, BulkMessageError{
+				Index:     offset + i,
+				Error:     err.Error(),
+				Recipient: recipient,
+			})
+		}
+		return
+	}
+	
+	r.Result = r.Result && response.Result
+	r.SuccessCount += response.SuccessCount
+	r.FailureCount += response.FailureCount
+	r.Messages = append(r.Messages, response.Messages...)
+	for _, sendError := range response.Errors {
+		sendError.Index += offset
+		r.Errors = append(r.Errors, sendError)
+	}
+	r.SkippedCount += response.SkippedCount
+	r.SkippedRecipients = append(r.SkippedRecipients, response.SkippedRecipients...)
+}
+
+// SendSessionMessage envía un mensaje de texto libre a un contacto. Solo es
+// válido dentro de la ventana de 24 horas desde el último mensaje del contacto;
+// fuera de ella WhatsApp exige una plantilla.
+func (s *Service) SendSessionMessage(ctx context.Context, whatsappNumber, text string) (*MessageResponse, error) {
+	req := &SendSessionMessageRequest{
+		WhatsappNumber: whatsappNumber,
+		MessageText:    text,
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	// WATI recibe el número en la ruta y el texto como query parameter
+	query := url.Values{}
+	query.Set("messageText", req.MessageText)
+	endpoint := fmt.Sprintf("/api/v1/sendSessionMessage/%s?%s", req.WhatsappNumber, query.Encode())
+	
+	var response MessageResponse
+	err := s.client.DoRequest(ctx, "POST", endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error sending session message: %w", err)
+	}
+	
 	return &response, nil
 }
 
+// SendSessionFile envía un archivo como mensaje de sesión, con un caption
+// opcional. El archivo se valida con las reglas de tipo y tamaño del paquete
+// media antes de enviarlo y se transmite en streaming como multipart.
+func (s *Service) SendSessionFile(ctx context.Context, whatsappNumber string, file io.Reader, fileName, caption string) (*MessageResponse, error) {
+	req := &SendSessionFileRequest{
+		WhatsappNumber: whatsappNumber,
+		File:           file,
+		FileName:       fileName,
+		Caption:        caption,
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	streamer, ok := s.client.(media.StreamingHTTPClient)
+	if !ok {
+		return nil, fmt.Errorf("HTTP client does not support streaming requests")
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/sendSessionFile/%s", req.WhatsappNumber)
+	if req.Caption != "" {
+		query := url.Values{}
+		query.Set("caption", req.Caption)
+		endpoint += "?" + query.Encode()
+	}
+	
+	// Si el tamaño no se pudo validar de antemano, se limita mientras se lee
+	var body io.Reader = req.File
+	if _, ok := readerSize(req.File); !ok {
+		maxSize := media.GetMaxFileSize(req.MediaType())
+		body = &sizeLimitedReader{reader: req.File, remaining: maxSize, maxSize: maxSize}
+	}
+	
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	
+	go func() {
+		pw.CloseWithError(writeSessionFile(writer, req.FileName, req.MimeType(), body))
+	}()
+	
+	resp, err := streamer.DoStreamRequest(ctx, "POST", endpoint, pr, writer.FormDataContentType())
+	
+	// Desbloquear al writer si la petición terminó sin consumir todo el cuerpo
+	pr.Close()
+	
+	if err != nil {
+		return nil, fmt.Errorf("error sending session file: %w", err)
+	}
+	defer resp.Body.Close()
+	
+	var response MessageResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("error unmarshaling session file response: %w", err)
+	}
+	
+	return &response, nil
+}
+
+// writeSessionFile escribe el archivo en el multipart writer con su tipo MIME
+func writeSessionFile(writer *multipart.Writer, fileName, mimeType string, file io.Reader) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(fileName, `"`, `\"`)))
+	header.Set("Content-Type", mimeType)
+	
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("error creating form file: %w", err)
+	}
+	
+	if _, err := io.Copy(part, file); err != nil {
+		return fmt.Errorf("error copying file data: %w", err)
+	}
+	
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing multipart writer: %w", err)
+	}
+	
+	return nil
+}
+
+// sizeLimitedReader falla al superar maxSize bytes
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+	maxSize   int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("file exceeds maximum allowed size %d bytes", r.maxSize)
+	}
+	
+	// Leer un byte más del límite para detectar archivos demasiado grandes
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("file exceeds maximum allowed size %d bytes", r.maxSize)
+	}
+	
+	return n, err
+}
+
 // SendInteractiveListMessage envía un mensaje de lista interactiva
 func (s *Service) SendInteractiveListMessage(ctx context.Context, req *InteractiveListMessageRequest) (*MessageResponse, error) {
 	if req == nil {
@@ -99,6 +692,44 @@ func (s *Service) SendInteractiveButtonMessage(ctx context.Context, req *Interac
 	return &response, nil
 }
 
+// SendInteractiveCTAMessage envía un mensaje con un botón de llamada a la acción
+func (s *Service) SendInteractiveCTAMessage(ctx context.Context, req *InteractiveCTAMessageRequest) (*MessageResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	var response MessageResponse
+	err := s.client.DoRequest(ctx, "POST", "/api/v1/sendInteractiveCtaMessage", req, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error sending interactive CTA message: %w", err)
+	}
+	
+	return &response, nil
+}
+
+// SendProductMessage envía un mensaje de producto o de múltiples productos de un catálogo
+func (s *Service) SendProductMessage(ctx context.Context, req *ProductMessageRequest) (*MessageResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("request is required")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	var response MessageResponse
+	err := s.client.DoRequest(ctx, "POST", "/api/v1/sendProductMessage", req, &response)
+	if err != nil {
+		return nil, fmt.Errorf("error sending product message: %w", err)
+	}
+	
+	return &response, nil
+}
+
 // GetMessageTemplates obtiene todas las plantillas de mensajes disponibles
 func (s *Service) GetMessageTemplates(ctx context.Context) (*TemplatesResponse, error) {
 	var response TemplatesResponse
@@ -140,16 +771,7 @@ func (s *Service) GetMessages(ctx context.Context, params *GetMessagesParams) (*
 	params.SetDefaults()
 	
 	// Construir endpoint con query parameters
-	endpoint := "/api/v1/getMessages"
-	queryParams := params.ToMap()
-	
-	if len(queryParams) > 0 {
-		var parts []string
-		for key, value := range queryParams {
-			parts = append(parts, fmt.Sprintf("%s=%s", key, value))
-		}
-		endpoint += "?" + strings.Join(parts, "&")
-	}
+	endpoint := common.BuildEndpoint("/api/v1/getMessages", params.ToMap())
 	
 	var response MessagesResponse
 	err := s.client.DoRequest(ctx, "GET", endpoint, nil, &response)
@@ -202,6 +824,24 @@ func (s *Service) GetMessageStatus(ctx context.Context, id string) (*MessageStat
 	return &response.Status, nil
 }
 
+// MarkMessageRead marca como leído un mensaje recibido, para que el remitente
+// vea la confirmación de lectura
+func (s *Service) MarkMessageRead(ctx context.Context, messageID string) error {
+	if messageID == "" {
+		return fmt.Errorf("message ID is required")
+	}
+	
+	endpoint := fmt.Sprintf("/api/v1/markMessageRead/%s", url.PathEscape(messageID))
+	
+	var response BaseResponse
+	err := s.client.DoRequest(ctx, "POST", endpoint, nil, &response)
+	if err != nil {
+		return fmt.Errorf("error marking message %s as read: %w", messageID, err)
+	}
+	
+	return nil
+}
+
 // GetMessagesByPhone obtiene mensajes de un número de teléfono específico
 func (s *Service) GetMessagesByPhone(ctx context.Context, phone string, params *GetMessagesParams) (*MessagesResponse, error) {
 	if phone == "" {
@@ -216,12 +856,17 @@ func (s *Service) GetMessagesByPhone(ctx context.Context, phone string, params *
 	return s.GetMessages(ctx, params)
 }
 
-// GetMessagesByDateRange obtiene mensajes en un rango de fechas
+// GetMessagesByDateRange obtiene mensajes en un rango de fechas, ambas en
+// formato YYYY-MM-DD e inclusive
 func (s *Service) GetMessagesByDateRange(ctx context.Context, fromDate, toDate string, params *GetMessagesParams) (*MessagesResponse, error) {
 	if fromDate == "" || toDate == "" {
 		return nil, fmt.Errorf("both fromDate and toDate are required")
 	}
 	
+	if err := validateDateRange(fromDate, toDate); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
 	if params == nil {
 		params = &GetMessagesParams{}
 	}
@@ -231,6 +876,226 @@ func (s *Service) GetMessagesByDateRange(ctx context.Context, fromDate, toDate s
 	return s.GetMessages(ctx, params)
 }
 
+// GetMessagesBetween obtiene mensajes entre dos fechas, como
+// GetMessagesByDateRange. Solo se usa el día de from y to, en su propia zona
+// horaria.
+func (s *Service) GetMessagesBetween(ctx context.Context, from, to time.Time, params *GetMessagesParams) (*MessagesResponse, error) {
+	if from.IsZero() || to.IsZero() {
+		return nil, fmt.Errorf("both from and to are required")
+	}
+	
+	return s.GetMessagesByDateRange(ctx, from.Format(MessageDateFormat), to.Format(MessageDateFormat), params)
+}
+
+// Formatos soportados por ExportConversation
+const (
+	ExportFormatJSONLines = "jsonl"
+	ExportFormatCSV       = "csv"
+)
+
+// ExportConversation escribe en w todos los mensajes de un número de teléfono,
+// paginando automáticamente. Soporta los formatos JSON-lines y CSV.
+func (s *Service) ExportConversation(ctx context.Context, whatsappNumber string, w io.Writer, format string) error {
+	if whatsappNumber == "" {
+		return fmt.Errorf("phone number is required")
+	}
+	
+	if w == nil {
+		return fmt.Errorf("writer is required")
+	}
+	
+	var writeMessage func(message *Message) error
+	var flush func() error
+	
+	switch format {
+	case ExportFormatJSONLines:
+		encoder := json.NewEncoder(w)
+		writeMessage = func(message *Message) error {
+			return encoder.Encode(message)
+		}
+		flush = func() error { return nil }
+		
+	case ExportFormatCSV:
+		csvWriter := csv.NewWriter(w)
+		header := []string{"id", "timestamp", "direction", "from", "to", "type", "status", "content"}
+		if err := csvWriter.Write(header); err != nil {
+			return fmt.Errorf("error writing CSV header: %w", err)
+		}
+		writeMessage = func(message *Message) error {
+			return csvWriter.Write([]string{
+				message.ID,
+				message.Timestamp,
+				message.Direction,
+				message.From,
+				message.To,
+				message.Type,
+				message.Status,
+				message.Content,
+			})
+		}
+		flush = func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+	
+	err := s.forEachMessage(ctx, whatsappNumber, func(message *Message) error {
+		if err := writeMessage(message); err != nil {
+			return fmt.Errorf("error writing message %s: %w", message.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error exporting messages: %w", err)
+	}
+	
+	if err := flush(); err != nil {
+		return fmt.Errorf("error flushing export: %w", err)
+	}
+	
+	return nil
+}
+
+// GetConversation obtiene todos los mensajes de un número recorriendo todas las
+// páginas. Con resolveMedia se renueva la URL de cada mensaje con media, lo que
+// agrega una petición a la API por cada uno de ellos.
+func (s *Service) GetConversation(ctx context.Context, whatsappNumber string, resolveMedia bool) ([]Message, error) {
+	if whatsappNumber == "" {
+		return nil, fmt.Errorf("phone number is required")
+	}
+	
+	var mediaService *media.Service
+	if resolveMedia {
+		mediaService = media.NewService(s.client)
+	}
+	
+	var conversation []Message
+	err := s.forEachMessage(ctx, whatsappNumber, func(message *Message) error {
+		if mediaService != nil && message.Media != nil && message.Media.FileName != "" {
+			mediaURL, err := mediaService.GetMediaURL(ctx, message.Media.FileName)
+			if err != nil {
+				return fmt.Errorf("error resolving media for message %s: %w", message.ID, err)
+			}
+			message.Media.URL = mediaURL
+		}
+		
+		conversation = append(conversation, *message)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting conversation: %w", err)
+	}
+	
+	return conversation, nil
+}
+
+// MessageIterator recorre los mensajes página por página, pidiendo cada página
+// al servidor solo cuando se agotó la anterior
+type MessageIterator struct {
+	ctx     context.Context
+	service *Service
+	params  GetMessagesParams
+	page    []Message
+	index   int
+	current Message
+	done    bool
+	err     error
+}
+
+// IterateMessages retorna un iterador sobre los mensajes que coinciden con
+// params. La iteración termina en la última página informada por TotalPages o
+// en la primera página vacía, lo que ocurra antes.
+func (s *Service) IterateMessages(ctx context.Context, params *GetMessagesParams) *MessageIterator {
+	iterator := &MessageIterator{
+		ctx:     ctx,
+		service: s,
+	}
+	if params != nil {
+		iterator.params = *params
+	}
+	iterator.params.SetDefaults()
+	
+	return iterator
+}
+
+// Next avanza al siguiente mensaje, obteniendo una nueva página si es necesario.
+// Retorna false al terminar o ante un error (ver Err).
+func (it *MessageIterator) Next() bool {
+	for it.index >= len(it.page) {
+		if it.done || it.err != nil {
+			return false
+		}
+		
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		
+		response, err := it.service.GetMessages(it.ctx, &it.params)
+		if err != nil {
+			it.err = fmt.Errorf("error getting messages page %d: %w", it.params.PageNumber, err)
+			return false
+		}
+		
+		it.page = response.Messages
+		it.index = 0
+		
+		// Si no hay más páginas, terminar después de esta
+		next, more := response.PaginatedResponse.Advance(it.params.PageNumber, len(response.Messages))
+		it.done = !more
+		it.params.PageNumber = next
+	}
+	
+	it.current = it.page[it.index]
+	it.index++
+	
+	return true
+}
+
+// Message retorna el mensaje actual
+func (it *MessageIterator) Message() Message {
+	return it.current
+}
+
+// Err retorna el error que detuvo la iteración, si lo hubo
+func (it *MessageIterator) Err() error {
+	return it.err
+}
+
+// GetAllMessages obtiene todos los mensajes que coinciden con params paginando
+// automáticamente
+func (s *Service) GetAllMessages(ctx context.Context, params *GetMessagesParams) ([]Message, error) {
+	iterator := s.IterateMessages(ctx, params)
+	
+	var allMessages []Message
+	for iterator.Next() {
+		allMessages = append(allMessages, iterator.Message())
+	}
+	
+	if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+	
+	return allMessages, nil
+}
+
+// forEachMessage recorre todas las páginas de mensajes de un número e invoca fn
+// por cada mensaje, verificando la cancelación del contexto entre páginas
+func (s *Service) forEachMessage(ctx context.Context, whatsappNumber string, fn func(message *Message) error) error {
+	iterator := s.IterateMessages(ctx, &GetMessagesParams{Phone: whatsappNumber})
+	for iterator.Next() {
+		message := iterator.Message()
+		if err := fn(&message); err != nil {
+			return err
+		}
+	}
+	
+	return iterator.Err()
+}
+
 // SendSimpleTemplateMessage envía un mensaje de plantilla simple sin parámetros
 func (s *Service) SendSimpleTemplateMessage(ctx context.Context, phone, templateName, broadcastName string) (*MessageResponse, error) {
 	req := &SendTemplateMessageRequest{
@@ -242,13 +1107,33 @@ func (s *Service) SendSimpleTemplateMessage(ctx context.Context, phone, template
 	return s.SendTemplateMessage(ctx, req)
 }
 
-// SendTemplateMessageWithParams envía un mensaje de plantilla con parámetros
+// SendTemplateMessageWithParams envía un mensaje de plantilla con parámetros.
+// Como el orden de un map no está definido, los parámetros se envían ordenados
+// por nombre, comparando numéricamente los nombres numéricos ("2" antes que
+// "10"). Para plantillas posicionales es preferible SendTemplateMessageOrdered.
 func (s *Service) SendTemplateMessageWithParams(ctx context.Context, phone, templateName, broadcastName string, params map[string]string) (*MessageResponse, error) {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, errA := strconv.Atoi(names[i])
+		b, errB := strconv.Atoi(names[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		if errA == nil || errB == nil {
+			// Los nombres numéricos van primero
+			return errA == nil
+		}
+		return names[i] < names[j]
+	})
+	
 	var parameters []Parameter
-	for name, value := range params {
+	for _, name := range names {
 		parameters = append(parameters, Parameter{
 			Name:  name,
-			Value: value,
+			Value: params[name],
 		})
 	}
 	
@@ -262,6 +1147,98 @@ func (s *Service) SendTemplateMessageWithParams(ctx context.Context, phone, temp
 	return s.SendTemplateMessage(ctx, req)
 }
 
+// SendTemplateMessageOrdered envía un mensaje de plantilla con parámetros
+// posicionales: params[0] reemplaza {{1}}, params[1] reemplaza {{2}}, etc.
+func (s *Service) SendTemplateMessageOrdered(ctx context.Context, phone, templateName, broadcastName string, params []string) (*MessageResponse, error) {
+	parameters := make([]Parameter, len(params))
+	for i, value := range params {
+		parameters[i] = Parameter{
+			Name:  strconv.Itoa(i + 1),
+			Value: value,
+		}
+	}
+	
+	req := &SendTemplateMessageRequest{
+		WhatsappNumber: phone,
+		TemplateName:   templateName,
+		BroadcastName:  broadcastName,
+		Parameters:     parameters,
+	}
+	
+	return s.SendTemplateMessage(ctx, req)
+}
+
+// SendImageTemplate envía una plantilla cuyo header es una imagen. imageURL
+// debe ser una URL http(s) absoluta y pública; bodyParams completa los
+// placeholders del cuerpo.
+func (s *Service) SendImageTemplate(ctx context.Context, phone, templateName, broadcastName, imageURL string, bodyParams []Parameter) (*MessageResponse, error) {
+	if err := validateMediaURL("image URL", imageURL); err != nil {
+		return nil, fmt.Errorf("validation error: %w", err)
+	}
+	
+	req := &SendTemplateMessageRequest{
+		WhatsappNumber: phone,
+		TemplateName:   templateName,
+		BroadcastName:  broadcastName,
+		Parameters:     bodyParams,
+		HeaderParameters: []Parameter{
+			{Name: HeaderImageParameterName, Value: imageURL},
+		},
+	}
+	
+	return s.SendTemplateMessage(ctx, req)
+}
+
+// MessageOption agrega un header o un footer a los mensajes interactivos
+// creados por SendQuickReplyButtons y SendListMenu
+type MessageOption func(*interactiveDecoration)
+
+// interactiveDecoration acumula el header y el footer configurados con MessageOption
+type interactiveDecoration struct {
+	header *InteractiveHeader
+	footer *InteractiveFooter
+}
+
+// WithHeaderText agrega un header de texto. Es excluyente con WithHeaderImage.
+func WithHeaderText(text string) MessageOption {
+	return func(d *interactiveDecoration) {
+		if d.header == nil {
+			d.header = &InteractiveHeader{}
+		}
+		d.header.Type = InteractiveHeaderTypeText
+		d.header.Text = text
+	}
+}
+
+// WithHeaderImage agrega un header con la imagen de mediaURL. Es excluyente con
+// WithHeaderText y no está disponible en los mensajes de lista.
+func WithHeaderImage(mediaURL string) MessageOption {
+	return func(d *interactiveDecoration) {
+		if d.header == nil {
+			d.header = &InteractiveHeader{}
+		}
+		d.header.Type = InteractiveHeaderTypeImage
+		d.header.Image = &InteractiveHeaderMedia{Link: mediaURL}
+	}
+}
+
+// WithFooter agrega un footer de texto
+func WithFooter(text string) MessageOption {
+	return func(d *interactiveDecoration) {
+		d.footer = &InteractiveFooter{Text: text}
+	}
+}
+
+// applyMessageOptions aplica las opciones y retorna el header y el footer resultantes
+func applyMessageOptions(options []MessageOption) (*InteractiveHeader, *InteractiveFooter) {
+	var decoration interactiveDecoration
+	for _, option := range options {
+		option(&decoration)
+	}
+	
+	return decoration.header, decoration.footer
+}
+
 // CreateSimpleListMessage crea un mensaje de lista interactiva simple
 func (s *Service) CreateSimpleListMessage(phone, bodyText, buttonText string, sections []InteractiveSection) *InteractiveListMessageRequest {
 	return &InteractiveListMessageRequest{
@@ -289,8 +1266,9 @@ func (s *Service) CreateSimpleButtonMessage(phone, bodyText string, buttons []In
 	}
 }
 
-// SendQuickReplyButtons envía botones de respuesta rápida
-func (s *Service) SendQuickReplyButtons(ctx context.Context, phone, bodyText string, buttonTitles []string) (*MessageResponse, error) {
+// SendQuickReplyButtons envía botones de respuesta rápida. Las opciones
+// permiten agregar un header de texto o imagen y un footer.
+func (s *Service) SendQuickReplyButtons(ctx context.Context, phone, bodyText string, buttonTitles []string, options ...MessageOption) (*MessageResponse, error) {
 	if len(buttonTitles) == 0 || len(buttonTitles) > 3 {
 		return nil, fmt.Errorf("must provide 1-3 button titles, got %d", len(buttonTitles))
 	}
@@ -298,7 +1276,7 @@ func (s *Service) SendQuickReplyButtons(ctx context.Context, phone, bodyText str
 	var buttons []InteractiveButton
 	for i, title := range buttonTitles {
 		buttons = append(buttons, InteractiveButton{
-			Type: "reply",
+			Type: InteractiveButtonTypeReply,
 			Reply: InteractiveButtonReply{
 				ID:    fmt.Sprintf("btn_%d", i+1),
 				Title: title,
@@ -307,29 +1285,111 @@ func (s *Service) SendQuickReplyButtons(ctx context.Context, phone, bodyText str
 	}
 	
 	req := s.CreateSimpleButtonMessage(phone, bodyText, buttons)
+	req.Header, req.Footer = applyMessageOptions(options)
 	return s.SendInteractiveButtonMessage(ctx, req)
 }
 
-// SendListMenu envía un menú de lista con opciones
-func (s *Service) SendListMenu(ctx context.Context, phone, bodyText, buttonText string, menuItems map[string][]string) (*MessageResponse, error) {
-	var sections []InteractiveSection
+// SendURLButton envía un mensaje con un botón que abre una URL
+func (s *Service) SendURLButton(ctx context.Context, phone, bodyText, buttonText, targetURL string) (*MessageResponse, error) {
+	req := &InteractiveCTAMessageRequest{
+		WhatsappNumber: phone,
+		Body: InteractiveBody{
+			Text: bodyText,
+		},
+		Action: InteractiveCTAAction{
+			Name: InteractiveTypeCTAURL,
+			Parameters: InteractiveCTAParameters{
+				DisplayText: buttonText,
+				URL:         targetURL,
+			},
+		},
+	}
+	
+	return s.SendInteractiveCTAMessage(ctx, req)
+}
+
+// SendCallButton envía un mensaje con un botón que llama a callPhone, en formato E.164
+func (s *Service) SendCallButton(ctx context.Context, phone, bodyText, buttonText, callPhone string) (*MessageResponse, error) {
+	req := &InteractiveCTAMessageRequest{
+		WhatsappNumber: phone,
+		Body: InteractiveBody{
+			Text: bodyText,
+		},
+		Action: InteractiveCTAAction{
+			Name: InteractiveTypeCTACall,
+			Parameters: InteractiveCTAParameters{
+				DisplayText: buttonText,
+				PhoneNumber: callPhone,
+			},
+		},
+	}
+	
+	return s.SendInteractiveCTAMessage(ctx, req)
+}
+
+// ListMenuSection representa una sección de SendOrderedListMenu con sus opciones
+type ListMenuSection struct {
+	Title string
+	Items []string
+}
+
+// SendListMenu envía un menú de lista con opciones. Las opciones permiten
+// agregar un header de texto y un footer. Como el orden de un map no está
+// definido, las secciones se envían ordenadas alfabéticamente por título; para
+// elegir el orden usar SendOrderedListMenu.
+func (s *Service) SendListMenu(ctx context.Context, phone, bodyText, buttonText string, menuItems map[string][]string, options ...MessageOption) (*MessageResponse, error) {
+	titles := make([]string, 0, len(menuItems))
+	for title := range menuItems {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	
+	sections := make([]ListMenuSection, 0, len(titles))
+	for _, title := range titles {
+		sections = append(sections, ListMenuSection{Title: title, Items: menuItems[title]})
+	}
+	
+	return s.SendOrderedListMenu(ctx, phone, bodyText, buttonText, sections, options...)
+}
+
+// SendOrderedListMenu envía un menú de lista respetando el orden de las
+// secciones y de sus opciones. El ID de cada fila se deriva del título de la
+// sección y la posición de la opción ("mas_vendidos_1"), por lo que es estable
+// entre envíos; si dos secciones generan el mismo ID se agrega un sufijo para
+// mantenerlo único.
+func (s *Service) SendOrderedListMenu(ctx context.Context, phone, bodyText, buttonText string, menu []ListMenuSection, options ...MessageOption) (*MessageResponse, error) {
+	if len(menu) > MaxListSections {
+		return nil, fmt.Errorf("menu has %d sections, maximum %d allowed", len(menu), MaxListSections)
+	}
 	
-	for sectionTitle, items := range menuItems {
+	usedIDs := make(map[string]bool)
+	sections := make([]InteractiveSection, 0, len(menu))
+	
+	for _, section := range menu {
+		prefix := strings.ToLower(strings.ReplaceAll(section.Title, " ", "_"))
+		
 		var rows []InteractiveListRow
-		for i, item := range items {
+		for i, item := range section.Items {
+			id := fmt.Sprintf("%s_%d", prefix, i+1)
+			for suffix := 2; usedIDs[id]; suffix++ {
+				id = fmt.Sprintf("%s_%d_%d", prefix, i+1, suffix)
+			}
+			usedIDs[id] = true
+			
 			rows = append(rows, InteractiveListRow{
-				ID:    fmt.Sprintf("%s_%d", strings.ToLower(strings.ReplaceAll(sectionTitle, " ", "_")), i+1),
+				ID:    id,
 				Title: item,
 			})
 		}
 		
 		sections = append(sections, InteractiveSection{
-			Title: sectionTitle,
+			Title: section.Title,
 			Rows:  rows,
 		})
 	}
 	
 	req := s.CreateSimpleListMessage(phone, bodyText, buttonText, sections)
+	req.Header, req.Footer = applyMessageOptions(options)
 	return s.SendInteractiveListMessage(ctx, req)
 }
 
@@ -367,3 +1427,102 @@ func (s *Service) GetActiveTemplates(ctx context.Context) ([]Template, error) {
 	return active, nil
 }
 
+// Intervalos de sondeo de WaitForTemplateApproval. El intervalo se duplica tras
+// cada consulta hasta templatePollMaxInterval.
+var (
+	templatePollInterval    = 2 * time.Second
+	templatePollMaxInterval = 30 * time.Second
+)
+
+// WaitForTemplateApproval consulta la plantilla hasta que sea aprobada o
+// rechazada, o hasta agotar timeout. Una plantilla rechazada se retorna junto
+// con un error que incluye el motivo del rechazo.
+func (s *Service) WaitForTemplateApproval(ctx context.Context, name string, timeout time.Duration) (*Template, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
+	interval := templatePollInterval
+	for {
+		template, err := s.GetMessageTemplate(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		
+		if template.IsApproved() {
+			return template, nil
+		}
+		
+		if template.IsRejected() {
+			return template, fmt.Errorf("template '%s' was rejected: %s", name, template.RejectionReason)
+		}
+		
+		select {
+		case <-ctx.Done():
+			return template, fmt.Errorf("timed out waiting for template '%s' approval (status %s): %w", name, template.Status, ctx.Err())
+		case <-time.After(interval):
+		}
+		
+		interval *= 2
+		if interval > templatePollMaxInterval {
+			interval = templatePollMaxInterval
+		}
+	}
+}
+
+// Intervalos de sondeo de WaitForMessageStatus. El intervalo se duplica tras
+// cada consulta hasta messageStatusPollMaxInterval.
+var (
+	messageStatusPollInterval    = time.Second
+	messageStatusPollMaxInterval = 10 * time.Second
+)
+
+// WaitForMessageStatus consulta el estado de un mensaje hasta que sea entregado,
+// leído o fallido, o hasta agotar timeout. Un mensaje fallido se retorna junto
+// con un error que incluye el motivo informado por la API.
+func (s *Service) WaitForMessageStatus(ctx context.Context, id string, timeout time.Duration) (*MessageStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
+	interval := messageStatusPollInterval
+	for {
+		status, err := s.GetMessageStatus(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		
+		if status.IsFailed() {
+			return status, fmt.Errorf("message %s failed: %s", id, status.Error)
+		}
+		
+		if status.IsFinal() {
+			return status, nil
+		}
+		
+		select {
+		case <-ctx.Done():
+			return status, fmt.Errorf("timed out waiting for message %s delivery (status %s): %w", id, status.Status, ctx.Err())
+		case <-time.After(interval):
+		}
+		
+		interval *= 2
+		if interval > messageStatusPollMaxInterval {
+			interval = messageStatusPollMaxInterval
+		}
+	}
+}
+
+// SendAndWaitForDelivery envía un mensaje de plantilla y espera, hasta timeout,
+// a que sea entregado, leído o falle
+func (s *Service) SendAndWaitForDelivery(ctx context.Context, req *SendTemplateMessageRequest, timeout time.Duration) (*MessageStatus, error) {
+	response, err := s.SendTemplateMessage(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	
+	id := response.MessageID()
+	if id == "" {
+		return nil, fmt.Errorf("send response did not include a message ID")
+	}
+	
+	return s.WaitForMessageStatus(ctx, id, timeout)
+}
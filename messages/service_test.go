@@ -1,10 +1,25 @@
 package messages
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
+	"github.com/diogenes-moreira/wati-sdk/contacts"
+	"github.com/diogenes-moreira/wati-sdk/media"
 )
 
 // MockHTTPClient implementa HTTPClient para testing
@@ -157,6 +172,91 @@ func TestSendTemplateMessage(t *testing.T) {
 	}
 }
 
+func TestSendTemplateMessageChannelNumber(t *testing.T) {
+	tests := []struct {
+		name          string
+		channelNumber string
+		wantPayload   string
+	}{
+		{
+			name:          "explicit channel",
+			channelNumber: "5491112345678",
+			wantPayload:   `"channel_number":"5491112345678"`,
+		},
+		{
+			name: "default channel",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload []byte
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					payload, _ = json.Marshal(body)
+					return nil
+				},
+			}
+			
+			service := NewService(mockClient)
+			request := &SendTemplateMessageRequest{
+				WhatsappNumber: "1234567890",
+				TemplateName:   "hello_world",
+				BroadcastName:  "test_broadcast",
+				ChannelNumber:  tt.channelNumber,
+			}
+			
+			if _, err := service.SendTemplateMessage(context.Background(), request); err != nil {
+				t.Fatalf("SendTemplateMessage() error = %v", err)
+			}
+			
+			if tt.wantPayload != "" && !strings.Contains(string(payload), tt.wantPayload) {
+				t.Errorf("Expected payload to contain %s, got %s", tt.wantPayload, payload)
+			}
+			
+			if tt.wantPayload == "" && strings.Contains(string(payload), "channel_number") {
+				t.Errorf("Expected channel_number to be omitted, got %s", payload)
+			}
+		})
+	}
+}
+
+func TestChannelNumberJSONName(t *testing.T) {
+	// Todas las peticiones envían el número emisor con el mismo nombre
+	requests := []interface{}{
+		&SendTemplateMessageRequest{ChannelNumber: "5491112345678"},
+		&SendTemplateMessagesRequest{ChannelNumber: "5491112345678"},
+		&InteractiveListMessageRequest{ChannelNumber: "5491112345678"},
+		&InteractiveButtonMessageRequest{ChannelNumber: "5491112345678"},
+		&InteractiveCTAMessageRequest{ChannelNumber: "5491112345678"},
+		&ProductMessageRequest{ChannelNumber: "5491112345678"},
+	}
+	
+	for _, request := range requests {
+		payload, err := json.Marshal(request)
+		if err != nil {
+			t.Fatalf("json.Marshal(%T) error = %v", request, err)
+		}
+		
+		if !strings.Contains(string(payload), `"channel_number":"5491112345678"`) {
+			t.Errorf("Expected %T to send channel_number, got %s", request, payload)
+		}
+	}
+}
+
+func TestSendTemplateMessageInvalidChannelNumber(t *testing.T) {
+	request := &SendTemplateMessageRequest{
+		WhatsappNumber: "1234567890",
+		TemplateName:   "hello_world",
+		BroadcastName:  "test_broadcast",
+		ChannelNumber:  "channel-1",
+	}
+	
+	if err := request.Validate(); err == nil {
+		t.Error("Expected error for non-numeric channel number")
+	}
+}
+
 func TestSendTemplateMessagesValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -395,6 +495,33 @@ func TestInteractiveButtonMessageValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "button without type",
+			request: &InteractiveButtonMessageRequest{
+				WhatsappNumber: "1234567890",
+				Body:           InteractiveBody{Text: "Choose an option"},
+				Action: InteractiveButtonAction{
+					Buttons: []InteractiveButton{
+						{Reply: InteractiveButtonReply{ID: "1", Title: "Yes"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "button with invalid type",
+			request: &InteractiveButtonMessageRequest{
+				WhatsappNumber: "1234567890",
+				Body:           InteractiveBody{Text: "Choose an option"},
+				Action: InteractiveButtonAction{
+					Buttons: []InteractiveButton{
+						{Type: "reply", Reply: InteractiveButtonReply{ID: "1", Title: "Yes"}},
+						{Type: "url", Reply: InteractiveButtonReply{ID: "2", Title: "Web"}},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -407,6 +534,115 @@ func TestInteractiveButtonMessageValidation(t *testing.T) {
 	}
 }
 
+func TestSendProductMessage(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if endpoint != "/api/v1/sendProductMessage" {
+				t.Errorf("Expected endpoint '/api/v1/sendProductMessage', got %s", endpoint)
+			}
+			
+			if req, ok := body.(*ProductMessageRequest); ok {
+				if req.CatalogID != "catalog_1" {
+					t.Errorf("Expected catalog ID 'catalog_1', got %s", req.CatalogID)
+				}
+			} else {
+				t.Errorf("Expected ProductMessageRequest body, got %T", body)
+			}
+			
+			// Simular respuesta exitosa
+			if response, ok := result.(*MessageResponse); ok {
+				response.BaseResponse.Result = true
+			}
+			
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx := context.Background()
+	
+	request := &ProductMessageRequest{
+		WhatsappNumber:     "1234567890",
+		CatalogID:          "catalog_1",
+		ProductRetailerIDs: []string{"sku_123"},
+	}
+	
+	response, err := service.SendProductMessage(ctx, request)
+	if err != nil {
+		t.Errorf("SendProductMessage() error = %v", err)
+		return
+	}
+	
+	if !response.Result {
+		t.Error("Expected successful response")
+	}
+}
+
+func TestSendProductMessageMissingCatalog(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("DoRequest should not be called for an invalid request")
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.SendProductMessage(context.Background(), &ProductMessageRequest{
+		WhatsappNumber:     "1234567890",
+		ProductRetailerIDs: []string{"sku_123"},
+	})
+	if err == nil {
+		t.Error("Expected error for missing catalog ID, got nil")
+	}
+}
+
+func TestBulkMessageResponseSuccessRate(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     BulkMessageResponse
+		wantRate     float64
+		wantFailures bool
+	}{
+		{
+			name:         "all success",
+			response:     BulkMessageResponse{SuccessCount: 10},
+			wantRate:     1,
+			wantFailures: false,
+		},
+		{
+			name:         "all failure",
+			response:     BulkMessageResponse{FailureCount: 4},
+			wantRate:     0,
+			wantFailures: true,
+		},
+		{
+			name:         "mixed",
+			response:     BulkMessageResponse{SuccessCount: 3, FailureCount: 1},
+			wantRate:     0.75,
+			wantFailures: true,
+		},
+		{
+			name:         "empty response",
+			response:     BulkMessageResponse{},
+			wantRate:     0,
+			wantFailures: false,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rate := tt.response.SuccessRate(); rate != tt.wantRate {
+				t.Errorf("SuccessRate() = %v, want %v", rate, tt.wantRate)
+			}
+			
+			if failures := tt.response.HasFailures(); failures != tt.wantFailures {
+				t.Errorf("HasFailures() = %v, want %v", failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
 func TestGetMessagesParams(t *testing.T) {
 	params := &GetMessagesParams{
 		PageSize:   10,
@@ -499,6 +735,75 @@ func TestSendQuickReplyButtonsValidation(t *testing.T) {
 	}
 }
 
+// listRequestWithSections construye una lista válida con el número de secciones y filas indicado
+func listRequestWithSections(sections, rows int) *InteractiveListMessageRequest {
+	req := &InteractiveListMessageRequest{
+		WhatsappNumber: "1234567890",
+		Body:           InteractiveBody{Text: "Choose an option"},
+		Action:         InteractiveListAction{Button: "Options"},
+	}
+	
+	for i := 0; i < sections; i++ {
+		section := InteractiveSection{Title: fmt.Sprintf("Section %d", i+1)}
+		for j := 0; j < rows; j++ {
+			section.Rows = append(section.Rows, InteractiveListRow{
+				ID:    fmt.Sprintf("%d_%d", i, j),
+				Title: fmt.Sprintf("Row %d", j+1),
+			})
+		}
+		req.Action.Sections = append(req.Action.Sections, section)
+	}
+	
+	return req
+}
+
+func TestInteractiveListMessageLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections int
+		rows     int
+		wantErr  bool
+	}{
+		{name: "10 sections allowed", sections: 10, rows: 1, wantErr: false},
+		{name: "11 sections rejected", sections: 11, rows: 1, wantErr: true},
+		{name: "10 rows allowed", sections: 1, rows: 10, wantErr: false},
+		{name: "11 rows rejected", sections: 1, rows: 11, wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := listRequestWithSections(tt.sections, tt.rows).Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			
+			if err != nil && !strings.Contains(err.Error(), "got 11") {
+				t.Errorf("Expected error to name the over-limit count, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSendListMenuTooManySections(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("DoRequest should not be called when the menu exceeds the section limit")
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	menuItems := make(map[string][]string)
+	for i := 0; i < MaxListSections+1; i++ {
+		menuItems[fmt.Sprintf("Section %d", i+1)] = []string{"Item"}
+	}
+	
+	if _, err := service.SendListMenu(context.Background(), "1234567890", "What do you need?", "Options", menuItems); err == nil {
+		t.Error("Expected error for menu with too many sections")
+	}
+}
+
 func TestSendListMenu(t *testing.T) {
 	mockClient := &MockHTTPClient{
 		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
@@ -541,6 +846,117 @@ func TestSendListMenu(t *testing.T) {
 	}
 }
 
+func TestExportConversationJSONLines(t *testing.T) {
+	pages := map[string][]Message{
+		"1": {{ID: "msg_1", Content: "Hola"}, {ID: "msg_2", Content: "¿Cómo estás?"}},
+		"2": {{ID: "msg_3", Content: "Bien, gracias"}},
+	}
+	
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				t.Fatalf("Invalid endpoint %s: %v", endpoint, err)
+			}
+			
+			if phone := u.Query().Get("phone"); phone != "1234567890" {
+				t.Errorf("Expected phone '1234567890', got %s", phone)
+			}
+			
+			// Simular respuesta paginada
+			if response, ok := result.(*MessagesResponse); ok {
+				response.TotalPages = 2
+				response.Messages = pages[u.Query().Get("pageNumber")]
+			}
+			
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	var buf bytes.Buffer
+	err := service.ExportConversation(context.Background(), "1234567890", &buf, ExportFormatJSONLines)
+	if err != nil {
+		t.Fatalf("ExportConversation() error = %v", err)
+	}
+	
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+	
+	var last Message
+	if err := json.Unmarshal([]byte(lines[2]), &last); err != nil {
+		t.Fatalf("Invalid JSON line: %v", err)
+	}
+	
+	if last.ID != "msg_3" {
+		t.Errorf("Expected last message 'msg_3', got %s", last.ID)
+	}
+}
+
+func TestGetConversationResolveMedia(t *testing.T) {
+	for _, resolveMedia := range []bool{false, true} {
+		t.Run(fmt.Sprintf("resolveMedia=%v", resolveMedia), func(t *testing.T) {
+			mediaRequests := 0
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					if strings.HasPrefix(endpoint, "/api/v1/getMediaByFileName/") {
+						mediaRequests++
+						if response, ok := result.(*media.MediaResponse); ok {
+							response.Media.URL = "https://cdn.wati.io/fresh/photo.jpg"
+						}
+						return nil
+					}
+					
+					if response, ok := result.(*MessagesResponse); ok {
+						response.TotalPages = 1
+						response.Messages = []Message{
+							{ID: "msg_1", Content: "Hola"},
+							{ID: "msg_2", Media: &MediaInfo{FileName: "photo.jpg", URL: "https://cdn.wati.io/expired/photo.jpg"}},
+						}
+					}
+					return nil
+				},
+			}
+			
+			service := NewService(mockClient)
+			
+			conversation, err := service.GetConversation(context.Background(), "1234567890", resolveMedia)
+			if err != nil {
+				t.Fatalf("GetConversation() error = %v", err)
+			}
+			
+			if len(conversation) != 2 {
+				t.Fatalf("Expected 2 messages, got %d", len(conversation))
+			}
+			
+			wantRequests, wantURL := 0, "https://cdn.wati.io/expired/photo.jpg"
+			if resolveMedia {
+				wantRequests, wantURL = 1, "https://cdn.wati.io/fresh/photo.jpg"
+			}
+			
+			if mediaRequests != wantRequests {
+				t.Errorf("Expected %d media requests, got %d", wantRequests, mediaRequests)
+			}
+			
+			if got := conversation[1].Media.URL; got != wantURL {
+				t.Errorf("Expected media URL %s, got %s", wantURL, got)
+			}
+		})
+	}
+}
+
+func TestExportConversationUnsupportedFormat(t *testing.T) {
+	service := NewService(&MockHTTPClient{})
+	
+	err := service.ExportConversation(context.Background(), "1234567890", &bytes.Buffer{}, "xml")
+	if err == nil {
+		t.Error("Expected error for unsupported format, got nil")
+	}
+}
+
 // Benchmark para medir performance del servicio
 func BenchmarkSendTemplateMessage(b *testing.B) {
 	mockClient := &MockHTTPClient{
@@ -569,3 +985,1698 @@ func BenchmarkSendTemplateMessage(b *testing.B) {
 	}
 }
 
+func TestTemplateRejectionReason(t *testing.T) {
+	payload := []byte(`{
+		"id": "tpl_1",
+		"name": "promo",
+		"status": "rejected",
+		"rejectionReason": "INVALID_FORMAT"
+	}`)
+	
+	var template Template
+	if err := json.Unmarshal(payload, &template); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	
+	if template.RejectionReason != "INVALID_FORMAT" {
+		t.Errorf("Expected rejection reason INVALID_FORMAT, got %q", template.RejectionReason)
+	}
+	
+	if !template.IsRejected() {
+		t.Error("Expected lowercase status to be treated as rejected")
+	}
+	
+	if template.IsApproved() || template.IsPending() {
+		t.Error("Rejected template should not be approved or pending")
+	}
+}
+
+func TestWaitForTemplateApproval(t *testing.T) {
+	templatePollInterval = time.Millisecond
+	defer func() { templatePollInterval = 2 * time.Second }()
+	
+	polls := 0
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			polls++
+			status := "PENDING"
+			if polls >= 2 {
+				status = "APPROVED"
+			}
+			
+			if response, ok := result.(*TemplatesResponse); ok {
+				response.Templates = []Template{{Name: "welcome", Status: status}}
+			}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	template, err := service.WaitForTemplateApproval(context.Background(), "welcome", time.Second)
+	if err != nil {
+		t.Fatalf("WaitForTemplateApproval() error = %v", err)
+	}
+	
+	if !template.IsApproved() {
+		t.Errorf("Expected approved template, got status %s", template.Status)
+	}
+	
+	if polls != 2 {
+		t.Errorf("Expected 2 polls, got %d", polls)
+	}
+}
+
+func TestWaitForTemplateApprovalRejected(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if response, ok := result.(*TemplatesResponse); ok {
+				response.Templates = []Template{{Name: "promo", Status: "REJECTED", RejectionReason: "INVALID_FORMAT"}}
+			}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.WaitForTemplateApproval(context.Background(), "promo", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "INVALID_FORMAT") {
+		t.Errorf("Expected rejection error with reason, got %v", err)
+	}
+}
+
+func TestSendTemplateMessageNormalizesPhoneNumbers(t *testing.T) {
+	var sent *SendTemplateMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			sent = body.(*SendTemplateMessageRequest)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	request := &SendTemplateMessageRequest{
+		WhatsappNumber: "+54 9 11 1234-5678",
+		TemplateName:   "hello_world",
+		BroadcastName:  "test_broadcast",
+		ChannelNumber:  "+1 (555) 123-4567",
+	}
+	
+	if _, err := service.SendTemplateMessage(context.Background(), request); err != nil {
+		t.Fatalf("SendTemplateMessage() error = %v", err)
+	}
+	
+	if sent.WhatsappNumber != "5491112345678" {
+		t.Errorf("Expected normalized whatsappNumber, got %s", sent.WhatsappNumber)
+	}
+	
+	if sent.ChannelNumber != "15551234567" {
+		t.Errorf("Expected normalized channel_number, got %s", sent.ChannelNumber)
+	}
+	
+	request.WhatsappNumber = "abcdefghij"
+	if _, err := service.SendTemplateMessage(context.Background(), request); !errors.Is(err, common.ErrInvalidPhoneNumber) {
+		t.Errorf("Expected ErrInvalidPhoneNumber, got %v", err)
+	}
+}
+
+func TestSendSessionMessage(t *testing.T) {
+	var gotMethod, gotEndpoint string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotMethod = method
+			gotEndpoint = endpoint
+			
+			if response, ok := result.(*MessageResponse); ok {
+				response.Result = true
+			}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	response, err := service.SendSessionMessage(context.Background(), "+54 9 11 1234-5678", "Hola, ¿en qué te ayudo?")
+	if err != nil {
+		t.Fatalf("SendSessionMessage() error = %v", err)
+	}
+	
+	if !response.Result {
+		t.Error("Expected successful response")
+	}
+	
+	if gotMethod != "POST" {
+		t.Errorf("Expected POST method, got %s", gotMethod)
+	}
+	
+	path, rawQuery, _ := strings.Cut(gotEndpoint, "?")
+	if path != "/api/v1/sendSessionMessage/5491112345678" {
+		t.Errorf("Unexpected endpoint path %s", path)
+	}
+	
+	query, _ := url.ParseQuery(rawQuery)
+	if got := query.Get("messageText"); got != "Hola, ¿en qué te ayudo?" {
+		t.Errorf("Expected messageText to round-trip, got %q", got)
+	}
+}
+
+func TestSendSessionMessageValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		number string
+		text   string
+	}{
+		{name: "missing number", number: "", text: "hola"},
+		{name: "invalid number", number: "abc", text: "hola"},
+		{name: "empty text", number: "1234567890", text: "  "},
+		{name: "whitespace-only text", number: "1234567890", text: " \t\n "},
+		{name: "text too long", number: "1234567890", text: strings.Repeat("a", MaxSessionMessageLength+1)},
+	}
+	
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("Expected no request for an invalid session message")
+			return nil
+		},
+	})
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.SendSessionMessage(context.Background(), tt.number, tt.text); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestSessionMessageTrimsText(t *testing.T) {
+	req := &SendSessionMessageRequest{WhatsappNumber: "1234567890", MessageText: "  hola \n"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	if req.MessageText != "hola" {
+		t.Errorf("Expected trimmed text %q, got %q", "hola", req.MessageText)
+	}
+}
+
+func TestInteractiveBodyWhitespace(t *testing.T) {
+	buttons := InteractiveButtonAction{
+		Buttons: []InteractiveButton{
+			{Type: "reply", Reply: InteractiveButtonReply{ID: "1", Title: "Yes"}},
+		},
+	}
+	
+	blank := &InteractiveButtonMessageRequest{
+		WhatsappNumber: "1234567890",
+		Body:           InteractiveBody{Text: " \t "},
+		Action:         buttons,
+	}
+	if err := blank.Validate(); err == nil {
+		t.Error("Expected error for whitespace-only body text")
+	}
+	
+	padded := &InteractiveButtonMessageRequest{
+		WhatsappNumber: "1234567890",
+		Body:           InteractiveBody{Text: "  Choose an option  "},
+		Action:         buttons,
+	}
+	if err := padded.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if padded.Body.Text != "Choose an option" {
+		t.Errorf("Expected trimmed body text, got %q", padded.Body.Text)
+	}
+	
+	cta := &InteractiveCTAMessageRequest{
+		WhatsappNumber: "1234567890",
+		Body:           InteractiveBody{Text: "   "},
+	}
+	if err := cta.Validate(); err == nil {
+		t.Error("Expected error for whitespace-only CTA body text")
+	}
+}
+
+// mockContactLookup implementa ContactLookup a partir de un mapa de contactos
+type mockContactLookup map[string]*contacts.Contact
+
+func (m mockContactLookup) GetContactByPhone(ctx context.Context, phone string) (*contacts.Contact, error) {
+	if contact, ok := m[phone]; ok {
+		return contact, nil
+	}
+	return nil, fmt.Errorf("%w with phone %s", contacts.ErrContactNotFound, phone)
+}
+
+func TestSendTemplateMessagesSkipOptedOut(t *testing.T) {
+	lookup := mockContactLookup{
+		"1111111111": {Phone: "1111111111", AllowBroadcast: true},
+		"2222222222": {Phone: "2222222222", AllowBroadcast: false},
+		"3333333333": {Phone: "3333333333", AllowBroadcast: true},
+		"4444444444": {Phone: "4444444444", AllowBroadcast: false},
+	}
+	
+	var sent []string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			for _, recipient := range body.(*SendTemplateMessagesRequest).Recipients {
+				sent = append(sent, recipient.WhatsappNumber)
+			}
+			response := result.(*BulkMessageResponse)
+			response.SuccessCount = len(sent)
+			response.Errors = []BulkMessageError{{Index: 1, Error: "invalid number"}}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient, WithContactLookup(lookup))
+	
+	req := &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients: []TemplateMessageRecipient{
+			{WhatsappNumber: "1111111111"},
+			{WhatsappNumber: "2222222222"},
+			{WhatsappNumber: "3333333333"},
+			{WhatsappNumber: "4444444444"},
+			{WhatsappNumber: "5555555555"}, // Todavía no es contacto
+		},
+		SkipOptedOut: true,
+	}
+	
+	response, err := service.SendTemplateMessages(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendTemplateMessages() error = %v", err)
+	}
+	
+	if strings.Join(sent, ",") != "1111111111,3333333333,5555555555" {
+		t.Errorf("Unexpected recipients sent: %v", sent)
+	}
+	
+	if response.SkippedCount != 2 || len(response.SkippedRecipients) != 2 {
+		t.Errorf("Expected 2 skipped recipients, got %d", response.SkippedCount)
+	}
+	
+	if response.SuccessCount != 3 {
+		t.Errorf("Expected 3 successes, got %d", response.SuccessCount)
+	}
+	
+	if len(req.Recipients) != 5 {
+		t.Error("Expected the caller's request not to be modified")
+	}
+	
+	// El índice 1 de los enviados corresponde al destinatario 2 de la petición
+	if response.Errors[0].Index != 2 {
+		t.Errorf("Expected error index to refer to the original recipient 2, got %d", response.Errors[0].Index)
+	}
+}
+
+func TestSendTemplateMessagesSkipOptedOutAll(t *testing.T) {
+	lookup := mockContactLookup{
+		"2222222222": {Phone: "2222222222", AllowBroadcast: false},
+	}
+	
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("Expected no request when every recipient is skipped")
+			return nil
+		},
+	}, WithContactLookup(lookup))
+	
+	response, err := service.SendTemplateMessages(context.Background(), &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    []TemplateMessageRecipient{{WhatsappNumber: "2222222222"}},
+		SkipOptedOut:  true,
+	})
+	if err != nil {
+		t.Fatalf("SendTemplateMessages() error = %v", err)
+	}
+	
+	if response.SkippedCount != 1 {
+		t.Errorf("Expected 1 skipped recipient, got %d", response.SkippedCount)
+	}
+}
+
+func TestSendTemplateMessagesSkipOptedOutWithoutLookup(t *testing.T) {
+	service := NewService(&MockHTTPClient{})
+	
+	_, err := service.SendTemplateMessages(context.Background(), &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    []TemplateMessageRecipient{{WhatsappNumber: "1111111111"}},
+		SkipOptedOut:  true,
+	})
+	if err == nil {
+		t.Error("Expected error when no contacts service is configured")
+	}
+}
+
+// MockStreamingHTTPClient agrega DoStreamRequest a MockHTTPClient
+type MockStreamingHTTPClient struct {
+	MockHTTPClient
+	DoStreamRequestFunc func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error)
+}
+
+func (m *MockStreamingHTTPClient) DoStreamRequest(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+	return m.DoStreamRequestFunc(ctx, method, endpoint, body, contentType)
+}
+
+func TestSendSessionFile(t *testing.T) {
+	var gotEndpoint, gotFileName, gotContentType, gotData string
+	mockClient := &MockStreamingHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			gotEndpoint = endpoint
+			
+			_, params, err := mime.ParseMediaType(contentType)
+			if err != nil {
+				t.Fatalf("Invalid content type %s: %v", contentType, err)
+			}
+			
+			part, err := multipart.NewReader(body, params["boundary"]).NextPart()
+			if err != nil {
+				t.Fatalf("Invalid multipart body: %v", err)
+			}
+			gotFileName = part.FileName()
+			gotContentType = part.Header.Get("Content-Type")
+			data, _ := io.ReadAll(part)
+			gotData = string(data)
+			
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"result": true, "model": {"ids": ["wamid.123"]}}`)),
+			}, nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	response, err := service.SendSessionFile(context.Background(), "1234567890", strings.NewReader("%PDF-1.4"), "factura.pdf", "Tu factura")
+	if err != nil {
+		t.Fatalf("SendSessionFile() error = %v", err)
+	}
+	
+	if response.MessageID() != "wamid.123" {
+		t.Errorf("Expected message ID wamid.123, got %q", response.MessageID())
+	}
+	
+	if gotEndpoint != "/api/v1/sendSessionFile/1234567890?caption=Tu+factura" {
+		t.Errorf("Unexpected endpoint %s", gotEndpoint)
+	}
+	
+	if gotFileName != "factura.pdf" || gotContentType != "application/pdf" || gotData != "%PDF-1.4" {
+		t.Errorf("Unexpected file part: name=%s type=%s data=%q", gotFileName, gotContentType, gotData)
+	}
+}
+
+func TestSendSessionFileValidation(t *testing.T) {
+	mockClient := &MockStreamingHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			t.Error("Expected no request for an invalid file")
+			return nil, fmt.Errorf("unexpected request")
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	tests := []struct {
+		name     string
+		file     io.Reader
+		fileName string
+	}{
+		{name: "unsupported type", file: strings.NewReader("data"), fileName: "programa.exe"},
+		{name: "oversized image", file: bytes.NewReader(make([]byte, media.GetMaxFileSize(media.MediaTypeImage)+1)), fileName: "foto.jpg"},
+		{name: "missing file", file: nil, fileName: "foto.jpg"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.SendSessionFile(context.Background(), "1234567890", tt.file, tt.fileName, ""); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestSendSessionFileUnknownSizeLimit(t *testing.T) {
+	mockClient := &MockStreamingHTTPClient{
+		DoStreamRequestFunc: func(ctx context.Context, method, endpoint string, body io.Reader, contentType string) (*http.Response, error) {
+			// Consumir el cuerpo como lo haría el cliente HTTP
+			if _, err := io.ReadAll(body); err != nil {
+				return nil, err
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{"result": true}`))}, nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	// io.MultiReader oculta el tamaño, por lo que el límite se aplica al leer
+	file := io.MultiReader(bytes.NewReader(make([]byte, media.GetMaxFileSize(media.MediaTypeImage)+1)))
+	if _, err := service.SendSessionFile(context.Background(), "1234567890", file, "foto.jpg", ""); err == nil {
+		t.Error("Expected error for a file exceeding the size limit")
+	}
+}
+
+// transientError simula un error de API reintentable como *wati.WATIError
+type transientError struct {
+	retryAfter time.Duration
+}
+
+func (e *transientError) Error() string                 { return "rate limit exceeded" }
+func (e *transientError) IsRetryable() bool             { return true }
+func (e *transientError) GetRetryAfter() time.Duration { return e.retryAfter }
+
+func TestSendTemplateMessageWithRetryLater(t *testing.T) {
+	var attempts int32
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				return &transientError{}
+			}
+			result.(*MessageResponse).Result = true
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	done := make(chan error, 1)
+	req := &SendTemplateMessageRequest{
+		WhatsappNumber: "1234567890",
+		TemplateName:   "hello_world",
+		BroadcastName:  "test_broadcast",
+	}
+	
+	service.SendTemplateMessageWithRetryLater(context.Background(), req, 10*time.Millisecond, func(response *MessageResponse, err error) {
+		if err == nil && !response.Result {
+			err = fmt.Errorf("unexpected response %+v", response)
+		}
+		done <- err
+	})
+	
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected scheduled retry to succeed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Callback was not invoked")
+	}
+	
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestSendTemplateMessageWithRetryLaterCanceled(t *testing.T) {
+	var attempts int32
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			atomic.AddInt32(&attempts, 1)
+			return &transientError{}
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx, cancel := context.WithCancel(context.Background())
+	
+	done := make(chan error, 1)
+	req := &SendTemplateMessageRequest{
+		WhatsappNumber: "1234567890",
+		TemplateName:   "hello_world",
+		BroadcastName:  "test_broadcast",
+	}
+	
+	service.SendTemplateMessageWithRetryLater(ctx, req, time.Hour, func(response *MessageResponse, err error) {
+		done <- err
+	})
+	cancel()
+	
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Callback was not invoked after cancellation")
+	}
+	
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("Expected the scheduled retry to be skipped, got %d attempts", got)
+	}
+}
+
+func TestSendTemplateMessageWithRetryLaterPermanentError(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return fmt.Errorf("invalid template")
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	called := false
+	req := &SendTemplateMessageRequest{
+		WhatsappNumber: "1234567890",
+		TemplateName:   "hello_world",
+		BroadcastName:  "test_broadcast",
+	}
+	
+	service.SendTemplateMessageWithRetryLater(context.Background(), req, time.Hour, func(response *MessageResponse, err error) {
+		called = true
+		if err == nil {
+			t.Error("Expected the permanent error to be reported")
+		}
+	})
+	
+	if !called {
+		t.Error("Expected callback to run synchronously for a permanent error")
+	}
+}
+
+// recipientsFor genera n destinatarios con números distintos
+func recipientsFor(n int) []TemplateMessageRecipient {
+	recipients := make([]TemplateMessageRecipient, n)
+	for i := range recipients {
+		recipients[i].WhatsappNumber = fmt.Sprintf("54911%08d", i)
+	}
+	return recipients
+}
+
+func TestSendTemplateMessagesBatched(t *testing.T) {
+	var batchSizes []int
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			req := body.(*SendTemplateMessagesRequest)
+			batchSizes = append(batchSizes, len(req.Recipients))
+			
+			// Cada lote informa un fallo en su segundo destinatario
+			response := result.(*BulkMessageResponse)
+			response.Result = true
+			response.SuccessCount = len(req.Recipients) - 1
+			response.FailureCount = 1
+			response.Errors = []BulkMessageError{{Index: 1, Error: "invalid number", Recipient: req.Recipients[1]}}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	req := &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    recipientsFor(250),
+	}
+	
+	response, err := service.SendTemplateMessagesBatched(context.Background(), req)
+	if err != nil {
+		t.Fatalf("SendTemplateMessagesBatched() error = %v", err)
+	}
+	
+	if fmt.Sprint(batchSizes) != "[100 100 50]" {
+		t.Errorf("Expected batches [100 100 50], got %v", batchSizes)
+	}
+	
+	if response.SuccessCount != 247 || response.FailureCount != 3 {
+		t.Errorf("Expected 247 successes and 3 failures, got %d and %d", response.SuccessCount, response.FailureCount)
+	}
+	
+	for i, want := range []int{1, 101, 201} {
+		if response.Errors[i].Index != want {
+			t.Errorf("Expected error %d at index %d, got %d", i, want, response.Errors[i].Index)
+		}
+		if response.Errors[i].Recipient.WhatsappNumber != req.Recipients[want].WhatsappNumber {
+			t.Errorf("Expected error %d to reference recipient %d", i, want)
+		}
+	}
+}
+
+func TestSendTemplateMessagesBatchedFailure(t *testing.T) {
+	tests := []struct {
+		name        string
+		stopOnError bool
+		wantErr     bool
+		wantBatches int
+		wantSuccess int
+		wantFailure int
+	}{
+		{name: "continue", stopOnError: false, wantErr: false, wantBatches: 3, wantSuccess: 150, wantFailure: 100},
+		{name: "stop", stopOnError: true, wantErr: true, wantBatches: 2, wantSuccess: 100, wantFailure: 0},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches := 0
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					batches++
+					if batches == 2 {
+						return fmt.Errorf("service unavailable")
+					}
+					
+					response := result.(*BulkMessageResponse)
+					response.Result = true
+					response.SuccessCount = len(body.(*SendTemplateMessagesRequest).Recipients)
+					return nil
+				},
+			}
+			
+			service := NewService(mockClient)
+			
+			response, err := service.SendTemplateMessagesBatched(context.Background(), &SendTemplateMessagesRequest{
+				TemplateName:     "promo",
+				BroadcastName:    "promo_broadcast",
+				Recipients:       recipientsFor(250),
+				StopOnBatchError: tt.stopOnError,
+			})
+			
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SendTemplateMessagesBatched() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			
+			if batches != tt.wantBatches {
+				t.Errorf("Expected %d batches, got %d", tt.wantBatches, batches)
+			}
+			
+			if response.SuccessCount != tt.wantSuccess || response.FailureCount != tt.wantFailure {
+				t.Errorf("Expected %d/%d, got %d/%d", tt.wantSuccess, tt.wantFailure, response.SuccessCount, response.FailureCount)
+			}
+			
+			if !tt.stopOnError && response.Errors[0].Index != 100 {
+				t.Errorf("Expected failed batch errors to start at index 100, got %d", response.Errors[0].Index)
+			}
+		})
+	}
+}
+
+func TestSendTemplateMessagesBatchedValidatesUpFront(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("Expected no batch to be sent when a later batch is invalid")
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	recipients := recipientsFor(150)
+	recipients[120].WhatsappNumber = "invalid"
+	
+	_, err := service.SendTemplateMessagesBatched(context.Background(), &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    recipients,
+	})
+	if err == nil {
+		t.Error("Expected validation error")
+	}
+}
+
+func TestSendTemplateMessagesConcurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+					break
+				}
+			}
+			
+			req := body.(*SendTemplateMessagesRequest)
+			
+			// Los lotes terminan en distinto orden del que se enviaron
+			time.Sleep(time.Duration(len(req.Recipients)%7) * time.Millisecond)
+			
+			response := result.(*BulkMessageResponse)
+			response.Result = true
+			response.SuccessCount = len(req.Recipients) - 1
+			response.FailureCount = 1
+			response.Errors = []BulkMessageError{{Index: 1, Error: "invalid number", Recipient: req.Recipients[1]}}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	req := &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    recipientsFor(950),
+	}
+	
+	response, err := service.SendTemplateMessagesConcurrent(context.Background(), req, 4)
+	if err != nil {
+		t.Fatalf("SendTemplateMessagesConcurrent() error = %v", err)
+	}
+	
+	if response.SuccessCount != 940 || response.FailureCount != 10 {
+		t.Errorf("Expected 940 successes and 10 failures, got %d and %d", response.SuccessCount, response.FailureCount)
+	}
+	
+	for i, sendError := range response.Errors {
+		want := i*MaxRecipientsPerRequest + 1
+		if sendError.Index != want || sendError.Recipient.WhatsappNumber != req.Recipients[want].WhatsappNumber {
+			t.Errorf("Expected error %d at index %d, got %d", i, want, sendError.Index)
+		}
+	}
+	
+	if max := atomic.LoadInt32(&maxInFlight); max > 4 {
+		t.Errorf("Expected at most 4 concurrent batches, got %d", max)
+	}
+}
+
+func TestSendTemplateMessagesConcurrentCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	var calls int32
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			// El primer lote se completa; los demás quedan bloqueados hasta la cancelación
+			if atomic.AddInt32(&calls, 1) == 1 {
+				response := result.(*BulkMessageResponse)
+				response.Result = true
+				response.SuccessCount = len(body.(*SendTemplateMessagesRequest).Recipients)
+				cancel()
+				return nil
+			}
+			
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	response, err := service.SendTemplateMessagesConcurrent(ctx, &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_broadcast",
+		Recipients:    recipientsFor(500),
+	}, 1)
+	
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	
+	if response == nil || response.SuccessCount != 100 || response.FailureCount != 0 {
+		t.Errorf("Expected the partial result of the first batch, got %+v", response)
+	}
+}
+
+// benchmarkBulkClient simula la latencia de red de cada lote
+func benchmarkBulkClient() *MockHTTPClient {
+	return &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			time.Sleep(2 * time.Millisecond)
+			result.(*BulkMessageResponse).SuccessCount = len(body.(*SendTemplateMessagesRequest).Recipients)
+			return nil
+		},
+	}
+}
+
+func BenchmarkSendTemplateMessagesBatched(b *testing.B) {
+	service := NewService(benchmarkBulkClient())
+	recipients := recipientsFor(2000)
+	
+	for i := 0; i < b.N; i++ {
+		req := &SendTemplateMessagesRequest{TemplateName: "promo", BroadcastName: "promo", Recipients: recipients}
+		if _, err := service.SendTemplateMessagesBatched(context.Background(), req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSendTemplateMessagesConcurrent(b *testing.B) {
+	service := NewService(benchmarkBulkClient())
+	recipients := recipientsFor(2000)
+	
+	for i := 0; i < b.N; i++ {
+		req := &SendTemplateMessagesRequest{TemplateName: "promo", BroadcastName: "promo", Recipients: recipients}
+		if _, err := service.SendTemplateMessagesConcurrent(context.Background(), req, 8); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// capturedParameters retorna un cliente que guarda los parámetros enviados
+func capturedParameters(sent *[]Parameter) *MockHTTPClient {
+	return &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			*sent = body.(*SendTemplateMessageRequest).Parameters
+			return nil
+		},
+	}
+}
+
+func TestSendTemplateMessageOrdered(t *testing.T) {
+	var sent []Parameter
+	service := NewService(capturedParameters(&sent))
+	
+	params := []string{"Juan", "pedido 123", "mañana", "10:00", "sucursal centro", "ventanilla 2", "DNI", "efectivo", "sin cargo", "gracias", "saludos"}
+	if _, err := service.SendTemplateMessageOrdered(context.Background(), "1234567890", "order_update", "orders", params); err != nil {
+		t.Fatalf("SendTemplateMessageOrdered() error = %v", err)
+	}
+	
+	if len(sent) != len(params) {
+		t.Fatalf("Expected %d parameters, got %d", len(params), len(sent))
+	}
+	
+	for i, param := range sent {
+		if param.Name != strconv.Itoa(i+1) || param.Value != params[i] {
+			t.Errorf("Expected parameter %d to be {%d %s}, got %+v", i, i+1, params[i], param)
+		}
+	}
+}
+
+func TestSendTemplateMessageWithParamsSorted(t *testing.T) {
+	var sent []Parameter
+	service := NewService(capturedParameters(&sent))
+	
+	params := map[string]string{"10": "j", "2": "b", "1": "a", "name": "Juan", "amount": "100"}
+	
+	// Repetir para detectar un orden dependiente de la iteración del map
+	for i := 0; i < 20; i++ {
+		if _, err := service.SendTemplateMessageWithParams(context.Background(), "1234567890", "order_update", "orders", params); err != nil {
+			t.Fatalf("SendTemplateMessageWithParams() error = %v", err)
+		}
+		
+		var names []string
+		for _, param := range sent {
+			names = append(names, param.Name)
+		}
+		
+		if strings.Join(names, ",") != "1,2,10,amount,name" {
+			t.Fatalf("Expected parameters sorted as 1,2,10,amount,name, got %v", names)
+		}
+	}
+}
+
+func TestGetAllMessagesStopsOnEmptyPage(t *testing.T) {
+	requests := 0
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			requests++
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				t.Fatalf("Invalid endpoint %s: %v", endpoint, err)
+			}
+			
+			// TotalPages inconsistente: el servidor informa más páginas de las que tiene
+			response := result.(*MessagesResponse)
+			response.TotalPages = 100
+			switch u.Query().Get("pageNumber") {
+			case "1":
+				response.Messages = []Message{{ID: "msg_1"}, {ID: "msg_2"}}
+			case "2":
+				response.Messages = []Message{{ID: "msg_3"}}
+			}
+			
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	all, err := service.GetAllMessages(context.Background(), &GetMessagesParams{Phone: "1234567890"})
+	if err != nil {
+		t.Fatalf("GetAllMessages() error = %v", err)
+	}
+	
+	if len(all) != 3 || all[2].ID != "msg_3" {
+		t.Errorf("Expected 3 messages ending in msg_3, got %+v", all)
+	}
+	
+	if requests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestIterateMessagesIsLazy(t *testing.T) {
+	requests := 0
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			requests++
+			response := result.(*MessagesResponse)
+			response.TotalPages = 5
+			response.Messages = []Message{{ID: fmt.Sprintf("msg_%d", requests)}}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	iterator := service.IterateMessages(context.Background(), nil)
+	
+	if requests != 0 {
+		t.Fatalf("Expected no requests before Next, got %d", requests)
+	}
+	
+	if !iterator.Next() || iterator.Message().ID != "msg_1" {
+		t.Fatalf("Expected msg_1, got %+v (err %v)", iterator.Message(), iterator.Err())
+	}
+	
+	if requests != 1 {
+		t.Errorf("Expected a single page request, got %d", requests)
+	}
+}
+
+func TestIterateMessagesContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	
+	requests := 0
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			requests++
+			response := result.(*MessagesResponse)
+			response.TotalPages = 3
+			response.Messages = []Message{{ID: "msg"}}
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	iterator := service.IterateMessages(ctx, nil)
+	
+	if !iterator.Next() {
+		t.Fatalf("Next() = false, err %v", iterator.Err())
+	}
+	
+	// Cancelar entre páginas
+	cancel()
+	
+	if iterator.Next() {
+		t.Error("Expected Next() to stop after cancellation")
+	}
+	
+	if !errors.Is(iterator.Err(), context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", iterator.Err())
+	}
+	
+	if requests != 1 {
+		t.Errorf("Expected 1 page request, got %d", requests)
+	}
+}
+
+func TestInteractiveCTAMessageValidation(t *testing.T) {
+	urlAction := func(displayText, target string) InteractiveCTAAction {
+		return InteractiveCTAAction{
+			Name:       InteractiveTypeCTAURL,
+			Parameters: InteractiveCTAParameters{DisplayText: displayText, URL: target},
+		}
+	}
+	callAction := func(displayText, phone string) InteractiveCTAAction {
+		return InteractiveCTAAction{
+			Name:       InteractiveTypeCTACall,
+			Parameters: InteractiveCTAParameters{DisplayText: displayText, PhoneNumber: phone},
+		}
+	}
+	
+	tests := []struct {
+		name    string
+		action  InteractiveCTAAction
+		wantErr bool
+	}{
+		{name: "valid url", action: urlAction("Ver pedido", "https://example.com/orders/1")},
+		{name: "valid call", action: callAction("Llamar", "+5491112345678")},
+		{name: "relative url", action: urlAction("Ver pedido", "/orders/1"), wantErr: true},
+		{name: "url without host", action: urlAction("Ver pedido", "https://"), wantErr: true},
+		{name: "empty url", action: urlAction("Ver pedido", ""), wantErr: true},
+		{name: "call without plus", action: callAction("Llamar", "5491112345678"), wantErr: true},
+		{name: "call with separators", action: callAction("Llamar", "+54 9 11 1234-5678"), wantErr: true},
+		{name: "missing button text", action: urlAction("", "https://example.com"), wantErr: true},
+		{name: "button text too long", action: urlAction(strings.Repeat("a", MaxCTAButtonTextLength+1), "https://example.com"), wantErr: true},
+		{name: "unknown action", action: InteractiveCTAAction{Name: "cta_other", Parameters: InteractiveCTAParameters{DisplayText: "Ir"}}, wantErr: true},
+		{
+			name: "url and phone mixed",
+			action: InteractiveCTAAction{
+				Name:       InteractiveTypeCTAURL,
+				Parameters: InteractiveCTAParameters{DisplayText: "Ir", URL: "https://example.com", PhoneNumber: "+5491112345678"},
+			},
+			wantErr: true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &InteractiveCTAMessageRequest{
+				WhatsappNumber: "1234567890",
+				Body:           InteractiveBody{Text: "Tu pedido está listo"},
+				Action:         tt.action,
+			}
+			
+			err := req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSendURLButtonAndCallButton(t *testing.T) {
+	var sent []*InteractiveCTAMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if endpoint != "/api/v1/sendInteractiveCtaMessage" {
+				t.Errorf("Expected endpoint '/api/v1/sendInteractiveCtaMessage', got %s", endpoint)
+			}
+			
+			sent = append(sent, body.(*InteractiveCTAMessageRequest))
+			
+			if response, ok := result.(*MessageResponse); ok {
+				response.BaseResponse.Result = true
+			}
+			
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx := context.Background()
+	
+	if _, err := service.SendURLButton(ctx, "1234567890", "Seguí tu envío", "Ver envío", "https://example.com/track"); err != nil {
+		t.Fatalf("SendURLButton() error = %v", err)
+	}
+	
+	if _, err := service.SendCallButton(ctx, "1234567890", "¿Necesitás ayuda?", "Llamar", "+5491112345678"); err != nil {
+		t.Fatalf("SendCallButton() error = %v", err)
+	}
+	
+	if len(sent) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(sent))
+	}
+	
+	if sent[0].Action.Name != InteractiveTypeCTAURL || sent[0].Action.Parameters.URL != "https://example.com/track" {
+		t.Errorf("Unexpected URL action %+v", sent[0].Action)
+	}
+	
+	if sent[1].Action.Name != InteractiveTypeCTACall || sent[1].Action.Parameters.PhoneNumber != "+5491112345678" {
+		t.Errorf("Unexpected call action %+v", sent[1].Action)
+	}
+	
+	if _, err := service.SendCallButton(ctx, "1234567890", "¿Necesitás ayuda?", "Llamar", "12345"); err == nil {
+		t.Error("Expected validation error for a non E.164 phone")
+	}
+}
+
+func TestSendAndWaitForDelivery(t *testing.T) {
+	messageStatusPollInterval = time.Millisecond
+	defer func() { messageStatusPollInterval = time.Second }()
+	
+	polls := 0
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			if strings.HasPrefix(endpoint, "/api/v1/sendTemplateMessage") {
+				response := result.(*MessageResponse)
+				response.Result = true
+				response.Model.IDs = []string{"msg_123"}
+				return nil
+			}
+			
+			if endpoint != "/api/v1/getMessageStatus/msg_123" {
+				t.Errorf("Unexpected endpoint %s", endpoint)
+			}
+			
+			polls++
+			status := MessageStatusSent
+			if polls >= 2 {
+				status = MessageStatusDelivered
+			}
+			
+			return json.Unmarshal([]byte(fmt.Sprintf(`{"result":true,"status":{"id":"msg_123","status":%q}}`, status)), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	status, err := service.SendAndWaitForDelivery(context.Background(), &SendTemplateMessageRequest{
+		WhatsappNumber: "1234567890",
+		TemplateName:   "order_update",
+		BroadcastName:  "orders",
+	}, time.Second)
+	if err != nil {
+		t.Fatalf("SendAndWaitForDelivery() error = %v", err)
+	}
+	
+	if !status.IsDelivered() || status.ID != "msg_123" {
+		t.Errorf("Expected delivered msg_123, got %+v", status)
+	}
+	
+	if polls != 2 {
+		t.Errorf("Expected 2 polls, got %d", polls)
+	}
+}
+
+func TestWaitForMessageStatusFailed(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return json.Unmarshal([]byte(`{"result":true,"status":{"id":"msg_1","status":"failed","error":"RECIPIENT_UNAVAILABLE"}}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	status, err := service.WaitForMessageStatus(context.Background(), "msg_1", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "RECIPIENT_UNAVAILABLE") {
+		t.Errorf("Expected failure error with reason, got %v", err)
+	}
+	
+	if status == nil || !status.IsFailed() {
+		t.Errorf("Expected failed status, got %+v", status)
+	}
+}
+
+func TestWaitForMessageStatusTimeout(t *testing.T) {
+	messageStatusPollInterval = time.Millisecond
+	defer func() { messageStatusPollInterval = time.Second }()
+	
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return json.Unmarshal([]byte(`{"result":true,"status":{"id":"msg_1","status":"sent"}}`), result)
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.WaitForMessageStatus(context.Background(), "msg_1", 20*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestSendQuickReplyButtonsWithOptions(t *testing.T) {
+	var sent *InteractiveButtonMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			sent = body.(*InteractiveButtonMessageRequest)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx := context.Background()
+	
+	_, err := service.SendQuickReplyButtons(ctx, "1234567890", "¿Confirmás el turno?", []string{"Sí", "No"},
+		WithHeaderImage("https://example.com/turno.png"),
+		WithFooter("Responder antes de las 18hs"),
+	)
+	if err != nil {
+		t.Fatalf("SendQuickReplyButtons() error = %v", err)
+	}
+	
+	if sent.Header == nil || sent.Header.Type != InteractiveHeaderTypeImage || sent.Header.Image.Link != "https://example.com/turno.png" {
+		t.Errorf("Unexpected header %+v", sent.Header)
+	}
+	
+	if sent.Footer == nil || sent.Footer.Text != "Responder antes de las 18hs" {
+		t.Errorf("Unexpected footer %+v", sent.Footer)
+	}
+	
+	// Texto e imagen en el mismo header no están permitidos
+	_, err = service.SendQuickReplyButtons(ctx, "1234567890", "¿Confirmás el turno?", []string{"Sí", "No"},
+		WithHeaderText("Turno"),
+		WithHeaderImage("https://example.com/turno.png"),
+	)
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("Expected mutually exclusive header error, got %v", err)
+	}
+}
+
+func TestSendListMenuWithOptions(t *testing.T) {
+	var sent *InteractiveListMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			sent = body.(*InteractiveListMessageRequest)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	ctx := context.Background()
+	menu := map[string][]string{"Productos": {"Tablet"}}
+	
+	_, err := service.SendListMenu(ctx, "1234567890", "¿En qué podemos ayudarte?", "Ver opciones", menu,
+		WithHeaderText("Menú principal"),
+		WithFooter("Atención 24hs"),
+	)
+	if err != nil {
+		t.Fatalf("SendListMenu() error = %v", err)
+	}
+	
+	if sent.Header == nil || sent.Header.Type != InteractiveHeaderTypeText || sent.Header.Text != "Menú principal" {
+		t.Errorf("Unexpected header %+v", sent.Header)
+	}
+	
+	if sent.Footer == nil || sent.Footer.Text != "Atención 24hs" {
+		t.Errorf("Unexpected footer %+v", sent.Footer)
+	}
+	
+	// Los mensajes de lista no admiten headers con imagen
+	_, err = service.SendListMenu(ctx, "1234567890", "¿En qué podemos ayudarte?", "Ver opciones", menu,
+		WithHeaderImage("https://example.com/menu.png"),
+	)
+	if err == nil {
+		t.Error("Expected error for image header in a list message")
+	}
+}
+
+func TestCharacterLimitsMatchEnforcement(t *testing.T) {
+	limits := CharacterLimits()
+	
+	newList := func() *InteractiveListMessageRequest {
+		return &InteractiveListMessageRequest{
+			WhatsappNumber: "1234567890",
+			Header:         &InteractiveHeader{Type: InteractiveHeaderTypeText, Text: "Menú"},
+			Body:           InteractiveBody{Text: "Elegí una opción"},
+			Footer:         &InteractiveFooter{Text: "Atención 24hs"},
+			Action: InteractiveListAction{
+				Button: "Ver",
+				Sections: []InteractiveSection{
+					{Title: "Productos", Rows: []InteractiveListRow{{ID: "1", Title: "Tablet", Description: "10 pulgadas"}}},
+				},
+			},
+		}
+	}
+	newButtons := func() *InteractiveButtonMessageRequest {
+		return &InteractiveButtonMessageRequest{
+			WhatsappNumber: "1234567890",
+			Body:           InteractiveBody{Text: "¿Confirmás?"},
+			Action: InteractiveButtonAction{
+				Buttons: []InteractiveButton{{Type: InteractiveButtonTypeReply, Reply: InteractiveButtonReply{ID: "1", Title: "Sí"}}},
+			},
+		}
+	}
+	
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+		build func(text string) interface{ Validate() error }
+	}{
+		{name: "body", limit: limits.Body, want: MaxInteractiveBodyLength, build: func(text string) interface{ Validate() error } {
+			req := newButtons()
+			req.Body.Text = text
+			return req
+		}},
+		{name: "header", limit: limits.Header, want: MaxInteractiveHeaderLength, build: func(text string) interface{ Validate() error } {
+			req := newList()
+			req.Header.Text = text
+			return req
+		}},
+		{name: "footer", limit: limits.Footer, want: MaxInteractiveFooterLength, build: func(text string) interface{ Validate() error } {
+			req := newList()
+			req.Footer.Text = text
+			return req
+		}},
+		{name: "button", limit: limits.Button, want: MaxInteractiveButtonLength, build: func(text string) interface{ Validate() error } {
+			req := newButtons()
+			req.Action.Buttons[0].Reply.Title = text
+			return req
+		}},
+		{name: "list button", limit: limits.Button, want: MaxInteractiveButtonLength, build: func(text string) interface{ Validate() error } {
+			req := newList()
+			req.Action.Button = text
+			return req
+		}},
+		{name: "row title", limit: limits.RowTitle, want: MaxListRowTitleLength, build: func(text string) interface{ Validate() error } {
+			req := newList()
+			req.Action.Sections[0].Rows[0].Title = text
+			return req
+		}},
+		{name: "row description", limit: limits.RowDescription, want: MaxListRowDescriptionLength, build: func(text string) interface{ Validate() error } {
+			req := newList()
+			req.Action.Sections[0].Rows[0].Description = text
+			return req
+		}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limit != tt.want {
+				t.Fatalf("CharacterLimits() %s = %d, want %d", tt.name, tt.limit, tt.want)
+			}
+			
+			// Se cuentan caracteres: "ñ" ocupa dos bytes pero cuenta como uno
+			if err := tt.build(strings.Repeat("ñ", tt.limit)).Validate(); err != nil {
+				t.Errorf("Expected %d characters to be accepted, got %v", tt.limit, err)
+			}
+			
+			if err := tt.build(strings.Repeat("ñ", tt.limit+1)).Validate(); err == nil {
+				t.Errorf("Expected %d characters to be rejected", tt.limit+1)
+			}
+		})
+	}
+}
+
+func TestSendOrderedListMenuKeepsOrder(t *testing.T) {
+	var sent *InteractiveListMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			sent = body.(*InteractiveListMessageRequest)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	menu := []ListMenuSection{
+		{Title: "Más vendidos", Items: []string{"Tablet", "Laptop"}},
+		{Title: "Accesorios", Items: []string{"Funda"}},
+		{Title: "más_vendidos", Items: []string{"Cargador"}},
+	}
+	
+	_, err := service.SendOrderedListMenu(context.Background(), "1234567890", "¿Qué buscás?", "Ver", menu)
+	if err != nil {
+		t.Fatalf("SendOrderedListMenu() error = %v", err)
+	}
+	
+	sections := sent.Action.Sections
+	if len(sections) != 3 || sections[0].Title != "Más vendidos" || sections[1].Title != "Accesorios" {
+		t.Fatalf("Expected sections in the given order, got %+v", sections)
+	}
+	
+	if sections[0].Rows[0].ID != "más_vendidos_1" || sections[0].Rows[1].ID != "más_vendidos_2" {
+		t.Errorf("Unexpected row IDs %+v", sections[0].Rows)
+	}
+	
+	// "más_vendidos" genera el mismo prefijo que "Más vendidos": el ID no debe repetirse
+	if id := sections[2].Rows[0].ID; id != "más_vendidos_1_2" {
+		t.Errorf("Expected deduplicated row ID, got %s", id)
+	}
+}
+
+func TestSendListMenuSortsSections(t *testing.T) {
+	var sent *InteractiveListMessageRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			sent = body.(*InteractiveListMessageRequest)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	menu := map[string][]string{
+		"Servicios": {"Soporte"},
+		"Productos": {"Tablet"},
+		"Envíos":    {"Seguimiento"},
+	}
+	
+	// El orden debe ser el mismo en cada envío
+	for i := 0; i < 5; i++ {
+		if _, err := service.SendListMenu(context.Background(), "1234567890", "¿Qué buscás?", "Ver", menu); err != nil {
+			t.Fatalf("SendListMenu() error = %v", err)
+		}
+		
+		var titles []string
+		for _, section := range sent.Action.Sections {
+			titles = append(titles, section.Title)
+		}
+		
+		if strings.Join(titles, ",") != "Envíos,Productos,Servicios" {
+			t.Fatalf("Expected alphabetical sections, got %v", titles)
+		}
+	}
+}
+
+func TestMarkMessageRead(t *testing.T) {
+	var gotMethod, gotEndpoint string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotMethod, gotEndpoint = method, endpoint
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	if err := service.MarkMessageRead(context.Background(), "wamid.123"); err != nil {
+		t.Fatalf("MarkMessageRead() error = %v", err)
+	}
+	
+	if gotMethod != "POST" || gotEndpoint != "/api/v1/markMessageRead/wamid.123" {
+		t.Errorf("Unexpected request %s %s", gotMethod, gotEndpoint)
+	}
+	
+	if err := service.MarkMessageRead(context.Background(), "wamid/1?x=2"); err != nil {
+		t.Fatalf("MarkMessageRead() error = %v", err)
+	}
+	
+	if gotEndpoint != "/api/v1/markMessageRead/wamid%2F1%3Fx=2" {
+		t.Errorf("Expected escaped message ID in endpoint, got %s", gotEndpoint)
+	}
+	
+	if err := service.MarkMessageRead(context.Background(), ""); err == nil {
+		t.Error("Expected error for empty message ID")
+	}
+}
+
+// quotaFunc adapta una función a QuotaChecker
+type quotaFunc func(ctx context.Context) (int, error)
+
+func (f quotaFunc) RemainingQuota(ctx context.Context) (int, error) {
+	return f(ctx)
+}
+
+func TestSendTemplateMessagesQuotaGuard(t *testing.T) {
+	tests := []struct {
+		name      string
+		remaining int
+		wantErr   bool
+	}{
+		{name: "enough quota", remaining: 3},
+		{name: "no known limit", remaining: -1},
+		{name: "quota below recipients", remaining: 2, wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sends := 0
+			mockClient := &MockHTTPClient{
+				DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+					sends++
+					return nil
+				},
+			}
+			
+			checker := quotaFunc(func(ctx context.Context) (int, error) { return tt.remaining, nil })
+			service := NewService(mockClient, WithQuotaChecker(checker))
+			
+			req := &SendTemplateMessagesRequest{
+				TemplateName:  "promo",
+				BroadcastName: "promo_octubre",
+				Recipients:    recipientsFor(3),
+			}
+			
+			for _, send := range []func() error{
+				func() error { _, err := service.SendTemplateMessages(context.Background(), req); return err },
+				func() error { _, err := service.SendTemplateMessagesBatched(context.Background(), req); return err },
+				func() error { _, err := service.SendTemplateMessagesConcurrent(context.Background(), req, 2); return err },
+			} {
+				err := send()
+				if tt.wantErr != errors.Is(err, ErrQuotaExceeded) {
+					t.Errorf("Expected ErrQuotaExceeded %v, got %v", tt.wantErr, err)
+				}
+			}
+			
+			if tt.wantErr && sends != 0 {
+				t.Errorf("Expected no sends when quota is exceeded, got %d", sends)
+			}
+		})
+	}
+}
+
+func TestSendTemplateMessagesQuotaAfterSkipOptedOut(t *testing.T) {
+	lookup := mockContactLookup{
+		"1111111111": {Phone: "1111111111", AllowBroadcast: false},
+		"3333333333": {Phone: "3333333333", AllowBroadcast: false},
+	}
+	
+	var sent []string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			for _, recipient := range body.(*SendTemplateMessagesRequest).Recipients {
+				sent = append(sent, recipient.WhatsappNumber)
+			}
+			response := result.(*BulkMessageResponse)
+			response.Result = true
+			response.Errors = []BulkMessageError{{Index: 1, Error: "invalid number"}}
+			return nil
+		},
+	}
+	
+	// Cuatro destinatarios superan la cuota, pero dos están dados de baja
+	checks := 0
+	checker := quotaFunc(func(ctx context.Context) (int, error) {
+		checks++
+		return 2, nil
+	})
+	service := NewService(mockClient, WithQuotaChecker(checker), WithContactLookup(lookup))
+	
+	req := &SendTemplateMessagesRequest{
+		TemplateName:  "promo",
+		BroadcastName: "promo_octubre",
+		Recipients: []TemplateMessageRecipient{
+			{WhatsappNumber: "1111111111"},
+			{WhatsappNumber: "2222222222"},
+			{WhatsappNumber: "3333333333"},
+			{WhatsappNumber: "4444444444"},
+		},
+		SkipOptedOut: true,
+	}
+	
+	for name, send := range map[string]func() (*BulkMessageResponse, error){
+		"single":     func() (*BulkMessageResponse, error) { return service.SendTemplateMessages(context.Background(), req) },
+		"batched":    func() (*BulkMessageResponse, error) { return service.SendTemplateMessagesBatched(context.Background(), req) },
+		"concurrent": func() (*BulkMessageResponse, error) { return service.SendTemplateMessagesConcurrent(context.Background(), req, 2) },
+	} {
+		sent, checks = nil, 0
+		
+		response, err := send()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", name, err)
+		}
+		
+		if strings.Join(sent, ",") != "2222222222,4444444444" {
+			t.Errorf("%s: unexpected recipients sent: %v", name, sent)
+		}
+		
+		if checks != 1 {
+			t.Errorf("%s: expected the quota to be checked once, got %d", name, checks)
+		}
+		
+		if response.SkippedCount != 2 {
+			t.Errorf("%s: expected 2 skipped recipients, got %d", name, response.SkippedCount)
+		}
+		
+		// El índice 1 de los enviados corresponde al destinatario 3 de la petición
+		if len(response.Errors) != 1 || response.Errors[0].Index != 3 {
+			t.Errorf("%s: expected error index 3, got %+v", name, response.Errors)
+		}
+	}
+}
+
+func TestSendImageTemplate(t *testing.T) {
+	var payload string
+	var endpoints []string
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			endpoints = append(endpoints, endpoint)
+			data, err := json.Marshal(body)
+			if err != nil {
+				return err
+			}
+			payload = string(data)
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	_, err := service.SendImageTemplate(context.Background(), "1234567890", "promo_verano", "promos",
+		"https://cdn.example.com/promo.jpg", []Parameter{{Name: "1", Value: "Juan"}, {Name: "2", Value: "20%"}})
+	if err != nil {
+		t.Fatalf("SendImageTemplate() error = %v", err)
+	}
+	
+	for _, want := range []string{
+		`"template_name":"promo_verano"`,
+		`"header_parameters":[{"name":"header_image","value":"https://cdn.example.com/promo.jpg"}]`,
+		`"parameters":[{"name":"1","value":"Juan"},{"name":"2","value":"20%"}]`,
+	} {
+		if !strings.Contains(payload, want) {
+			t.Errorf("Expected payload to contain %s, got %s", want, payload)
+		}
+	}
+	
+	for _, invalid := range []string{"", "promo.jpg", "ftp://cdn.example.com/promo.jpg"} {
+		if _, err := service.SendImageTemplate(context.Background(), "1234567890", "promo_verano", "promos", invalid, nil); err == nil {
+			t.Errorf("Expected error for image URL %q", invalid)
+		}
+	}
+	
+	if len(endpoints) != 1 {
+		t.Errorf("Expected invalid URLs to be rejected before sending, got %d requests", len(endpoints))
+	}
+}
+
+func TestGetMessagesEncodesQuery(t *testing.T) {
+	var gotEndpoint string
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return nil
+		},
+	})
+	
+	_, err := service.GetMessages(context.Background(), &GetMessagesParams{
+		Phone:    "+5491112345678",
+		FromDate: "2024-01-01 10:00",
+		ToDate:   "2024-01-31&x=1",
+	})
+	if err != nil {
+		t.Fatalf("GetMessages() error = %v", err)
+	}
+	
+	want := "/api/v1/getMessages?fromDate=2024-01-01+10%3A00&pageNumber=1&pageSize=20&phone=%2B5491112345678&toDate=2024-01-31%26x%3D1"
+	if gotEndpoint != want {
+		t.Errorf("Expected endpoint %s, got %s", want, gotEndpoint)
+	}
+}
+
+func TestGetMessagesByDateRangeValidation(t *testing.T) {
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Errorf("Expected no request for an invalid range, got %s", endpoint)
+			return nil
+		},
+	})
+	
+	tests := []struct {
+		name     string
+		fromDate string
+		toDate   string
+	}{
+		{name: "missing toDate", fromDate: "2024-01-01", toDate: ""},
+		{name: "invalid month", fromDate: "2024-13-01", toDate: "2024-12-31"},
+		{name: "wrong format", fromDate: "01/01/2024", toDate: "2024-01-31"},
+		{name: "datetime", fromDate: "2024-01-01", toDate: "2024-01-31T10:00:00Z"},
+		{name: "swapped range", fromDate: "2024-02-01", toDate: "2024-01-01"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.GetMessagesByDateRange(context.Background(), tt.fromDate, tt.toDate, nil); err == nil {
+				t.Error("Expected validation error")
+			}
+		})
+	}
+}
+
+func TestGetMessagesBetween(t *testing.T) {
+	var gotEndpoint string
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotEndpoint = endpoint
+			return nil
+		},
+	})
+	
+	from := time.Date(2024, 1, 1, 23, 30, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	if _, err := service.GetMessagesBetween(context.Background(), from, to, nil); err != nil {
+		t.Fatalf("GetMessagesBetween() error = %v", err)
+	}
+	
+	if !strings.Contains(gotEndpoint, "fromDate=2024-01-01") || !strings.Contains(gotEndpoint, "toDate=2024-01-01") {
+		t.Errorf("Expected formatted dates in endpoint, got %s", gotEndpoint)
+	}
+	
+	if _, err := service.GetMessagesBetween(context.Background(), to.AddDate(0, 0, 1), from, nil); err == nil {
+		t.Error("Expected error for swapped range")
+	}
+	if _, err := service.GetMessagesBetween(context.Background(), time.Time{}, to, nil); err == nil {
+		t.Error("Expected error for zero time")
+	}
+}
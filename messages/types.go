@@ -2,7 +2,16 @@ package messages
 
 import (
 	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/common"
+	"github.com/diogenes-moreira/wati-sdk/media"
 )
 
 // Message representa un mensaje en WATI
@@ -59,6 +68,25 @@ type SendTemplateMessageRequest struct {
 	TemplateName   string      `json:"template_name"`
 	BroadcastName  string      `json:"broadcast_name"`
 	Parameters     []Parameter `json:"parameters,omitempty"`
+	
+	// ChannelNumber selecciona el número emisor en cuentas con varios canales.
+	// Si se omite, WATI usa el canal por defecto de la cuenta.
+	ChannelNumber string `json:"channel_number,omitempty"`
+	
+	// Parámetros del header y de los botones URL dinámicos; ver TemplateMessageBuilder
+	HeaderParameters []Parameter       `json:"header_parameters,omitempty"`
+	ButtonParameters []ButtonParameter `json:"button_parameters,omitempty"`
+}
+
+// HeaderImageParameterName es el nombre del parámetro de header que lleva la
+// URL de la imagen en las plantillas con header de imagen
+const HeaderImageParameterName = "header_image"
+
+// ButtonParameter completa el sufijo dinámico de la URL del botón Index
+// (base 0) de una plantilla
+type ButtonParameter struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
 }
 
 // SendTemplateMessagesRequest representa la petición para enviar múltiples mensajes de plantilla
@@ -66,14 +94,50 @@ type SendTemplateMessagesRequest struct {
 	TemplateName   string                        `json:"template_name"`
 	BroadcastName  string                        `json:"broadcast_name"`
 	Recipients     []TemplateMessageRecipient    `json:"recipients"`
+	ChannelNumber  string                        `json:"channel_number,omitempty"`
+	
+	// SkipOptedOut excluye antes del envío a los destinatarios cuyo contacto
+	// tiene AllowBroadcast en false. Requiere WithContactLookup.
+	SkipOptedOut bool `json:"-"`
+	
+	// StopOnBatchError detiene SendTemplateMessagesBatched en el primer lote
+	// que falla en lugar de continuar con los siguientes
+	StopOnBatchError bool `json:"-"`
 }
 
+// MessageDateFormat es el formato de fecha que WATI espera en los filtros del
+// historial de mensajes
+const MessageDateFormat = "2006-01-02"
+
+// MaxRecipientsPerRequest es la cantidad máxima de destinatarios que WATI
+// acepta por llamada a sendTemplateMessages
+const MaxRecipientsPerRequest = 100
+
 // TemplateMessageRecipient representa un destinatario de mensaje de plantilla
 type TemplateMessageRecipient struct {
 	WhatsappNumber string      `json:"whatsappNumber"`
 	Parameters     []Parameter `json:"parameters,omitempty"`
 }
 
+// MaxSessionMessageLength es la longitud máxima del texto de un mensaje de sesión
+const MaxSessionMessageLength = 4096
+
+// SendSessionMessageRequest representa la petición para enviar un mensaje de
+// texto libre dentro de la ventana de 24 horas de la conversación
+type SendSessionMessageRequest struct {
+	WhatsappNumber string `json:"whatsappNumber"`
+	MessageText    string `json:"messageText"`
+}
+
+// SendSessionFileRequest representa la petición para enviar un archivo (imagen,
+// documento, video o audio) dentro de la ventana de 24 horas
+type SendSessionFileRequest struct {
+	WhatsappNumber string
+	File           io.Reader
+	FileName       string
+	Caption        string
+}
+
 // MessageResponse representa la respuesta de envío de mensaje
 type MessageResponse struct {
 	BaseResponse
@@ -85,17 +149,35 @@ type MessageResponse struct {
 	ValidWhatsAppNumber bool      `json:"validWhatsAppNumber"`
 }
 
+// MessageID retorna el ID del primer mensaje creado por el envío, o "" si la
+// respuesta no lo incluye
+func (r *MessageResponse) MessageID() string {
+	if len(r.Model.IDs) == 0 {
+		return ""
+	}
+	
+	return r.Model.IDs[0]
+}
+
 // BulkMessageResponse representa la respuesta de envío múltiple
 type BulkMessageResponse struct {
 	BaseResponse
 	SuccessCount int             `json:"successCount"`
 	FailureCount int             `json:"failureCount"`
 	Messages     []MessageResponse `json:"messages"`
-	Errors       []struct {
-		Index     int    `json:"index"`
-		Error     string `json:"error"`
-		Recipient TemplateMessageRecipient `json:"recipient"`
-	} `json:"errors,omitempty"`
+	Errors       []BulkMessageError `json:"errors,omitempty"`
+	
+	// Destinatarios excluidos por SkipOptedOut; no los informa WATI
+	SkippedCount      int                        `json:"-"`
+	SkippedRecipients []TemplateMessageRecipient `json:"-"`
+}
+
+// BulkMessageError describe un destinatario que falló en un envío múltiple;
+// Index es su posición en Recipients
+type BulkMessageError struct {
+	Index     int    `json:"index"`
+	Error     string `json:"error"`
+	Recipient TemplateMessageRecipient `json:"recipient"`
 }
 
 // Contact representa un contacto en la respuesta de mensaje
@@ -140,6 +222,7 @@ type InteractiveListMessageRequest struct {
 	Body           InteractiveBody       `json:"body"`
 	Footer         *InteractiveFooter    `json:"footer,omitempty"`
 	Action         InteractiveListAction `json:"action"`
+	ChannelNumber  string                `json:"channel_number,omitempty"`
 }
 
 // InteractiveButtonMessageRequest representa la petición para mensaje de botones interactivos
@@ -149,14 +232,28 @@ type InteractiveButtonMessageRequest struct {
 	Body           InteractiveBody         `json:"body"`
 	Footer         *InteractiveFooter      `json:"footer,omitempty"`
 	Action         InteractiveButtonAction `json:"action"`
+	ChannelNumber  string                  `json:"channel_number,omitempty"`
 }
 
-// InteractiveHeader representa el header de un mensaje interactivo
+// InteractiveHeader representa el header de un mensaje interactivo. Un header
+// lleva texto o una imagen, nunca ambos.
 type InteractiveHeader struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type  string                  `json:"type"`
+	Text  string                  `json:"text,omitempty"`
+	Image *InteractiveHeaderMedia `json:"image,omitempty"`
+}
+
+// InteractiveHeaderMedia representa el archivo de un header con media
+type InteractiveHeaderMedia struct {
+	Link string `json:"link"`
 }
 
+// Tipos de header de un mensaje interactivo
+const (
+	InteractiveHeaderTypeText  = "text"
+	InteractiveHeaderTypeImage = "image"
+)
+
 // InteractiveBody representa el cuerpo de un mensaje interactivo
 type InteractiveBody struct {
 	Text string `json:"text"`
@@ -167,6 +264,47 @@ type InteractiveFooter struct {
 	Text string `json:"text"`
 }
 
+// Límites impuestos por WhatsApp a los mensajes de lista interactiva
+const (
+	MaxListSections       = 10
+	MaxListRowsPerSection = 10
+)
+
+// Límites de caracteres que WhatsApp impone a cada campo de los mensajes
+// interactivos. Se cuentan caracteres, no bytes.
+const (
+	MaxInteractiveBodyLength    = 1024
+	MaxInteractiveHeaderLength  = 60
+	MaxInteractiveFooterLength  = 60
+	MaxInteractiveButtonLength  = 20
+	MaxListRowTitleLength       = 24
+	MaxListRowDescriptionLength = 72
+)
+
+// InteractiveCharacterLimits agrupa los límites de caracteres de los mensajes
+// interactivos, por ejemplo para mostrarlos en una interfaz antes de enviar
+type InteractiveCharacterLimits struct {
+	Body           int `json:"body"`
+	Header         int `json:"header"`
+	Footer         int `json:"footer"`
+	Button         int `json:"button"`
+	RowTitle       int `json:"rowTitle"`
+	RowDescription int `json:"rowDescription"`
+}
+
+// CharacterLimits retorna los límites de caracteres que aplican los
+// validadores de mensajes interactivos
+func CharacterLimits() InteractiveCharacterLimits {
+	return InteractiveCharacterLimits{
+		Body:           MaxInteractiveBodyLength,
+		Header:         MaxInteractiveHeaderLength,
+		Footer:         MaxInteractiveFooterLength,
+		Button:         MaxInteractiveButtonLength,
+		RowTitle:       MaxListRowTitleLength,
+		RowDescription: MaxListRowDescriptionLength,
+	}
+}
+
 // InteractiveListAction representa la acción de lista interactiva
 type InteractiveListAction struct {
 	Button   string                 `json:"button"`
@@ -197,12 +335,62 @@ type InteractiveButton struct {
 	Reply InteractiveButtonReply `json:"reply"`
 }
 
+// InteractiveButtonTypeReply es el único tipo de botón que WhatsApp admite en
+// mensajes de botones de respuesta rápida
+const InteractiveButtonTypeReply = "reply"
+
 // InteractiveButtonReply representa la respuesta de un botón interactivo
 type InteractiveButtonReply struct {
 	ID    string `json:"id"`
 	Title string `json:"title"`
 }
 
+// Tipos de mensajes interactivos de llamada a la acción (CTA)
+const (
+	InteractiveTypeCTAURL  = "cta_url"
+	InteractiveTypeCTACall = "cta_call"
+)
+
+// MaxCTAButtonTextLength es la longitud máxima del texto de un botón CTA
+const MaxCTAButtonTextLength = MaxInteractiveButtonLength
+
+// InteractiveCTAMessageRequest representa la petición para un mensaje con un
+// botón de llamada a la acción, que abre una URL o llama a un teléfono
+type InteractiveCTAMessageRequest struct {
+	WhatsappNumber string               `json:"whatsappNumber"`
+	Header         *InteractiveHeader   `json:"header,omitempty"`
+	Body           InteractiveBody      `json:"body"`
+	Footer         *InteractiveFooter   `json:"footer,omitempty"`
+	Action         InteractiveCTAAction `json:"action"`
+	ChannelNumber  string               `json:"channel_number,omitempty"`
+}
+
+// InteractiveCTAAction representa la acción de un botón CTA. Name es
+// InteractiveTypeCTAURL o InteractiveTypeCTACall.
+type InteractiveCTAAction struct {
+	Name       string                   `json:"name"`
+	Parameters InteractiveCTAParameters `json:"parameters"`
+}
+
+// InteractiveCTAParameters representa el texto y el destino de un botón CTA
+type InteractiveCTAParameters struct {
+	DisplayText string `json:"display_text"`
+	URL         string `json:"url,omitempty"`
+	PhoneNumber string `json:"phone_number,omitempty"`
+}
+
+// ProductMessageRequest representa la petición para enviar un mensaje de producto
+// (un solo producto) o de múltiples productos de un catálogo
+type ProductMessageRequest struct {
+	WhatsappNumber     string             `json:"whatsappNumber"`
+	CatalogID          string             `json:"catalogId"`
+	ProductRetailerIDs []string           `json:"productRetailerIds"`
+	Header             *InteractiveHeader `json:"header,omitempty"`
+	Body               *InteractiveBody   `json:"body,omitempty"`
+	Footer             *InteractiveFooter `json:"footer,omitempty"`
+	ChannelNumber      string             `json:"channel_number,omitempty"`
+}
+
 // Template representa una plantilla de mensaje
 type Template struct {
 	ID          string              `json:"id"`
@@ -211,6 +399,9 @@ type Template struct {
 	Status      string              `json:"status"`
 	Category    string              `json:"category"`
 	Components  []TemplateComponent `json:"components"`
+	
+	// RejectionReason explica por qué WhatsApp rechazó la plantilla (Status REJECTED)
+	RejectionReason string `json:"rejectionReason,omitempty"`
 	CreatedAt   string              `json:"createdAt"`
 	UpdatedAt   string              `json:"updatedAt"`
 }
@@ -267,6 +458,36 @@ type MessageStatus struct {
 	Error     string `json:"error,omitempty"`
 }
 
+// Estados de entrega de un mensaje
+const (
+	MessageStatusSent      = "sent"
+	MessageStatusDelivered = "delivered"
+	MessageStatusRead      = "read"
+	MessageStatusFailed    = "failed"
+)
+
+// IsDelivered indica si el mensaje fue entregado. Un mensaje leído también se
+// considera entregado.
+func (s *MessageStatus) IsDelivered() bool {
+	return strings.EqualFold(s.Status, MessageStatusDelivered) || s.IsRead()
+}
+
+// IsRead indica si el mensaje fue leído
+func (s *MessageStatus) IsRead() bool {
+	return strings.EqualFold(s.Status, MessageStatusRead)
+}
+
+// IsFailed indica si el envío del mensaje falló
+func (s *MessageStatus) IsFailed() bool {
+	return strings.EqualFold(s.Status, MessageStatusFailed)
+}
+
+// IsFinal indica si el estado ya no va a cambiar por la entrega: entregado,
+// leído o fallido
+func (s *MessageStatus) IsFinal() bool {
+	return s.IsDelivered() || s.IsFailed()
+}
+
 // BaseResponse representa la respuesta base de la API
 type BaseResponse struct {
 	Result  bool   `json:"result"`
@@ -275,14 +496,10 @@ type BaseResponse struct {
 }
 
 // PaginatedResponse representa una respuesta paginada
-type PaginatedResponse struct {
-	Page       int `json:"page"`
-	PageSize   int `json:"pageSize"`
-	TotalPages int `json:"totalPages"`
-	TotalCount int `json:"totalCount"`
-}
+type PaginatedResponse = common.PaginatedResponse
 
-// Validate valida la petición de mensaje de plantilla
+// Validate valida la petición de mensaje de plantilla y reemplaza
+// WhatsappNumber por su forma normalizada
 func (r *SendTemplateMessageRequest) Validate() error {
 	if r.WhatsappNumber == "" {
 		return fmt.Errorf("whatsappNumber is required")
@@ -296,15 +513,126 @@ func (r *SendTemplateMessageRequest) Validate() error {
 		return fmt.Errorf("broadcast_name is required")
 	}
 	
-	// Validación básica del número de teléfono
-	if len(r.WhatsappNumber) < 10 {
-		return fmt.Errorf("whatsappNumber must be at least 10 digits")
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+// Validate valida la petición de mensaje de sesión y reemplaza WhatsappNumber
+// por su forma normalizada
+func (r *SendSessionMessageRequest) Validate() error {
+	if r.WhatsappNumber == "" {
+		return fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	// WhatsApp rechaza los textos vacíos o formados solo por espacios
+	r.MessageText = strings.TrimSpace(r.MessageText)
+	if r.MessageText == "" {
+		return fmt.Errorf("messageText is required")
+	}
+	
+	if length := len([]rune(r.MessageText)); length > MaxSessionMessageLength {
+		return fmt.Errorf("messageText exceeds %d characters, got %d", MaxSessionMessageLength, length)
+	}
+	
+	return nil
+}
+
+// Validate valida la petición de archivo de sesión. El tipo MIME se deduce de
+// la extensión de FileName y el tamaño se verifica cuando File lo expone (por
+// ejemplo *os.File, *bytes.Reader o *strings.Reader); si no, se controla
+// durante el envío. WhatsappNumber queda normalizado.
+func (r *SendSessionFileRequest) Validate() error {
+	if r.WhatsappNumber == "" {
+		return fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	if r.File == nil {
+		return fmt.Errorf("file is required")
+	}
+	
+	if r.FileName == "" {
+		return fmt.Errorf("fileName is required")
+	}
+	
+	mediaType := r.MediaType()
+	if mimeType := r.MimeType(); !media.IsSupportedMimeType(mediaType, mimeType) {
+		return fmt.Errorf("unsupported file type %s for %s", mimeType, r.FileName)
+	}
+	
+	if size, ok := readerSize(r.File); ok {
+		if err := media.ValidateFileSize(mediaType, size); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// MimeType retorna el tipo MIME deducido de la extensión de FileName
+func (r *SendSessionFileRequest) MimeType() string {
+	return media.GetMimeTypeFromExtension(filepath.Ext(r.FileName))
+}
+
+// MediaType retorna el tipo de media correspondiente a MimeType
+func (r *SendSessionFileRequest) MediaType() media.MediaType {
+	return media.GetMediaTypeFromMimeType(r.MimeType())
+}
+
+// validateDateRange verifica que fromDate y toDate tengan el formato
+// MessageDateFormat y que fromDate no sea posterior a toDate
+func validateDateRange(fromDate, toDate string) error {
+	from, err := time.Parse(MessageDateFormat, fromDate)
+	if err != nil {
+		return fmt.Errorf("invalid fromDate %q: expected format YYYY-MM-DD", fromDate)
+	}
+	
+	to, err := time.Parse(MessageDateFormat, toDate)
+	if err != nil {
+		return fmt.Errorf("invalid toDate %q: expected format YYYY-MM-DD", toDate)
+	}
+	
+	if from.After(to) {
+		return fmt.Errorf("fromDate %s is after toDate %s", fromDate, toDate)
 	}
 	
 	return nil
 }
 
-// Validate valida la petición de múltiples mensajes de plantilla
+// readerSize retorna el tamaño de r si puede conocerse sin leerlo
+func readerSize(r io.Reader) (int64, bool) {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len()), true
+	case interface{ Size() int64 }:
+		return v.Size(), true
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+		return info.Size(), true
+	}
+	
+	return 0, false
+}
+
+// Validate valida la petición de múltiples mensajes de plantilla y reemplaza
+// el WhatsappNumber de cada destinatario por su forma normalizada
 func (r *SendTemplateMessagesRequest) Validate() error {
 	if r.TemplateName == "" {
 		return fmt.Errorf("template_name is required")
@@ -319,8 +647,8 @@ func (r *SendTemplateMessagesRequest) Validate() error {
 	}
 	
 	// WATI permite hasta 100 destinatarios por llamada
-	if len(r.Recipients) > 100 {
-		return fmt.Errorf("maximum 100 recipients allowed per request, got %d", len(r.Recipients))
+	if len(r.Recipients) > MaxRecipientsPerRequest {
+		return fmt.Errorf("maximum %d recipients allowed per request, got %d", MaxRecipientsPerRequest, len(r.Recipients))
 	}
 	
 	// Validar cada destinatario
@@ -329,24 +657,30 @@ func (r *SendTemplateMessagesRequest) Validate() error {
 			return fmt.Errorf("whatsappNumber is required for recipient %d", i)
 		}
 		
-		if len(recipient.WhatsappNumber) < 10 {
-			return fmt.Errorf("whatsappNumber must be at least 10 digits for recipient %d", i)
+		if err := common.NormalizeWhatsappNumber(&r.Recipients[i].WhatsappNumber); err != nil {
+			return fmt.Errorf("recipient %d: %w", i, err)
 		}
 	}
 	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
-// Validate valida la petición de mensaje de lista interactiva
+// Validate valida la petición de mensaje de lista interactiva y reemplaza
+// WhatsappNumber por su forma normalizada
 func (r *InteractiveListMessageRequest) Validate() error {
 	if r.WhatsappNumber == "" {
 		return fmt.Errorf("whatsappNumber is required")
 	}
 	
-	if len(r.WhatsappNumber) < 10 {
-		return fmt.Errorf("whatsappNumber must be at least 10 digits")
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
 	}
 	
+	r.Body.Text = strings.TrimSpace(r.Body.Text)
 	if r.Body.Text == "" {
 		return fmt.Errorf("body text is required")
 	}
@@ -355,10 +689,18 @@ func (r *InteractiveListMessageRequest) Validate() error {
 		return fmt.Errorf("action button text is required")
 	}
 	
+	if err := validateTextLength("action button text", r.Action.Button, MaxInteractiveButtonLength); err != nil {
+		return err
+	}
+	
 	if len(r.Action.Sections) == 0 {
 		return fmt.Errorf("at least one section is required")
 	}
 	
+	if len(r.Action.Sections) > MaxListSections {
+		return fmt.Errorf("maximum %d sections allowed, got %d", MaxListSections, len(r.Action.Sections))
+	}
+	
 	// Validar secciones
 	for i, section := range r.Action.Sections {
 		if section.Title == "" {
@@ -369,6 +711,10 @@ func (r *InteractiveListMessageRequest) Validate() error {
 			return fmt.Errorf("at least one row is required for section %d", i)
 		}
 		
+		if len(section.Rows) > MaxListRowsPerSection {
+			return fmt.Errorf("maximum %d rows allowed per section, got %d for section %d", MaxListRowsPerSection, len(section.Rows), i)
+		}
+		
 		// Validar filas
 		for j, row := range section.Rows {
 			if row.ID == "" {
@@ -378,22 +724,45 @@ func (r *InteractiveListMessageRequest) Validate() error {
 			if row.Title == "" {
 				return fmt.Errorf("row title is required for section %d, row %d", i, j)
 			}
+			
+			if err := validateTextLength(fmt.Sprintf("row title for section %d, row %d", i, j), row.Title, MaxListRowTitleLength); err != nil {
+				return err
+			}
+			
+			if err := validateTextLength(fmt.Sprintf("row description for section %d, row %d", i, j), row.Description, MaxListRowDescriptionLength); err != nil {
+				return err
+			}
 		}
 	}
 	
+	// WhatsApp solo admite headers de texto en los mensajes de lista
+	if r.Header != nil && r.Header.Type != InteractiveHeaderTypeText {
+		return fmt.Errorf("list messages only support %s headers, got %q", InteractiveHeaderTypeText, r.Header.Type)
+	}
+	
+	if err := validateInteractiveText(r.Header, r.Body.Text, r.Footer); err != nil {
+		return err
+	}
+	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
+	}
+	
 	return nil
 }
 
-// Validate valida la petición de mensaje de botones interactivos
+// Validate valida la petición de mensaje de botones interactivos y reemplaza
+// WhatsappNumber por su forma normalizada
 func (r *InteractiveButtonMessageRequest) Validate() error {
 	if r.WhatsappNumber == "" {
 		return fmt.Errorf("whatsappNumber is required")
 	}
 	
-	if len(r.WhatsappNumber) < 10 {
-		return fmt.Errorf("whatsappNumber must be at least 10 digits")
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
 	}
 	
+	r.Body.Text = strings.TrimSpace(r.Body.Text)
 	if r.Body.Text == "" {
 		return fmt.Errorf("body text is required")
 	}
@@ -415,11 +784,225 @@ func (r *InteractiveButtonMessageRequest) Validate() error {
 		if button.Reply.Title == "" {
 			return fmt.Errorf("button title is required for button %d", i)
 		}
+		
+		if err := validateTextLength(fmt.Sprintf("button title for button %d", i), button.Reply.Title, MaxInteractiveButtonLength); err != nil {
+			return err
+		}
+		
+		if button.Type != InteractiveButtonTypeReply {
+			return fmt.Errorf("button type must be %q for button %d, got %q", InteractiveButtonTypeReply, i, button.Type)
+		}
+	}
+	
+	if err := validateInteractiveText(r.Header, r.Body.Text, r.Footer); err != nil {
+		return err
+	}
+	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+// validateTextLength verifica que value no supere max caracteres
+func validateTextLength(field, value string, max int) error {
+	if length := len([]rune(value)); length > max {
+		return fmt.Errorf("%s exceeds maximum length of %d characters, got %d", field, max, length)
+	}
+	return nil
+}
+
+// validateInteractiveText verifica los límites de caracteres del header, el
+// cuerpo y el footer comunes a todos los mensajes interactivos
+func validateInteractiveText(header *InteractiveHeader, body string, footer *InteractiveFooter) error {
+	if err := validateInteractiveHeader(header); err != nil {
+		return err
+	}
+	
+	if err := validateTextLength("body text", body, MaxInteractiveBodyLength); err != nil {
+		return err
+	}
+	
+	if footer != nil {
+		if err := validateTextLength("footer text", footer.Text, MaxInteractiveFooterLength); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// validateMediaURL verifica que raw sea una URL http(s) absoluta
+func validateMediaURL(field, raw string) error {
+	if raw == "" {
+		return fmt.Errorf("%s is required", field)
+	}
+	
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fmt.Errorf("%s must be an absolute http(s) URL, got %q", field, raw)
+	}
+	
+	return nil
+}
+
+// validateInteractiveHeader valida que el header lleve texto o imagen según su
+// tipo, pero no ambos. Un header nil es válido.
+func validateInteractiveHeader(header *InteractiveHeader) error {
+	if header == nil {
+		return nil
+	}
+	
+	if err := validateTextLength("header text", header.Text, MaxInteractiveHeaderLength); err != nil {
+		return err
+	}
+	
+	if header.Text != "" && header.Image != nil {
+		return fmt.Errorf("header text and image are mutually exclusive")
+	}
+	
+	switch header.Type {
+	case InteractiveHeaderTypeText:
+		if header.Text == "" {
+			return fmt.Errorf("header text is required for a %s header", InteractiveHeaderTypeText)
+		}
+		
+	case InteractiveHeaderTypeImage:
+		if header.Image == nil || header.Image.Link == "" {
+			return fmt.Errorf("header image link is required for an %s header", InteractiveHeaderTypeImage)
+		}
+	}
+	
+	return nil
+}
+
+// Validate valida la petición de mensaje con botón CTA y reemplaza
+// WhatsappNumber por su forma normalizada
+func (r *InteractiveCTAMessageRequest) Validate() error {
+	if r.WhatsappNumber == "" {
+		return fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	r.Body.Text = strings.TrimSpace(r.Body.Text)
+	if r.Body.Text == "" {
+		return fmt.Errorf("body text is required")
+	}
+	
+	params := r.Action.Parameters
+	if params.DisplayText == "" {
+		return fmt.Errorf("button text is required")
+	}
+	
+	if err := validateTextLength("button text", params.DisplayText, MaxCTAButtonTextLength); err != nil {
+		return err
+	}
+	
+	switch r.Action.Name {
+	case InteractiveTypeCTAURL:
+		if params.PhoneNumber != "" {
+			return fmt.Errorf("phone number is not allowed in a %s button", InteractiveTypeCTAURL)
+		}
+		
+		parsed, err := url.Parse(params.URL)
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+			return fmt.Errorf("url must be a valid absolute URL, got %q", params.URL)
+		}
+		
+	case InteractiveTypeCTACall:
+		if params.URL != "" {
+			return fmt.Errorf("url is not allowed in a %s button", InteractiveTypeCTACall)
+		}
+		
+		if err := common.ValidateE164(params.PhoneNumber); err != nil {
+			return fmt.Errorf("invalid button phone number: %w", err)
+		}
+		
+	default:
+		return fmt.Errorf("action name must be %q or %q, got %q", InteractiveTypeCTAURL, InteractiveTypeCTACall, r.Action.Name)
+	}
+	
+	if err := validateInteractiveText(r.Header, r.Body.Text, r.Footer); err != nil {
+		return err
+	}
+	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
+	}
+	
+	return nil
+}
+
+// SuccessRate retorna la proporción (entre 0 y 1) de mensajes enviados con éxito
+func (r *BulkMessageResponse) SuccessRate() float64 {
+	total := r.SuccessCount + r.FailureCount
+	if total == 0 {
+		return 0
+	}
+	
+	return float64(r.SuccessCount) / float64(total)
+}
+
+// HasFailures indica si algún mensaje del envío múltiple falló
+func (r *BulkMessageResponse) HasFailures() bool {
+	return r.FailureCount > 0 || len(r.Errors) > 0
+}
+
+// Validate valida la petición de mensaje de producto y reemplaza
+// WhatsappNumber por su forma normalizada
+func (r *ProductMessageRequest) Validate() error {
+	if r.WhatsappNumber == "" {
+		return fmt.Errorf("whatsappNumber is required")
+	}
+	
+	if err := common.NormalizeWhatsappNumber(&r.WhatsappNumber); err != nil {
+		return err
+	}
+	
+	if r.CatalogID == "" {
+		return fmt.Errorf("catalogId is required")
+	}
+	
+	if len(r.ProductRetailerIDs) == 0 {
+		return fmt.Errorf("at least one product retailer ID is required")
+	}
+	
+	for i, id := range r.ProductRetailerIDs {
+		if id == "" {
+			return fmt.Errorf("product retailer ID is required for product %d", i)
+		}
+	}
+	
+	// WhatsApp exige texto en el cuerpo para mensajes de múltiples productos
+	if r.IsMultiProduct() && (r.Body == nil || strings.TrimSpace(r.Body.Text) == "") {
+		return fmt.Errorf("body text is required for multi-product messages")
+	}
+	
+	var bodyText string
+	if r.Body != nil {
+		bodyText = r.Body.Text
+	}
+	
+	if err := validateInteractiveText(r.Header, bodyText, r.Footer); err != nil {
+		return err
+	}
+	
+	if err := validateChannelNumber(&r.ChannelNumber); err != nil {
+		return err
 	}
 	
 	return nil
 }
 
+// IsMultiProduct indica si el mensaje incluye más de un producto
+func (r *ProductMessageRequest) IsMultiProduct() bool {
+	return len(r.ProductRetailerIDs) > 1
+}
+
 // ToMap convierte GetMessagesParams a un mapa para query parameters
 func (p *GetMessagesParams) ToMap() map[string]string {
 	params := make(map[string]string)
@@ -458,3 +1041,34 @@ func (p *GetMessagesParams) SetDefaults() {
 	}
 }
 
+// IsApproved indica si la plantilla fue aprobada
+func (t *Template) IsApproved() bool {
+	return strings.EqualFold(t.Status, "APPROVED")
+}
+
+// IsPending indica si la plantilla está pendiente de revisión
+func (t *Template) IsPending() bool {
+	return strings.EqualFold(t.Status, "PENDING")
+}
+
+// IsRejected indica si la plantilla fue rechazada; ver RejectionReason
+func (t *Template) IsRejected() bool {
+	return strings.EqualFold(t.Status, "REJECTED")
+}
+
+// validateChannelNumber valida y normaliza el número emisor opcional. El SDK no
+// expone el listado de canales de la cuenta, por lo que la existencia del canal
+// la verifica WATI al recibir la petición.
+func validateChannelNumber(channelNumber *string) error {
+	if *channelNumber == "" {
+		return nil
+	}
+	
+	normalized, err := common.ValidatePhoneNumber(*channelNumber)
+	if err != nil {
+		return fmt.Errorf("invalid channel_number: %w", err)
+	}
+	*channelNumber = normalized
+	
+	return nil
+}
@@ -0,0 +1,71 @@
+package wati
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// quotaCacheTTL es cuánto se reutiliza la cuota consultada antes de pedirla de nuevo
+const quotaCacheTTL = 30 * time.Second
+
+// quotaGuard implementa messages.QuotaChecker consultando GetAccountInfo y
+// cacheando el resultado durante ttl
+type quotaGuard struct {
+	fetch func(ctx context.Context) (*AccountInfo, error)
+	ttl   time.Duration
+	now   func() time.Time
+	
+	mutex     sync.Mutex
+	remaining int
+	fetchedAt time.Time
+	cached    bool
+}
+
+// newQuotaGuard crea un quotaGuard que obtiene la cuota con fetch
+func newQuotaGuard(fetch func(ctx context.Context) (*AccountInfo, error), ttl time.Duration) *quotaGuard {
+	return &quotaGuard{
+		fetch: fetch,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// RemainingQuota retorna la cuota restante de la cuenta, o -1 si no tiene un
+// límite conocido
+func (g *quotaGuard) RemainingQuota(ctx context.Context) (int, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	
+	if g.cached && g.now().Sub(g.fetchedAt) < g.ttl {
+		return g.remaining, nil
+	}
+	
+	info, err := g.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	
+	g.remaining = info.RemainingQuota()
+	g.fetchedAt = g.now()
+	g.cached = true
+	
+	return g.remaining, nil
+}
+
+// ConsumeQuota descuenta n mensajes de la cuota cacheada, para que los envíos
+// dentro del TTL no superen juntos la cuota consultada. Una cuota sin límite
+// conocido no se modifica.
+func (g *quotaGuard) ConsumeQuota(n int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	
+	if !g.cached || g.remaining < 0 {
+		return
+	}
+	
+	g.remaining -= n
+	if g.remaining < 0 {
+		g.remaining = 0
+	}
+}
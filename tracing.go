@@ -0,0 +1,64 @@
+package wati
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifica al SDK como scope de instrumentación
+const tracerName = "github.com/diogenes-moreira/wati-sdk"
+
+// requestSpan envuelve el span de una llamada a la API. Un *requestSpan nil
+// representa el tracing deshabilitado y todos sus métodos son no-op.
+type requestSpan struct {
+	span trace.Span
+}
+
+// startSpan inicia un span para la llamada si hay un TracerProvider configurado
+func (c *Client) startSpan(ctx context.Context, method, endpoint string) (context.Context, *requestSpan) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	
+	path, _, _ := strings.Cut(c.resolveEndpoint(ctx, endpoint), "?")
+	ctx, span := c.tracer.Start(ctx, "wati."+method+" "+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("http.method", method)),
+	)
+	
+	return ctx, &requestSpan{span: span}
+}
+
+// inject propaga el span actual en los headers de la petición saliente
+func (s *requestSpan) inject(req *http.Request) {
+	if s == nil {
+		return
+	}
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+}
+
+// end registra el resultado de la llamada y cierra el span
+func (s *requestSpan) end(statusCode, retries int, err error) {
+	if s == nil {
+		return
+	}
+	
+	if statusCode != 0 {
+		s.span.SetAttributes(attribute.Int("http.status_code", statusCode))
+	}
+	s.span.SetAttributes(attribute.Int("wati.retry_count", retries))
+	
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	
+	s.span.End()
+}
@@ -0,0 +1,161 @@
+package wati
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan guarda lo que el cliente registra en el span
+type recordingSpan struct {
+	noop.Span
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	err    error
+	ended  bool
+}
+
+func (s *recordingSpan) SpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func (s *recordingSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, description string) {
+	s.status = code
+}
+
+func (s *recordingSpan) RecordError(err error, options ...trace.EventOption) {
+	s.err = err
+}
+
+func (s *recordingSpan) End(options ...trace.SpanEndOption) {
+	s.ended = true
+}
+
+// recordingTracer crea recordingSpans y los acumula para inspeccionarlos
+type recordingTracer struct {
+	noop.Tracer
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordingSpan{name: name, attrs: make(map[attribute.Key]attribute.Value)}
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type recordingProvider struct {
+	noop.TracerProvider
+	tracer *recordingTracer
+}
+
+func (p *recordingProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return p.tracer
+}
+
+func TestClientTracing(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(previous)
+	
+	var traceparents []string
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"result": true}`))
+	}))
+	defer server.Close()
+	
+	provider := &recordingProvider{tracer: &recordingTracer{}}
+	client := NewClient(server.URL, "test-token", WithRetries(1), WithTracerProvider(provider))
+	
+	if err := client.DoRequest(context.Background(), "POST", "/api/v1/sendTemplateMessage?whatsappNumber=123", nil, nil); err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	
+	if len(provider.tracer.spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(provider.tracer.spans))
+	}
+	
+	span := provider.tracer.spans[0]
+	if span.name != "wati.POST /api/v1/sendTemplateMessage" {
+		t.Errorf("Unexpected span name %q", span.name)
+	}
+	if got := span.attrs["http.status_code"].AsInt64(); got != http.StatusOK {
+		t.Errorf("Expected http.status_code 200, got %d", got)
+	}
+	if got := span.attrs["wati.retry_count"].AsInt64(); got != 1 {
+		t.Errorf("Expected wati.retry_count 1, got %d", got)
+	}
+	if span.status == codes.Error || span.err != nil {
+		t.Error("Expected span without error")
+	}
+	if !span.ended {
+		t.Error("Expected span to be ended")
+	}
+	
+	for i, tp := range traceparents {
+		if tp == "" {
+			t.Errorf("Expected traceparent header on attempt %d", i+1)
+		}
+	}
+}
+
+func TestClientTracingRecordsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": "invalid number"}`))
+	}))
+	defer server.Close()
+	
+	provider := &recordingProvider{tracer: &recordingTracer{}}
+	client := NewClient(server.URL, "test-token", WithTracerProvider(provider))
+	
+	if err := client.DoRequest(context.Background(), "GET", "/api/v1/getContacts", nil, nil); err == nil {
+		t.Fatal("Expected error")
+	}
+	
+	span := provider.tracer.spans[0]
+	if span.status != codes.Error || span.err == nil {
+		t.Error("Expected span to record the error")
+	}
+	if got := span.attrs["http.status_code"].AsInt64(); got != http.StatusBadRequest {
+		t.Errorf("Expected http.status_code 400, got %d", got)
+	}
+}
+
+func TestClientTracingDisabled(t *testing.T) {
+	client, ok := NewClient("https://test.wati.io", "test-token").(*Client)
+	if !ok {
+		t.Fatal("NewClient() did not return *Client")
+	}
+	
+	if client.tracer != nil {
+		t.Error("Expected tracing to be disabled without a TracerProvider")
+	}
+}
@@ -2,6 +2,9 @@ package wati
 
 import (
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/messages"
+	"github.com/diogenes-moreira/wati-sdk/webhooks"
 )
 
 // BaseResponse representa la respuesta base de la API de WATI
@@ -64,35 +67,50 @@ type TokenResponse struct {
 	ExpiresAt time.Time `json:"expiresAt"`
 }
 
+// AccountInfo representa el plan y los límites de envío de la cuenta de WATI
+type AccountInfo struct {
+	BaseResponse
+	Plan            string `json:"plan"`
+	MessagingLimit  int    `json:"messagingLimit"`  // Conversaciones iniciadas por el negocio cada 24 horas
+	MessagesSent    int    `json:"messagesSent"`    // Conversaciones iniciadas en las últimas 24 horas
+	MessagingTier   string `json:"messagingTier,omitempty"`
+	QualityRating   string `json:"qualityRating,omitempty"`
+	ConnectedNumber string `json:"connectedNumber"`
+}
+
+// RemainingQuota retorna cuántas conversaciones puede iniciar todavía la cuenta
+// en la ventana actual, o -1 si la cuenta no informa un límite
+func (a *AccountInfo) RemainingQuota() int {
+	if a.MessagingLimit <= 0 {
+		return -1
+	}
+	
+	if remaining := a.MessagingLimit - a.MessagesSent; remaining > 0 {
+		return remaining
+	}
+	
+	return 0
+}
+
 // WebhookEventType representa el tipo de evento de webhook
-type WebhookEventType string
+type WebhookEventType = webhooks.WebhookEventType
 
 const (
-	MessageReceived       WebhookEventType = "message_received"
-	NewContactMessage     WebhookEventType = "new_contact_message"
-	SessionMessageSent    WebhookEventType = "session_message_sent"
-	TemplateMessageSent   WebhookEventType = "template_message_sent"
-	MessageDelivered      WebhookEventType = "message_delivered"
-	MessageRead           WebhookEventType = "message_read"
-	MessageReplied        WebhookEventType = "message_replied"
-	TemplateMessageFailed WebhookEventType = "template_message_failed"
+	MessageReceived       = webhooks.MessageReceived
+	NewContactMessage     = webhooks.NewContactMessage
+	SessionMessageSent    = webhooks.SessionMessageSent
+	TemplateMessageSent   = webhooks.TemplateMessageSent
+	MessageDelivered      = webhooks.MessageDelivered
+	MessageRead           = webhooks.MessageRead
+	MessageReplied        = webhooks.MessageReplied
+	TemplateMessageFailed = webhooks.TemplateMessageFailed
 )
 
 // WebhookEvent representa un evento de webhook
-type WebhookEvent struct {
-	Type      WebhookEventType `json:"type"`
-	Timestamp string           `json:"timestamp"`
-	Data      interface{}      `json:"data"`
-}
+type WebhookEvent = webhooks.WebhookEvent
 
 // WebhookHandler es una función que maneja eventos de webhook
-type WebhookHandler func(event *WebhookEvent) error
+type WebhookHandler = webhooks.WebhookHandler
 
 // MessageStatus representa el estado de un mensaje
-type MessageStatus struct {
-	ID        string `json:"id"`
-	Status    string `json:"status"`
-	Timestamp string `json:"timestamp"`
-	Error     string `json:"error,omitempty"`
-}
-
+type MessageStatus = messages.MessageStatus
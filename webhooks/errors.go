@@ -0,0 +1,87 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrQueueFull indica que la cola del modo asíncrono no aceptó el evento
+var ErrQueueFull = errors.New("webhook queue full")
+
+// ErrInvalidSignature indica que la firma del evento no coincide con el secreto
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrSignatureRequired indica que se exige firma pero no hay secreto configurado
+// para verificarla
+var ErrSignatureRequired = errors.New("webhook signature required but no secret is configured")
+
+// ErrPermanentFailure marca un error de handler que no se resuelve reintentando,
+// por ejemplo un rechazo de negocio. El servidor responde 200 para que WATI no
+// reenvíe el evento. Los handlers lo envuelven con %w:
+//
+//	return fmt.Errorf("%w: order %s not found", webhooks.ErrPermanentFailure, id)
+var ErrPermanentFailure = errors.New("permanent webhook handler failure")
+
+// ErrDeliveryAlreadySucceeded indica que se pidió reenviar una entrega de
+// webhook que WATI ya había entregado con éxito
+var ErrDeliveryAlreadySucceeded = errors.New("webhook delivery already succeeded")
+
+// ReplayError indica que un evento fue rechazado por estar fuera de la ventana
+// de tolerancia configurada con SetMaxEventAge
+type ReplayError struct {
+	Code      int    `json:"code"`
+	Message   string `json:"message"`
+	EventID   string `json:"eventId"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Error implementa la interfaz error
+func (e *ReplayError) Error() string {
+	return fmt.Sprintf("webhook replay rejected %d: %s", e.Code, e.Message)
+}
+
+// IsAuthenticationError indica que el evento debe responderse con 401
+func (e *ReplayError) IsAuthenticationError() bool {
+	return e.Code == http.StatusUnauthorized
+}
+
+// newReplayError crea un ReplayError para el evento indicado
+func newReplayError(event *WebhookEvent, message string) *ReplayError {
+	return &ReplayError{
+		Code:      http.StatusUnauthorized,
+		Message:   message,
+		EventID:   event.ID,
+		Timestamp: event.Timestamp,
+	}
+}
+
+// isPermanentFailure indica si todos los errores de handler de err están
+// marcados con ErrPermanentFailure. Basta un error transitorio para que valga
+// la pena que WATI reintente.
+func isPermanentFailure(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs := joined.Unwrap()
+		for _, e := range errs {
+			if !isPermanentFailure(e) {
+				return false
+			}
+		}
+		return len(errs) > 0
+	}
+	
+	return errors.Is(err, ErrPermanentFailure)
+}
+
+// HandlerPanicError indica que un handler de webhook entró en pánico al
+// procesar un evento
+type HandlerPanicError struct {
+	EventType WebhookEventType `json:"eventType"`
+	EventID   string           `json:"eventId"`
+	Value     interface{}      `json:"-"`
+}
+
+// Error implementa la interfaz error
+func (e *HandlerPanicError) Error() string {
+	return fmt.Sprintf("webhook handler panicked on event %s (%s): %v", e.EventID, e.EventType, e.Value)
+}
@@ -0,0 +1,108 @@
+package webhooks
+
+import (
+	"sync"
+	"time"
+)
+
+// InteractionTracker registra localmente a qué mensajes interactivos ya
+// respondió cada contacto. WhatsApp no permite editar ni deshabilitar botones
+// después de enviados, así que los handlers pueden usarlo para ignorar toques
+// repetidos sobre el mismo mensaje. Los registros expiran después del TTL.
+type InteractionTracker struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	responses map[interactionKey]time.Time
+	now       func() time.Time
+}
+
+type interactionKey struct {
+	contact   string
+	messageID string
+}
+
+// NewInteractionTracker crea un tracker cuyos registros expiran después de ttl.
+// Un ttl <= 0 conserva los registros indefinidamente.
+func NewInteractionTracker(ttl time.Duration) *InteractionTracker {
+	return &InteractionTracker{
+		ttl:       ttl,
+		responses: make(map[interactionKey]time.Time),
+		now:       time.Now,
+	}
+}
+
+// Record registra la respuesta de contact al mensaje interactivo messageID.
+// Retorna true si el contacto ya había respondido a ese mensaje y el registro
+// no expiró, es decir, si se trata de un toque duplicado.
+func (t *InteractionTracker) Record(contact, messageID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	now := t.now()
+	t.pruneLocked(now)
+	
+	key := interactionKey{contact: contact, messageID: messageID}
+	if _, exists := t.responses[key]; exists {
+		return true
+	}
+	
+	t.responses[key] = now
+	return false
+}
+
+// RecordReply registra una respuesta de botón o lista recibida por webhook y
+// retorna true si es duplicada, usando el ID del mensaje interactivo original
+// que el webhook incluye en Context. Sin Context.ID no es posible saber a qué
+// mensaje responde (los IDs de opción como "yes" se repiten entre mensajes),
+// así que la respuesta no se registra ni se considera duplicada. Los mensajes
+// que no son respuestas interactivas nunca son duplicados.
+func (t *InteractionTracker) RecordReply(data *MessageReceivedData) bool {
+	if data == nil || !(data.IsButtonReply() || data.IsListReply()) {
+		return false
+	}
+	
+	if data.Context == nil || data.Context.ID == "" {
+		return false
+	}
+	
+	return t.Record(data.From, data.Context.ID)
+}
+
+// HasResponded verifica si contact ya respondió al mensaje messageID
+func (t *InteractionTracker) HasResponded(contact, messageID string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	recordedAt, exists := t.responses[interactionKey{contact: contact, messageID: messageID}]
+	return exists && !t.expired(recordedAt, t.now())
+}
+
+// Forget elimina el registro de una respuesta para volver a aceptarla
+func (t *InteractionTracker) Forget(contact, messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	delete(t.responses, interactionKey{contact: contact, messageID: messageID})
+}
+
+// Len retorna la cantidad de respuestas registradas que no expiraron
+func (t *InteractionTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	
+	t.pruneLocked(t.now())
+	return len(t.responses)
+}
+
+// pruneLocked elimina los registros expirados; requiere t.mu tomado
+func (t *InteractionTracker) pruneLocked(now time.Time) {
+	for key, recordedAt := range t.responses {
+		if t.expired(recordedAt, now) {
+			delete(t.responses, key)
+		}
+	}
+}
+
+func (t *InteractionTracker) expired(recordedAt, now time.Time) bool {
+	return t.ttl > 0 && now.Sub(recordedAt) >= t.ttl
+}
@@ -3,13 +3,19 @@ package webhooks
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,17 +29,82 @@ type Service struct {
 	client HTTPClient
 	server *WebhookServer
 	mutex  sync.RWMutex
+	
+	// Cola y workers del modo asíncrono; queue es nil en modo síncrono.
+	// stopping se activa antes de cerrar la cola y enqueues cuenta los envíos
+	// en curso, que StopWebhookServer espera antes del cierre.
+	queue    chan *WebhookEvent
+	stopping bool
+	enqueues sync.WaitGroup
+	workers  sync.WaitGroup
+	dropped  atomic.Uint64
+	
+	// skipWarned evita repetir en cada evento el aviso de firma sin verificar;
+	// SetSecret lo reinicia
+	skipWarned atomic.Bool
+}
+
+// defaultQueueSize es la capacidad por defecto de la cola del modo asíncrono
+const defaultQueueSize = 100
+
+// Option configura el servicio de webhooks
+type Option func(*Service)
+
+// WithSignatureAlgo establece el algoritmo y la codificación de las firmas de
+// webhook. Por defecto se usa HMAC-SHA256 en hexadecimal.
+func WithSignatureAlgo(algo SignatureAlgo, encoding SignatureEncoding) Option {
+	return func(s *Service) {
+		s.server.Signature = SignatureConfig{
+			Algo:     algo,
+			Encoding: encoding,
+		}
+	}
+}
+
+// WithTLSConfig sirve los webhooks por HTTPS con la configuración TLS indicada,
+// que debe proveer los certificados (Certificates o GetCertificate, por ejemplo
+// de autocert). Aplica a todos los modos de inicio del servidor.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(s *Service) {
+		s.server.TLSConfig = config
+	}
+}
+
+// WithQueueSize establece la capacidad de la cola del modo asíncrono
+func WithQueueSize(size int) Option {
+	return func(s *Service) {
+		s.server.QueueSize = size
+	}
+}
+
+// WithQueueFullPolicy establece qué hacer con los eventos cuando la cola del
+// modo asíncrono está llena. Por defecto se responde 503 (QueueFullReject).
+func WithQueueFullPolicy(policy QueueFullPolicy) Option {
+	return func(s *Service) {
+		s.server.QueueFullPolicy = policy
+	}
 }
 
 // NewService crea una nueva instancia del servicio de webhooks
-func NewService(client HTTPClient) *Service {
-	return &Service{
+func NewService(client HTTPClient, options ...Option) *Service {
+	service := &Service{
 		client: client,
 		server: &WebhookServer{
-			Handlers:  make(map[WebhookEventType]WebhookHandler),
+			Handlers:  make(map[WebhookEventType][]WebhookHandler),
 			IsRunning: false,
+			Signature: DefaultSignatureConfig(),
+			QueueSize: defaultQueueSize,
+			QueueFullPolicy: QueueFullPolicy{
+				Action: QueueFullReject,
+			},
 		},
 	}
+	
+	for _, option := range options {
+		option(service)
+	}
+	
+	return service
 }
 
 // RegisterWebhook registra un webhook en WATI
@@ -97,48 +168,220 @@ func (s *Service) ListWebhooks(ctx context.Context) (*WebhooksResponse, error) {
 	return &response, nil
 }
 
+// conflictError lo implementa *wati.WATIError; se declara aquí para no
+// depender del paquete raíz
+type conflictError interface {
+	IsConflictError() bool
+}
+
+// RetryWebhookDelivery pide a WATI que reenvíe al webhookURL una entrega que
+// falló. Si la entrega ya se había completado (o la API responde 409) retorna
+// un error que envuelve ErrDeliveryAlreadySucceeded.
+func (s *Service) RetryWebhookDelivery(ctx context.Context, webhookURL, deliveryID string) error {
+	if webhookURL == "" {
+		return fmt.Errorf("webhook URL is required")
+	}
+	
+	if _, err := url.ParseRequestURI(webhookURL); err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	
+	if deliveryID == "" {
+		return fmt.Errorf("delivery ID is required")
+	}
+	
+	request := &RedeliveryRequest{
+		URL:        webhookURL,
+		DeliveryID: deliveryID,
+	}
+	
+	var response RedeliveryResponse
+	err := s.client.DoRequest(ctx, "POST", "/api/v1/webhooks/redeliver", request, &response)
+	if err != nil {
+		var conflict conflictError
+		if errors.As(err, &conflict) && conflict.IsConflictError() {
+			return fmt.Errorf("%w: %s: %v", ErrDeliveryAlreadySucceeded, deliveryID, err)
+		}
+		return fmt.Errorf("error retrying webhook delivery %s: %w", deliveryID, err)
+	}
+	
+	if strings.EqualFold(response.Status, "delivered") || strings.EqualFold(response.Status, "succeeded") {
+		return fmt.Errorf("%w: %s", ErrDeliveryAlreadySucceeded, deliveryID)
+	}
+	
+	if !response.Result && response.Error != "" {
+		return fmt.Errorf("error retrying webhook delivery %s: %s", deliveryID, response.Error)
+	}
+	
+	return nil
+}
+
 // HandleWebhook procesa un evento de webhook
 func (s *Service) HandleWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	event, err := s.verifyEvent(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := s.dispatchEvent(event); err != nil {
+		return event, err
+	}
+	
+	return event, nil
+}
+
+// verifyEvent parsea el evento y aplica la validación de firma y antigüedad
+func (s *Service) verifyEvent(payload []byte, signature string) (*WebhookEvent, error) {
 	// Parsear el evento
 	event, err := ParseWebhookEvent(payload)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing webhook event: %w", err)
 	}
 	
-	// Validar firma si hay un secreto configurado
+	// Validar firma
 	s.mutex.RLock()
 	secret := s.server.Secret
+	requireSignature := s.server.RequireSignature
+	maxEventAge := s.server.MaxEventAge
+	signatureConfig := s.server.Signature
 	s.mutex.RUnlock()
 	
-	if !ValidateSignature(payload, signature, secret) {
-		return nil, fmt.Errorf("invalid webhook signature")
+	switch VerifySignatureWithConfig(payload, signature, secret, signatureConfig) {
+	case SignatureInvalid:
+		return nil, ErrInvalidSignature
+	case SignatureSkipped:
+		if requireSignature {
+			return nil, ErrSignatureRequired
+		}
+		if s.skipWarned.CompareAndSwap(false, true) {
+			log.Printf("Webhook signature check skipped: no secret configured")
+		}
+	}
+	
+	// Rechazar eventos fuera de la ventana de tolerancia
+	if maxEventAge > 0 {
+		if err := checkEventAge(event, maxEventAge, time.Now()); err != nil {
+			return nil, err
+		}
 	}
 	
-	// Ejecutar handler si existe
+	return event, nil
+}
+
+// dispatchEvent ejecuta los handlers del evento en orden de registro, o el
+// handler por defecto si el tipo de evento no tiene handlers
+func (s *Service) dispatchEvent(event *WebhookEvent) error {
 	s.mutex.RLock()
-	handler, exists := s.server.Handlers[event.Type]
+	handlers := s.server.Handlers[event.Type]
+	if len(handlers) == 0 && s.server.DefaultHandler != nil {
+		handlers = []WebhookHandler{s.server.DefaultHandler}
+	}
 	s.mutex.RUnlock()
 	
-	if exists && handler != nil {
-		if err := handler(event); err != nil {
-			return event, fmt.Errorf("error executing webhook handler: %w", err)
+	var errs []error
+	for _, handler := range handlers {
+		if handler == nil {
+			continue
+		}
+		if err := runHandler(handler, event); err != nil {
+			errs = append(errs, fmt.Errorf("error executing webhook handler: %w", err))
 		}
 	}
 	
-	return event, nil
+	return errors.Join(errs...)
+}
+
+// runHandler ejecuta un handler convirtiendo un pánico en HandlerPanicError para
+// que un handler defectuoso no tire abajo el servidor
+func runHandler(handler WebhookHandler, event *WebhookEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Webhook handler panicked on event %s (%s): %v", event.ID, event.Type, r)
+			err = &HandlerPanicError{
+				EventType: event.Type,
+				EventID:   event.ID,
+				Value:     r,
+			}
+		}
+	}()
+	
+	return handler(event)
+}
+
+// checkEventAge verifica que el Timestamp RFC3339 del evento esté dentro de
+// maxAge respecto de now, en cualquier dirección
+func checkEventAge(event *WebhookEvent, maxAge time.Duration, now time.Time) error {
+	timestamp, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		return newReplayError(event, fmt.Sprintf("invalid event timestamp %q", event.Timestamp))
+	}
+	
+	age := now.Sub(timestamp)
+	if age > maxAge || age < -maxAge {
+		return newReplayError(event, fmt.Sprintf("event timestamp %s is outside the %s tolerance window", event.Timestamp, maxAge))
+	}
+	
+	return nil
 }
 
-// ValidateWebhookSignature valida la firma de un webhook
+// ValidateWebhookSignature valida la firma de un webhook. Retorna false si no
+// hay secreto configurado.
 func (s *Service) ValidateWebhookSignature(payload []byte, signature string) bool {
 	s.mutex.RLock()
 	secret := s.server.Secret
+	signatureConfig := s.server.Signature
 	s.mutex.RUnlock()
 	
-	return ValidateSignature(payload, signature, secret)
+	return VerifySignatureWithConfig(payload, signature, secret, signatureConfig) == SignatureValid
 }
 
-// StartWebhookServer inicia el servidor de webhooks
+// StartWebhookServer inicia el servidor de webhooks. Sirve HTTPS si se
+// configuró TLS con WithTLSConfig o SetTLSConfig.
 func (s *Service) StartWebhookServer(port int, handlers map[WebhookEventType]WebhookHandler) error {
+	return s.startServer(port, nil, nil, handlers)
+}
+
+// StartWebhookServerTLS inicia el servidor de webhooks por HTTPS con el
+// certificado y la clave PEM indicados. WATI solo entrega webhooks a endpoints
+// HTTPS, así que este es el modo habitual sin un proxy que termine TLS. Los
+// archivos se cargan antes de iniciar, para reportar errores de inmediato.
+func (s *Service) StartWebhookServerTLS(port int, certFile, keyFile string, handlers map[WebhookEventType]WebhookHandler) error {
+	if certFile == "" || keyFile == "" {
+		return fmt.Errorf("certFile and keyFile are required")
+	}
+	
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("error loading TLS certificate: %w", err)
+	}
+	
+	// Partir de la configuración TLS del servicio, si la hay, sin modificarla
+	s.mutex.RLock()
+	config := s.server.TLSConfig.Clone()
+	s.mutex.RUnlock()
+	
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config.Certificates = []tls.Certificate{cert}
+	
+	return s.startServer(port, nil, config, handlers)
+}
+
+// StartWebhookServerWithListener inicia el servidor de webhooks sobre un
+// listener provisto por el llamador, por ejemplo uno en memoria para tests. El
+// listener se cierra al detener el servidor.
+func (s *Service) StartWebhookServerWithListener(listener net.Listener, handlers map[WebhookEventType]WebhookHandler) error {
+	if listener == nil {
+		return fmt.Errorf("listener is required")
+	}
+	
+	return s.startServer(0, listener, nil, handlers)
+}
+
+// startServer inicia el servidor síncrono en port o, si no es nil, en listener.
+// Con tlsConfig nil se usa la configuración TLS del servicio, si la hay.
+func (s *Service) startServer(port int, listener net.Listener, tlsConfig *tls.Config, handlers map[WebhookEventType]WebhookHandler) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	
@@ -146,16 +389,162 @@ func (s *Service) StartWebhookServer(port int, handlers map[WebhookEventType]Web
 		return fmt.Errorf("webhook server is already running")
 	}
 	
-	// Configurar handlers
+	if s.stopping {
+		return fmt.Errorf("webhook server is stopping")
+	}
+	
+	if tlsConfig == nil {
+		tlsConfig = s.server.TLSConfig
+	}
+	
+	// Configurar handlers copiando el mapa del llamador, para que modificarlo
+	// después no compita con el despacho de eventos
 	if handlers != nil {
-		s.server.Handlers = handlers
+		s.server.Handlers = make(map[WebhookEventType][]WebhookHandler, len(handlers))
+		for eventType, handler := range handlers {
+			if handler != nil {
+				s.server.Handlers[eventType] = []WebhookHandler{handler}
+			}
+		}
+	}
+	
+	s.listen(port, listener, tlsConfig)
+	return nil
+}
+
+// StartWebhookServerAsync inicia el servidor de webhooks en modo asíncrono: cada
+// evento se valida, se encola y se responde 200 de inmediato, y un pool fijo de
+// workers ejecuta los handlers. El comportamiento con la cola llena se configura
+// con WithQueueFullPolicy; por defecto se responde 503 para que WATI reintente.
+func (s *Service) StartWebhookServerAsync(port, workers int) error {
+	return s.startServerAsync(port, nil, workers)
+}
+
+// StartWebhookServerAsyncWithListener inicia el servidor asíncrono de webhooks
+// sobre un listener provisto por el llamador. Ver StartWebhookServerAsync.
+func (s *Service) StartWebhookServerAsyncWithListener(listener net.Listener, workers int) error {
+	if listener == nil {
+		return fmt.Errorf("listener is required")
+	}
+	
+	return s.startServerAsync(0, listener, workers)
+}
+
+// startServerAsync inicia el servidor asíncrono en port o, si no es nil, en listener
+func (s *Service) startServerAsync(port int, listener net.Listener, workers int) error {
+	if workers <= 0 {
+		return fmt.Errorf("workers must be greater than 0")
+	}
+	
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	if s.server.IsRunning {
+		return fmt.Errorf("webhook server is already running")
+	}
+	
+	if s.stopping {
+		return fmt.Errorf("webhook server is stopping")
+	}
+	
+	queueSize := s.server.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+	
+	s.startWorkers(workers, queueSize)
+	s.listen(port, listener, s.server.TLSConfig)
+	return nil
+}
+
+// startWorkers crea la cola de eventos y lanza los workers. Requiere s.mutex tomado.
+func (s *Service) startWorkers(workers, queueSize int) {
+	s.queue = make(chan *WebhookEvent, queueSize)
+	
+	for i := 0; i < workers; i++ {
+		s.workers.Add(1)
+		go func(queue <-chan *WebhookEvent) {
+			defer s.workers.Done()
+			for event := range queue {
+				if err := s.dispatchEvent(event); err != nil {
+					log.Printf("Error handling webhook event %s (%s): %v", event.ID, event.Type, err)
+				}
+			}
+		}(s.queue)
+	}
+}
+
+// enqueueEvent encola un evento aplicando la política de cola llena. Retorna
+// ErrQueueFull si el evento debe rechazarse; un evento descartado no es un error.
+func (s *Service) enqueueEvent(queue chan *WebhookEvent, event *WebhookEvent) error {
+	select {
+	case queue <- event:
+		return nil
+	default:
+	}
+	
+	s.mutex.RLock()
+	policy := s.server.QueueFullPolicy
+	s.mutex.RUnlock()
+	
+	switch policy.Action {
+	case QueueFullDrop:
+		s.dropped.Add(1)
+		log.Printf("Webhook queue full, dropping event %s (%s)", event.ID, event.Type)
+		return nil
+		
+	case QueueFullBlock:
+		timer := time.NewTimer(policy.Timeout)
+		defer timer.Stop()
+		
+		select {
+		case queue <- event:
+			return nil
+		case <-timer.C:
+		}
 	}
 	
+	log.Printf("Webhook queue full, rejecting event %s (%s)", event.ID, event.Type)
+	return ErrQueueFull
+}
+
+// QueueDepth retorna la cantidad de eventos esperando en la cola del modo asíncrono
+func (s *Service) QueueDepth() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	
+	return len(s.queue)
+}
+
+// DroppedEvents retorna la cantidad de eventos descartados por la política QueueFullDrop
+func (s *Service) DroppedEvents() uint64 {
+	return s.dropped.Load()
+}
+
+// drainWorkers cierra la cola y espera a que los workers procesen los eventos pendientes
+func (s *Service) drainWorkers(queue chan *WebhookEvent) {
+	if queue == nil {
+		return
+	}
+	
+	close(queue)
+	s.workers.Wait()
+}
+
+// listen crea el servidor HTTP y lo inicia en una goroutine, escuchando en port
+// o sirviendo sobre listener si no es nil. Con tlsConfig sirve HTTPS con sus
+// certificados. Requiere s.mutex tomado.
+func (s *Service) listen(port int, listener net.Listener, tlsConfig *tls.Config) {
+	if listener != nil {
+		if addr, ok := listener.Addr().(*net.TCPAddr); ok {
+			port = addr.Port
+		}
+	}
 	s.server.Port = port
 	
 	// Crear servidor HTTP
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook", s.handleWebhookRequest)
+	mux.HandleFunc("/webhook", s.WebhookHandler())
 	mux.HandleFunc("/health", s.handleHealthCheck)
 	
 	s.server.server = &http.Server{
@@ -164,51 +553,115 @@ func (s *Service) StartWebhookServer(port int, handlers map[WebhookEventType]Web
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		TLSConfig:    tlsConfig,
 	}
 	
-	// Iniciar servidor en goroutine
-	go func() {
-		log.Printf("Starting webhook server on port %d", port)
-		if err := s.server.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	// Iniciar servidor en goroutine. Los certificados salen de TLSConfig, por
+	// lo que ServeTLS y ListenAndServeTLS no reciben archivos.
+	go func(server *http.Server) {
+		var err error
+		switch {
+		case listener != nil && tlsConfig != nil:
+			log.Printf("Starting webhook server with TLS on %s", listener.Addr())
+			err = server.ServeTLS(listener, "", "")
+		case listener != nil:
+			log.Printf("Starting webhook server on %s", listener.Addr())
+			err = server.Serve(listener)
+		case tlsConfig != nil:
+			log.Printf("Starting webhook server with TLS on port %d", port)
+			err = server.ListenAndServeTLS("", "")
+		default:
+			log.Printf("Starting webhook server on port %d", port)
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Printf("Webhook server error: %v", err)
 		}
-	}()
+	}(s.server.server)
 	
 	s.server.IsRunning = true
-	return nil
 }
 
-// StopWebhookServer detiene el servidor de webhooks
+// stopWebhookTimeout es la espera máxima de StopWebhookServer por las peticiones en curso
+var stopWebhookTimeout = 30 * time.Second
+
+// StopWebhookServer detiene el servidor de webhooks. En modo asíncrono espera a
+// que los workers procesen los eventos ya encolados. Si las peticiones en curso
+// no terminan a tiempo retorna un error y el servidor sigue marcado como en
+// ejecución, para poder reintentar la detención. Mientras se drena la cola, un
+// WebhookHandler montado en otro mux responde 503 para que WATI reintente.
 func (s *Service) StopWebhookServer() error {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	
 	if !s.server.IsRunning {
+		s.mutex.Unlock()
 		return fmt.Errorf("webhook server is not running")
 	}
 	
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Las peticiones en curso necesitan el mutex, por lo que se libera antes del Shutdown
+	server := s.server.server
+	queue := s.queue
+	s.mutex.Unlock()
+	
+	ctx, cancel := context.WithTimeout(context.Background(), stopWebhookTimeout)
 	defer cancel()
 	
-	if err := s.server.server.Shutdown(ctx); err != nil {
+	if err := server.Shutdown(ctx); err != nil {
 		return fmt.Errorf("error stopping webhook server: %w", err)
 	}
 	
+	// Solo una detención concurrente drena la cola
+	s.mutex.Lock()
+	if !s.server.IsRunning || s.server.server != server {
+		s.mutex.Unlock()
+		return fmt.Errorf("webhook server is not running")
+	}
 	s.server.IsRunning = false
+	s.stopping = queue != nil
+	s.mutex.Unlock()
+	
+	// Las peticiones de un WebhookHandler montado en otro mux no las cubre el
+	// Shutdown: desde aquí se rechazan, y se esperan los envíos ya iniciados
+	// antes de cerrar la cola
+	s.enqueues.Wait()
+	s.drainWorkers(queue)
+	
+	s.mutex.Lock()
+	s.queue = nil
+	s.stopping = false
+	s.mutex.Unlock()
+	
 	log.Println("Webhook server stopped")
 	return nil
 }
 
-// RegisterHandler registra un handler para un tipo de evento específico
+// RegisterHandler agrega un handler para un tipo de evento específico. Los
+// handlers de un mismo tipo se ejecutan en orden de registro.
 func (s *Service) RegisterHandler(eventType WebhookEventType, handler WebhookHandler) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	
-	s.server.Handlers[eventType] = handler
+	s.server.Handlers[eventType] = append(s.server.Handlers[eventType], handler)
 }
 
-// UnregisterHandler desregistra un handler
+// RegisterDefaultHandler registra un handler que se ejecuta para cualquier evento
+// cuyo tipo no tenga handlers específicos, incluidos tipos que el SDK no conoce.
+// El JSON original de los datos está disponible en WebhookEvent.RawData.
+func (s *Service) RegisterDefaultHandler(handler WebhookHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.DefaultHandler = handler
+}
+
+// UnregisterHandler desregistra los handlers de un tipo de evento
+//
+// Deprecated: usar UnregisterAllHandlers.
 func (s *Service) UnregisterHandler(eventType WebhookEventType) {
+	s.UnregisterAllHandlers(eventType)
+}
+
+// UnregisterAllHandlers desregistra todos los handlers de un tipo de evento
+func (s *Service) UnregisterAllHandlers(eventType WebhookEventType) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	
@@ -221,6 +674,56 @@ func (s *Service) SetSecret(secret string) {
 	defer s.mutex.Unlock()
 	
 	s.server.Secret = secret
+	s.skipWarned.Store(false)
+}
+
+// SetRequireSignature obliga a rechazar los eventos cuya firma no pueda
+// verificarse, incluido el caso de no tener secreto configurado
+func (s *Service) SetRequireSignature(require bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.RequireSignature = require
+}
+
+// SetSignatureAlgo cambia el algoritmo y la codificación de las firmas esperadas
+func (s *Service) SetSignatureAlgo(algo SignatureAlgo, encoding SignatureEncoding) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.Signature = SignatureConfig{
+		Algo:     algo,
+		Encoding: encoding,
+	}
+}
+
+// SetTLSConfig establece la configuración TLS con la que se inicia el servidor
+// de webhooks. Ver WithTLSConfig. Nil vuelve a servir HTTP plano.
+func (s *Service) SetTLSConfig(config *tls.Config) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.TLSConfig = config
+}
+
+// SetSignatureHeader cambia el header del que se lee la firma de los eventos
+// recibidos y en el que TestWebhookEvent la envía. El nombre no distingue
+// mayúsculas. Vacío restablece el comportamiento por defecto: X-Webhook-Signature
+// con X-Hub-Signature-256 como alternativa.
+func (s *Service) SetSignatureHeader(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.SignatureHeader = strings.TrimSpace(name)
+}
+
+// SetMaxEventAge habilita la protección contra replay rechazando los eventos
+// cuyo Timestamp se aleje de la hora actual más de d. Cero la deshabilita.
+func (s *Service) SetMaxEventAge(d time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	
+	s.server.MaxEventAge = d
 }
 
 // GetServerStatus obtiene el estado del servidor
@@ -239,6 +742,18 @@ func (s *Service) GetServerPort() int {
 	return s.server.Port
 }
 
+// WebhookHandler retorna el handler HTTP que procesa los webhooks (lectura,
+// verificación de firma y despacho) sin iniciar un servidor propio, para
+// montarlo en el router de la aplicación:
+//
+//	mux.HandleFunc("/wati/webhook", service.WebhookHandler())
+//
+// Los eventos se despachan de forma síncrona salvo que el servidor asíncrono
+// del servicio esté en ejecución.
+func (s *Service) WebhookHandler() http.HandlerFunc {
+	return s.handleWebhookRequest
+}
+
 // handleWebhookRequest maneja las peticiones de webhook
 func (s *Service) handleWebhookRequest(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -255,20 +770,72 @@ func (s *Service) handleWebhookRequest(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 	
-	// Obtener firma del header
-	signature := r.Header.Get("X-Webhook-Signature")
-	if signature == "" {
-		signature = r.Header.Get("X-Hub-Signature-256")
+	s.mutex.RLock()
+	signatureHeader := s.server.SignatureHeader
+	s.mutex.RUnlock()
+	
+	// Obtener firma del header configurado o, por defecto, de los conocidos
+	var signature string
+	if signatureHeader != "" {
+		signature = r.Header.Get(signatureHeader)
+	} else {
+		signature = r.Header.Get(SignatureHeader)
+		if signature == "" {
+			signature = r.Header.Get(HubSignatureHeader)
+		}
 	}
 	
-	// Procesar webhook
-	event, err := s.HandleWebhook(body, signature)
+	// Verificar el evento: firma y antigüedad se responden con 401 y los
+	// payloads inválidos con 400, ya que reintentar no los resuelve
+	event, err := s.verifyEvent(body, signature)
 	if err != nil {
 		log.Printf("Error handling webhook: %v", err)
-		http.Error(w, "Error processing webhook", http.StatusBadRequest)
+		
+		var replayErr *ReplayError
+		switch {
+		case errors.As(err, &replayErr):
+			http.Error(w, "Stale webhook event", http.StatusUnauthorized)
+		case errors.Is(err, ErrInvalidSignature), errors.Is(err, ErrSignatureRequired):
+			http.Error(w, "Invalid webhook signature", http.StatusUnauthorized)
+		default:
+			http.Error(w, "Error processing webhook", http.StatusBadRequest)
+		}
 		return
 	}
 	
+	// Tomar la cola y registrar el envío bajo el mismo lock con el que
+	// StopWebhookServer activa stopping, para no enviar a una cola cerrada
+	s.mutex.RLock()
+	queue, stopping := s.queue, s.stopping
+	if queue != nil && !stopping {
+		s.enqueues.Add(1)
+	}
+	s.mutex.RUnlock()
+	
+	if stopping {
+		http.Error(w, "Webhook server stopping", http.StatusServiceUnavailable)
+		return
+	}
+	
+	if queue != nil {
+		// En modo asíncrono encolar el evento según la política de cola llena
+		err := s.enqueueEvent(queue, event)
+		s.enqueues.Done()
+		if err != nil {
+			http.Error(w, "Webhook queue full", http.StatusServiceUnavailable)
+			return
+		}
+	} else if err := s.dispatchEvent(event); err != nil {
+		log.Printf("Error handling webhook event %s (%s): %v", event.ID, event.Type, err)
+		
+		// Los errores permanentes se confirman para evitar reenvíos inútiles; el
+		// resto, incluidos los pánicos, se responde con 500 para que WATI reintente
+		if !isPermanentFailure(err) {
+			http.Error(w, "Internal error processing webhook", http.StatusInternalServerError)
+			return
+		}
+	}
+	
 	// Responder con éxito
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
@@ -294,13 +861,26 @@ func (s *Service) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
 		"server": map[string]interface{}{
 			"port":      s.GetServerPort(),
 			"running":   s.GetServerStatus(),
-			"handlers":  len(s.server.Handlers),
+			"handlers":  s.handlerCount(),
 		},
 	}
 	
 	json.NewEncoder(w).Encode(response)
 }
 
+// handlerCount retorna el total de handlers registrados
+func (s *Service) handlerCount() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	
+	count := 0
+	for _, handlers := range s.server.Handlers {
+		count += len(handlers)
+	}
+	
+	return count
+}
+
 // CreateMessageHandler crea un handler para mensajes recibidos
 func CreateMessageHandler(handler func(data MessageReceivedData) error) WebhookHandler {
 	return func(event *WebhookEvent) error {
@@ -394,12 +974,65 @@ func (s *Service) RegisterAllEventHandlers(handler WebhookHandler) {
 	}
 }
 
-// TestWebhook envía un evento de prueba al webhook
+// TestWebhook envía un evento message_received de prueba al webhook, firmado
+// igual que TestWebhookEvent.
 func (s *Service) TestWebhook(ctx context.Context, webhookURL string) error {
-	testEvent := &WebhookEvent{
-		ID:        "test-" + strconv.FormatInt(time.Now().Unix(), 10),
-		Type:      MessageReceived,
-		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	return s.TestWebhookEvent(ctx, webhookURL, defaultTestEvent())
+}
+
+// TestWebhookEvent envía el evento indicado al webhook. ID y Timestamp se
+// completan si están vacíos. Si el servicio tiene un secreto configurado, el
+// payload se firma con el algoritmo configurado en el header que verifica
+// StartWebhookServer (SetSignatureHeader o, por defecto, SignatureHeader).
+func (s *Service) TestWebhookEvent(ctx context.Context, webhookURL string, event *WebhookEvent) error {
+	if event == nil {
+		return fmt.Errorf("event is required")
+	}
+	
+	if event.Type == "" {
+		return fmt.Errorf("event type is required")
+	}
+	
+	// Completar los campos vacíos sin modificar el evento del llamador
+	testEvent := *event
+	if testEvent.ID == "" {
+		testEvent.ID = "test-" + strconv.FormatInt(time.Now().Unix(), 10)
+	}
+	if testEvent.Timestamp == "" {
+		testEvent.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	
+	// Convertir a JSON
+	payload, err := json.Marshal(&testEvent)
+	if err != nil {
+		return fmt.Errorf("error marshaling test event: %w", err)
+	}
+	
+	s.mutex.RLock()
+	secret := s.server.Secret
+	signatureConfig := s.server.Signature
+	signatureHeader := s.server.SignatureHeader
+	s.mutex.RUnlock()
+	
+	if signatureHeader == "" {
+		signatureHeader = SignatureHeader
+	}
+	
+	var signature string
+	if secret != "" {
+		signature, err = ComputeSignatureWithConfig(payload, secret, signatureConfig)
+		if err != nil {
+			return fmt.Errorf("error signing test event: %w", err)
+		}
+	}
+	
+	return sendTestWebhook(ctx, webhookURL, payload, signatureHeader, signature)
+}
+
+// defaultTestEvent construye el evento message_received que envía TestWebhook
+func defaultTestEvent() *WebhookEvent {
+	return &WebhookEvent{
+		Type: MessageReceived,
 		Data: MessageReceivedData{
 			MessageID:   "test-message-id",
 			From:        "1234567890",
@@ -411,24 +1044,64 @@ func (s *Service) TestWebhook(ctx context.Context, webhookURL string) error {
 		Source:  "wati-webhook-test",
 		Version: "1.0",
 	}
+}
+
+// Configuración de reintentos para el envío de webhooks de prueba
+var (
+	testWebhookMaxAttempts = 3
+	testWebhookTimeout     = 10 * time.Second
+	testWebhookBackoff     = 500 * time.Millisecond
+)
+
+// sendTestWebhook envía el payload al webhook reintentando con backoff exponencial
+// ante errores de red y respuestas transitorias (5xx o 429). La firma, si no
+// está vacía, se envía en el header indicado.
+func sendTestWebhook(ctx context.Context, webhookURL string, payload []byte, header, signature string) error {
+	httpClient := &http.Client{Timeout: testWebhookTimeout}
 	
-	// Convertir a JSON
-	payload, err := json.Marshal(testEvent)
-	if err != nil {
-		return fmt.Errorf("error marshaling test event: %w", err)
-	}
-	
-	// Enviar petición HTTP al webhook
-	resp, err := http.Post(webhookURL, "application/json", bytes.NewReader(payload))
-	if err != nil {
-		return fmt.Errorf("error sending test webhook: %w", err)
-	}
-	defer resp.Body.Close()
-	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("webhook test failed with status: %d", resp.StatusCode)
+	var lastErr error
+	for attempt := 0; attempt < testWebhookMaxAttempts; attempt++ {
+		if attempt > 0 {
+			// Esperar antes del reintento duplicando el intervalo en cada intento
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(testWebhookBackoff << uint(attempt-1)):
+			}
+		}
+		
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("error creating test webhook request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set(header, signature)
+		}
+		
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = fmt.Errorf("error sending test webhook: %w", err)
+			continue
+		}
+		
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		
+		lastErr = fmt.Errorf("webhook test failed with status: %d", resp.StatusCode)
+		
+		// Los errores del cliente no se resuelven reintentando
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return lastErr
+		}
 	}
 	
-	return nil
+	return fmt.Errorf("webhook test failed after %d attempts: %w", testWebhookMaxAttempts, lastErr)
 }
-
@@ -0,0 +1,1624 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// MockHTTPClient implementa HTTPClient para testing
+type MockHTTPClient struct {
+	DoRequestFunc func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error
+}
+
+func (m *MockHTTPClient) DoRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	if m.DoRequestFunc != nil {
+		return m.DoRequestFunc(ctx, method, endpoint, body, result)
+	}
+	return nil
+}
+
+func TestTestWebhookRetriesTransientFailure(t *testing.T) {
+	testWebhookBackoff = 10 * time.Millisecond
+	defer func() { testWebhookBackoff = 500 * time.Millisecond }()
+	
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fallar la primera petición, éxito en la segunda
+		if atomic.AddInt32(&requestCount, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	
+	service := NewService(nil)
+	
+	err := service.TestWebhook(context.Background(), server.URL)
+	if err != nil {
+		t.Errorf("TestWebhook() error = %v", err)
+	}
+	
+	if count := atomic.LoadInt32(&requestCount); count != 2 {
+		t.Errorf("Expected 2 requests (1 retry), got %d", count)
+	}
+}
+
+func TestTestWebhookReportsFinalStatus(t *testing.T) {
+	testWebhookBackoff = 10 * time.Millisecond
+	defer func() { testWebhookBackoff = 500 * time.Millisecond }()
+	
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	
+	service := NewService(nil)
+	
+	err := service.TestWebhook(context.Background(), server.URL)
+	if err == nil {
+		t.Fatal("Expected error but got nil")
+	}
+	
+	if got := err.Error(); !strings.Contains(got, "503") {
+		t.Errorf("Expected error to contain final status 503, got %q", got)
+	}
+}
+
+// sign calcula la firma HMAC-SHA256 en hexadecimal de un payload
+func sign(payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	secret := "test-secret"
+	signature := sign(payload, secret)
+	
+	tests := []struct {
+		name      string
+		signature string
+		secret    string
+		want      SignatureResult
+	}{
+		{name: "valid hex", signature: signature, secret: secret, want: SignatureValid},
+		{name: "valid with sha256 prefix", signature: "sha256=" + signature, secret: secret, want: SignatureValid},
+		{name: "wrong signature", signature: sign(payload, "other"), secret: secret, want: SignatureInvalid},
+		{name: "no secret", signature: "", secret: "", want: SignatureSkipped},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(payload, tt.signature, tt.secret); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	
+	if ValidateSignature(payload, "", "") {
+		t.Error("ValidateSignature() should not report an unchecked signature as valid")
+	}
+}
+
+func TestHandleWebhookSignature(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	
+	tests := []struct {
+		name      string
+		secret    string
+		require   bool
+		signature string
+		wantErr   bool
+	}{
+		{name: "no secret accepted", wantErr: false},
+		{name: "no secret rejected when required", require: true, wantErr: true},
+		{name: "valid signature", secret: "test-secret", require: true, signature: "sha256=" + sign(payload, "test-secret"), wantErr: false},
+		{name: "invalid signature", secret: "test-secret", signature: "bad", wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil)
+			service.SetSecret(tt.secret)
+			service.SetRequireSignature(tt.require)
+			
+			_, err := service.HandleWebhook(payload, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HandleWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookReplayProtection(t *testing.T) {
+	now := time.Now().UTC()
+	
+	tests := []struct {
+		name      string
+		timestamp string
+		wantErr   bool
+	}{
+		{name: "recent event", timestamp: now.Add(-time.Minute).Format(time.RFC3339), wantErr: false},
+		{name: "stale event", timestamp: now.Add(-10 * time.Minute).Format(time.RFC3339), wantErr: true},
+		{name: "future event", timestamp: now.Add(10 * time.Minute).Format(time.RFC3339), wantErr: true},
+		{name: "invalid timestamp", timestamp: "yesterday", wantErr: true},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil)
+			service.SetMaxEventAge(5 * time.Minute)
+			
+			payload := []byte(`{"id":"evt_1","type":"message_received","timestamp":"` + tt.timestamp + `"}`)
+			_, err := service.HandleWebhook(payload, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HandleWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			
+			if err == nil {
+				return
+			}
+			
+			replayErr, ok := err.(*ReplayError)
+			if !ok {
+				t.Fatalf("Expected *ReplayError, got %T", err)
+			}
+			
+			if !replayErr.IsAuthenticationError() || replayErr.EventID != "evt_1" {
+				t.Errorf("Unexpected replay error %+v", replayErr)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookRequestStaleEvent(t *testing.T) {
+	service := NewService(nil)
+	service.SetMaxEventAge(5 * time.Minute)
+	
+	stale := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	body := strings.NewReader(`{"id":"evt_1","type":"message_received","timestamp":"` + stale + `"}`)
+	
+	recorder := httptest.NewRecorder()
+	service.handleWebhookRequest(recorder, httptest.NewRequest(http.MethodPost, "/webhook", body))
+	
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", recorder.Code)
+	}
+}
+
+func TestHandleWebhookSignatureAlgo(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	secret := "test-secret"
+	
+	sha256Mac := hmac.New(sha256.New, []byte(secret))
+	sha256Mac.Write(payload)
+	sha1Mac := hmac.New(sha1.New, []byte(secret))
+	sha1Mac.Write(payload)
+	
+	tests := []struct {
+		name      string
+		algo      SignatureAlgo
+		encoding  SignatureEncoding
+		signature string
+		wantErr   bool
+	}{
+		{
+			name:      "base64 sha256",
+			algo:      SignatureSHA256,
+			encoding:  SignatureBase64,
+			signature: base64.StdEncoding.EncodeToString(sha256Mac.Sum(nil)),
+		},
+		{
+			name:      "hex sha1",
+			algo:      SignatureSHA1,
+			encoding:  SignatureHex,
+			signature: "sha1=" + hex.EncodeToString(sha1Mac.Sum(nil)),
+		},
+		{
+			name:      "hex sha256 under sha1 config",
+			algo:      SignatureSHA1,
+			encoding:  SignatureHex,
+			signature: sign(payload, secret),
+			wantErr:   true,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil, WithSignatureAlgo(tt.algo, tt.encoding))
+			service.SetSecret(secret)
+			
+			_, err := service.HandleWebhook(payload, tt.signature)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HandleWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookMultipleHandlers(t *testing.T) {
+	service := NewService(nil)
+	
+	var calls []string
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		calls = append(calls, "analytics")
+		return nil
+	})
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		calls = append(calls, "auto-reply")
+		return errors.New("auto-reply failed")
+	})
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		calls = append(calls, "crm")
+		return errors.New("crm failed")
+	})
+	
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	_, err := service.HandleWebhook(payload, "")
+	if err == nil {
+		t.Fatal("Expected aggregated handler error")
+	}
+	
+	if got := strings.Join(calls, ","); got != "analytics,auto-reply,crm" {
+		t.Errorf("Expected handlers to run in registration order, got %s", got)
+	}
+	
+	if !strings.Contains(err.Error(), "auto-reply failed") || !strings.Contains(err.Error(), "crm failed") {
+		t.Errorf("Expected both handler errors, got %v", err)
+	}
+	
+	service.UnregisterAllHandlers(MessageReceived)
+	calls = nil
+	
+	if _, err := service.HandleWebhook(payload, ""); err != nil {
+		t.Errorf("HandleWebhook() error = %v", err)
+	}
+	
+	if len(calls) != 0 {
+		t.Errorf("Expected no handlers after UnregisterAllHandlers, got %v", calls)
+	}
+}
+
+func TestHandleWebhookRequestRecoversHandlerPanic(t *testing.T) {
+	service := NewService(nil)
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		var data *MessageReceivedData
+		_ = data.Text // nil pointer dereference
+		return nil
+	})
+	
+	var called bool
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		called = true
+		return nil
+	})
+	
+	payload := `{"id":"evt_1","type":"message_received"}`
+	
+	for i := 0; i < 2; i++ {
+		recorder := httptest.NewRecorder()
+		service.handleWebhookRequest(recorder, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(payload)))
+		
+		if recorder.Code != http.StatusInternalServerError {
+			t.Errorf("Request %d: expected status 500, got %d", i+1, recorder.Code)
+		}
+	}
+	
+	if !called {
+		t.Error("Expected handlers after the panicking one to still run")
+	}
+	
+	_, err := service.HandleWebhook([]byte(payload), "")
+	var panicErr *HandlerPanicError
+	if !errors.As(err, &panicErr) {
+		t.Fatalf("Expected *HandlerPanicError, got %v", err)
+	}
+	
+	if panicErr.EventID != "evt_1" || panicErr.EventType != MessageReceived {
+		t.Errorf("Unexpected panic error %+v", panicErr)
+	}
+}
+
+func TestHandleWebhookRequestAsync(t *testing.T) {
+	service := NewService(nil)
+	
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var processed int32
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		started <- struct{}{}
+		<-release
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	
+	service.mutex.Lock()
+	service.startWorkers(1, 1)
+	queue := service.queue
+	service.mutex.Unlock()
+	
+	send := func() int {
+		recorder := httptest.NewRecorder()
+		body := strings.NewReader(`{"id":"evt_1","type":"message_received"}`)
+		service.handleWebhookRequest(recorder, httptest.NewRequest(http.MethodPost, "/webhook", body))
+		return recorder.Code
+	}
+	
+	// El primer evento lo toma el worker, que queda bloqueado en el handler
+	if code := send(); code != http.StatusOK {
+		t.Fatalf("Expected 200 for first event, got %d", code)
+	}
+	<-started
+	
+	// El segundo ocupa la cola y el tercero la encuentra llena
+	if code := send(); code != http.StatusOK {
+		t.Errorf("Expected 200 for queued event, got %d", code)
+	}
+	if code := send(); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 when queue is full, got %d", code)
+	}
+	
+	close(release)
+	service.drainWorkers(queue)
+	
+	if got := atomic.LoadInt32(&processed); got != 2 {
+		t.Errorf("Expected 2 processed events after drain, got %d", got)
+	}
+}
+
+func TestStartWebhookServerAsyncValidation(t *testing.T) {
+	service := NewService(nil)
+	
+	if err := service.StartWebhookServerAsync(0, 0); err == nil {
+		t.Error("Expected error for zero workers")
+	}
+}
+
+func TestHandleWebhookDefaultHandler(t *testing.T) {
+	service := NewService(nil)
+	
+	var specific, fallback []WebhookEventType
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		specific = append(specific, event.Type)
+		return nil
+	})
+	
+	var rawData string
+	service.RegisterDefaultHandler(func(event *WebhookEvent) error {
+		fallback = append(fallback, event.Type)
+		rawData = string(event.RawData)
+		return nil
+	})
+	
+	if _, err := service.HandleWebhook([]byte(`{"id":"evt_1","type":"message_received","data":{"text":"hi"}}`), ""); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+	
+	if _, err := service.HandleWebhook([]byte(`{"id":"evt_2","type":"order_placed","data":{"orderId":"42"}}`), ""); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+	
+	if len(specific) != 1 || specific[0] != MessageReceived {
+		t.Errorf("Expected specific handler for message_received only, got %v", specific)
+	}
+	
+	if len(fallback) != 1 || fallback[0] != "order_placed" {
+		t.Errorf("Expected default handler for unknown event only, got %v", fallback)
+	}
+	
+	if rawData != `{"orderId":"42"}` {
+		t.Errorf("Expected raw data to be preserved, got %s", rawData)
+	}
+}
+
+// saturatedQueue retorna un servicio cuya cola asíncrona de capacidad 1 está llena
+func saturatedQueue(t *testing.T, policy QueueFullPolicy) (*Service, chan *WebhookEvent) {
+	t.Helper()
+	
+	service := NewService(nil, WithQueueSize(1), WithQueueFullPolicy(policy))
+	queue := make(chan *WebhookEvent, service.server.QueueSize)
+	queue <- &WebhookEvent{ID: "evt_0"}
+	service.queue = queue
+	
+	return service, queue
+}
+
+func TestEnqueueEventBlockPolicy(t *testing.T) {
+	policy := QueueFullPolicy{Action: QueueFullBlock, Timeout: 50 * time.Millisecond}
+	
+	t.Run("space frees up before timeout", func(t *testing.T) {
+		service, queue := saturatedQueue(t, policy)
+		
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			<-queue
+		}()
+		
+		if err := service.enqueueEvent(queue, &WebhookEvent{ID: "evt_1"}); err != nil {
+			t.Errorf("enqueueEvent() error = %v", err)
+		}
+		
+		if depth := service.QueueDepth(); depth != 1 {
+			t.Errorf("Expected queue depth 1, got %d", depth)
+		}
+	})
+	
+	t.Run("timeout rejects event", func(t *testing.T) {
+		service, queue := saturatedQueue(t, policy)
+		
+		start := time.Now()
+		err := service.enqueueEvent(queue, &WebhookEvent{ID: "evt_1"})
+		if !errors.Is(err, ErrQueueFull) {
+			t.Errorf("Expected ErrQueueFull, got %v", err)
+		}
+		
+		if elapsed := time.Since(start); elapsed < policy.Timeout {
+			t.Errorf("Expected to block for %s, returned after %s", policy.Timeout, elapsed)
+		}
+	})
+}
+
+func TestEnqueueEventDropPolicy(t *testing.T) {
+	service, queue := saturatedQueue(t, QueueFullPolicy{Action: QueueFullDrop})
+	
+	for i := 0; i < 3; i++ {
+		if err := service.enqueueEvent(queue, &WebhookEvent{ID: "evt_1"}); err != nil {
+			t.Errorf("enqueueEvent() error = %v", err)
+		}
+	}
+	
+	if dropped := service.DroppedEvents(); dropped != 3 {
+		t.Errorf("Expected 3 dropped events, got %d", dropped)
+	}
+	
+	if depth := service.QueueDepth(); depth != 1 {
+		t.Errorf("Expected queue depth 1, got %d", depth)
+	}
+}
+
+func TestWebhookEventTypeCategories(t *testing.T) {
+	tests := []struct {
+		eventType WebhookEventType
+		message   bool
+		status    bool
+		contact   bool
+		chatbot   bool
+	}{
+		{eventType: MessageReceived, message: true},
+		{eventType: TemplateMessageFailed, message: true},
+		{eventType: MessageDelivered, status: true},
+		{eventType: MessageRead, status: true},
+		{eventType: ContactCreated, contact: true},
+		{eventType: ContactUpdated, contact: true},
+		{eventType: ChatbotStarted, chatbot: true},
+		{eventType: ChatStatusChanged, chatbot: true},
+		{eventType: "order_placed"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(string(tt.eventType), func(t *testing.T) {
+			if got := tt.eventType.IsMessageEvent(); got != tt.message {
+				t.Errorf("IsMessageEvent() = %v, want %v", got, tt.message)
+			}
+			if got := tt.eventType.IsStatusEvent(); got != tt.status {
+				t.Errorf("IsStatusEvent() = %v, want %v", got, tt.status)
+			}
+			if got := tt.eventType.IsContactEvent(); got != tt.contact {
+				t.Errorf("IsContactEvent() = %v, want %v", got, tt.contact)
+			}
+			if got := tt.eventType.IsChatbotEvent(); got != tt.chatbot {
+				t.Errorf("IsChatbotEvent() = %v, want %v", got, tt.chatbot)
+			}
+			
+			wantValid := tt.message || tt.status || tt.contact || tt.chatbot
+			if got := tt.eventType.IsValid(); got != wantValid {
+				t.Errorf("IsValid() = %v, want %v", got, wantValid)
+			}
+		})
+	}
+}
+
+func TestStartWebhookServerCopiesHandlers(t *testing.T) {
+	service := NewService(nil)
+	
+	var calls int32
+	handlers := map[WebhookEventType]WebhookHandler{
+		MessageReceived: func(event *WebhookEvent) error {
+			atomic.AddInt32(&calls, 1)
+			return nil
+		},
+	}
+	
+	if err := service.StartWebhookServer(0, handlers); err != nil {
+		t.Fatalf("StartWebhookServer() error = %v", err)
+	}
+	defer service.StopWebhookServer()
+	
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	done := make(chan struct{})
+	
+	// Mutar el mapa del llamador mientras se despachan eventos
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			handlers[MessageReceived] = nil
+			handlers[ContactCreated] = func(event *WebhookEvent) error { return nil }
+			delete(handlers, ContactCreated)
+		}
+	}()
+	
+	for i := 0; i < 100; i++ {
+		if _, err := service.HandleWebhook(payload, ""); err != nil {
+			t.Fatalf("HandleWebhook() error = %v", err)
+		}
+	}
+	<-done
+	
+	if got := atomic.LoadInt32(&calls); got != 100 {
+		t.Errorf("Expected the original handler to run 100 times, got %d", got)
+	}
+}
+
+func TestStartWebhookServerNilHandlers(t *testing.T) {
+	service := NewService(nil)
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error { return nil })
+	
+	if err := service.StartWebhookServer(0, nil); err != nil {
+		t.Fatalf("StartWebhookServer() error = %v", err)
+	}
+	defer service.StopWebhookServer()
+	
+	if got := service.handlerCount(); got != 1 {
+		t.Errorf("Expected registered handlers to be kept with a nil map, got %d", got)
+	}
+}
+
+func TestInteractionTracker(t *testing.T) {
+	tracker := NewInteractionTracker(time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+	
+	if tracker.Record("1234567890", "msg-1") {
+		t.Error("Expected first response not to be a duplicate")
+	}
+	
+	if !tracker.Record("1234567890", "msg-1") {
+		t.Error("Expected second response to be a duplicate")
+	}
+	
+	if tracker.Record("0987654321", "msg-1") {
+		t.Error("Expected response from another contact not to be a duplicate")
+	}
+	
+	if !tracker.HasResponded("1234567890", "msg-1") {
+		t.Error("Expected HasResponded to be true")
+	}
+	
+	now = now.Add(time.Minute)
+	
+	if tracker.HasResponded("1234567890", "msg-1") {
+		t.Error("Expected response to expire after the TTL")
+	}
+	
+	if tracker.Record("1234567890", "msg-1") {
+		t.Error("Expected response after expiry not to be a duplicate")
+	}
+	
+	if tracker.Len() != 1 {
+		t.Errorf("Expected expired records to be pruned, got %d", tracker.Len())
+	}
+}
+
+func TestInteractionTrackerRecordReply(t *testing.T) {
+	tracker := NewInteractionTracker(time.Minute)
+	
+	reply := &MessageReceivedData{
+		From:        "1234567890",
+		MessageType: "interactive",
+		Interactive: &WebhookInteractiveInfo{
+			Type:        "button_reply",
+			ButtonReply: &WebhookButtonReply{ID: "yes", Title: "Sí"},
+		},
+		Context: &WebhookMessageContext{ID: "msg-1"},
+	}
+	
+	if tracker.RecordReply(reply) {
+		t.Error("Expected first tap not to be a duplicate")
+	}
+	
+	// Otro botón del mismo mensaje también es un toque repetido
+	reply.Interactive.ButtonReply = &WebhookButtonReply{ID: "no", Title: "No"}
+	if !tracker.RecordReply(reply) {
+		t.Error("Expected second tap on the same message to be a duplicate")
+	}
+	
+	text := &MessageReceivedData{From: "1234567890", MessageType: "text", Text: "hola"}
+	if tracker.RecordReply(text) || tracker.RecordReply(text) {
+		t.Error("Expected text messages never to be duplicates")
+	}
+	
+	// Sin Context el mismo ID de opción puede responder a otro mensaje
+	noContext := &MessageReceivedData{
+		From:        "1234567890",
+		MessageType: "interactive",
+		Interactive: &WebhookInteractiveInfo{
+			Type:        "button_reply",
+			ButtonReply: &WebhookButtonReply{ID: "yes", Title: "Sí"},
+		},
+	}
+	if tracker.RecordReply(noContext) || tracker.RecordReply(noContext) {
+		t.Error("Expected replies without context never to be duplicates")
+	}
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	var gotMethod, gotEndpoint string
+	var gotBody *RedeliveryRequest
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			gotMethod = method
+			gotEndpoint = endpoint
+			gotBody = body.(*RedeliveryRequest)
+			result.(*RedeliveryResponse).Result = true
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	err := service.RetryWebhookDelivery(context.Background(), "https://example.com/webhook", "dlv-123")
+	if err != nil {
+		t.Fatalf("RetryWebhookDelivery() error = %v", err)
+	}
+	
+	if gotMethod != "POST" || gotEndpoint != "/api/v1/webhooks/redeliver" {
+		t.Errorf("Unexpected request %s %s", gotMethod, gotEndpoint)
+	}
+	
+	payload, _ := json.Marshal(gotBody)
+	if string(payload) != `{"url":"https://example.com/webhook","deliveryId":"dlv-123"}` {
+		t.Errorf("Unexpected payload %s", payload)
+	}
+}
+
+func TestRetryWebhookDeliveryAlreadySucceeded(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			response := result.(*RedeliveryResponse)
+			response.Status = "delivered"
+			return nil
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	err := service.RetryWebhookDelivery(context.Background(), "https://example.com/webhook", "dlv-123")
+	if !errors.Is(err, ErrDeliveryAlreadySucceeded) {
+		t.Errorf("Expected ErrDeliveryAlreadySucceeded, got %v", err)
+	}
+}
+
+// conflictAPIError simula el *wati.WATIError de una respuesta 409
+type conflictAPIError struct{}
+
+func (conflictAPIError) Error() string         { return "API error 409: delivery already completed" }
+func (conflictAPIError) IsConflictError() bool { return true }
+
+func TestRetryWebhookDeliveryConflict(t *testing.T) {
+	mockClient := &MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			return fmt.Errorf("error making request: %w", conflictAPIError{})
+		},
+	}
+	
+	service := NewService(mockClient)
+	
+	err := service.RetryWebhookDelivery(context.Background(), "https://example.com/webhook", "dlv-123")
+	if !errors.Is(err, ErrDeliveryAlreadySucceeded) {
+		t.Errorf("Expected ErrDeliveryAlreadySucceeded, got %v", err)
+	}
+}
+
+func TestRetryWebhookDeliveryValidation(t *testing.T) {
+	service := NewService(&MockHTTPClient{
+		DoRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+			t.Error("Expected no request for invalid input")
+			return nil
+		},
+	})
+	
+	tests := []struct {
+		url        string
+		deliveryID string
+	}{
+		{url: "", deliveryID: "dlv-123"},
+		{url: "not a url", deliveryID: "dlv-123"},
+		{url: "https://example.com/webhook", deliveryID: ""},
+	}
+	
+	for _, tt := range tests {
+		if err := service.RetryWebhookDelivery(context.Background(), tt.url, tt.deliveryID); err == nil {
+			t.Errorf("Expected error for url=%q deliveryID=%q", tt.url, tt.deliveryID)
+		}
+	}
+}
+
+func TestContactEventDataToCreateRequest(t *testing.T) {
+	data := &ContactEventData{
+		ContactID:      "c1",
+		WhatsappNumber: "5491112345678",
+		FirstName:      "Juan",
+		LastName:       "Pérez",
+		FullName:       "Juan Pérez",
+		Email:          "juan@ejemplo.com",
+		Tags:           []string{"cliente", "vip"},
+		CustomParams: []WebhookCustomParam{
+			{Name: "ciudad", Value: "Buenos Aires"},
+			{Name: "plan", Value: "premium"},
+		},
+	}
+	
+	req := data.ToCreateRequest()
+	
+	if req.FirstName != "Juan" || req.LastName != "Pérez" || req.Phone != "5491112345678" || req.Email != "juan@ejemplo.com" {
+		t.Errorf("Unexpected contact fields: %+v", req)
+	}
+	
+	if strings.Join(req.Tags, ",") != "cliente,vip" {
+		t.Errorf("Expected tags cliente,vip, got %v", req.Tags)
+	}
+	
+	if len(req.CustomParams) != 2 ||
+		req.CustomParams[0].Name != "ciudad" || req.CustomParams[0].Value != "Buenos Aires" ||
+		req.CustomParams[1].Name != "plan" || req.CustomParams[1].Value != "premium" {
+		t.Errorf("Unexpected custom params: %+v", req.CustomParams)
+	}
+	
+	// La petición debe poder enviarse tal cual a AddContact
+	if err := req.Validate(); err != nil {
+		t.Errorf("Expected a valid CreateContactRequest, got %v", err)
+	}
+	
+	// Las etiquetas no deben compartir memoria con el evento
+	req.Tags[0] = "otro"
+	if data.Tags[0] != "cliente" {
+		t.Error("Expected tags to be copied")
+	}
+}
+
+func TestContactEventDataToCreateRequestFullName(t *testing.T) {
+	data := &ContactEventData{WhatsappNumber: "5491112345678", FullName: "María García"}
+	
+	if req := data.ToCreateRequest(); req.FirstName != "María García" {
+		t.Errorf("Expected FullName as FirstName, got %q", req.FirstName)
+	}
+}
+
+// memoryListener implementa net.Listener sobre conexiones net.Pipe, para probar
+// el servidor de webhooks sin abrir puertos reales
+type memoryListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newMemoryListener() *memoryListener {
+	return &memoryListener{
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+func (l *memoryListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *memoryListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *memoryListener) Addr() net.Addr {
+	return memoryAddr{}
+}
+
+// DialContext entrega al servidor un extremo de un net.Pipe y retorna el otro
+func (l *memoryListener) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	server, client := net.Pipe()
+	select {
+	case l.conns <- server:
+		return client, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+type memoryAddr struct{}
+
+func (memoryAddr) Network() string { return "memory" }
+func (memoryAddr) String() string  { return "memory" }
+
+func TestStartWebhookServerWithListener(t *testing.T) {
+	listener := newMemoryListener()
+	service := NewService(nil)
+	
+	received := make(chan string, 1)
+	handlers := map[WebhookEventType]WebhookHandler{
+		MessageReceived: func(event *WebhookEvent) error {
+			received <- event.ID
+			return nil
+		},
+	}
+	
+	if err := service.StartWebhookServerWithListener(listener, handlers); err != nil {
+		t.Fatalf("StartWebhookServerWithListener() error = %v", err)
+	}
+	
+	client := &http.Client{Transport: &http.Transport{DialContext: listener.DialContext}}
+	
+	resp, err := client.Post("http://memory/webhook", "application/json", strings.NewReader(`{"id":"evt_1","type":"message_received"}`))
+	if err != nil {
+		t.Fatalf("POST /webhook error = %v", err)
+	}
+	resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	
+	select {
+	case id := <-received:
+		if id != "evt_1" {
+			t.Errorf("Expected event evt_1, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Handler was not called")
+	}
+	
+	resp, err = client.Get("http://memory/health")
+	if err != nil {
+		t.Fatalf("GET /health error = %v", err)
+	}
+	
+	var health struct {
+		Status string `json:"status"`
+		Server struct {
+			Running  bool `json:"running"`
+			Handlers int  `json:"handlers"`
+		} `json:"server"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&health)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Invalid health response: %v", err)
+	}
+	
+	if health.Status != "healthy" || !health.Server.Running || health.Server.Handlers != 1 {
+		t.Errorf("Unexpected health response %+v", health)
+	}
+	
+	if err := service.StopWebhookServer(); err != nil {
+		t.Fatalf("StopWebhookServer() error = %v", err)
+	}
+	
+	if _, err := listener.Accept(); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Expected listener to be closed after stop, got %v", err)
+	}
+}
+
+func TestStartWebhookServerAsyncWithListenerDrains(t *testing.T) {
+	listener := newMemoryListener()
+	service := NewService(nil)
+	
+	var processed int32
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&processed, 1)
+		return nil
+	})
+	
+	if err := service.StartWebhookServerAsyncWithListener(listener, 1); err != nil {
+		t.Fatalf("StartWebhookServerAsyncWithListener() error = %v", err)
+	}
+	
+	client := &http.Client{Transport: &http.Transport{DialContext: listener.DialContext}}
+	
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post("http://memory/webhook", "application/json", strings.NewReader(`{"id":"evt","type":"message_received"}`))
+		if err != nil {
+			t.Fatalf("POST /webhook error = %v", err)
+		}
+		resp.Body.Close()
+		
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Expected 200, got %d", resp.StatusCode)
+		}
+	}
+	
+	// Detener el servidor espera a que los workers procesen la cola
+	if err := service.StopWebhookServer(); err != nil {
+		t.Fatalf("StopWebhookServer() error = %v", err)
+	}
+	
+	if got := atomic.LoadInt32(&processed); got != 3 {
+		t.Errorf("Expected 3 processed events after drain, got %d", got)
+	}
+}
+
+func TestStartWebhookServerWithListenerValidation(t *testing.T) {
+	service := NewService(nil)
+	
+	if err := service.StartWebhookServerWithListener(nil, nil); err == nil {
+		t.Error("Expected error for nil listener")
+	}
+	
+	if err := service.StartWebhookServerAsyncWithListener(nil, 1); err == nil {
+		t.Error("Expected error for nil listener")
+	}
+}
+
+func TestParseMessageSentPricing(t *testing.T) {
+	payload := []byte(`{
+		"id": "evt_1",
+		"type": "template_message_sent",
+		"data": {
+			"messageId": "msg_1",
+			"to": "5491112345678",
+			"status": "sent",
+			"conversationId": "conv_123",
+			"conversationCategory": "marketing",
+			"pricing": {"billable": true, "pricingModel": "CBP", "category": "marketing"}
+		}
+	}`)
+	
+	event, err := ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	
+	data, ok := event.Data.(MessageSentData)
+	if !ok {
+		t.Fatalf("Expected MessageSentData, got %T", event.Data)
+	}
+	
+	if data.ConversationID != "conv_123" || data.GetConversationCategory() != ConversationCategoryMarketing {
+		t.Errorf("Unexpected conversation data %+v", data)
+	}
+	
+	if !data.HasPricing() || !data.IsBillable() || data.Pricing.PricingModel != "CBP" {
+		t.Errorf("Unexpected pricing %+v", data.Pricing)
+	}
+}
+
+func TestParseMessageSentWithoutPricing(t *testing.T) {
+	payload := []byte(`{"id":"evt_2","type":"session_message_sent","data":{"messageId":"msg_2","status":"sent"}}`)
+	
+	event, err := ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	
+	data := event.Data.(MessageSentData)
+	if data.HasPricing() || data.IsBillable() || data.ConversationID != "" || data.GetConversationCategory() != "" {
+		t.Errorf("Expected no pricing data, got %+v", data)
+	}
+}
+
+func TestValidateHubSignature(t *testing.T) {
+	payload := []byte(`{"eventType":"message_received"}`)
+	secret := "test-secret"
+	header := "sha256=" + sign(payload, secret)
+	
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		secret  string
+		want    bool
+	}{
+		{name: "valid signature", payload: payload, header: header, secret: secret, want: true},
+		{name: "tampered payload", payload: []byte(`{"eventType":"message_read"}`), header: header, secret: secret, want: false},
+		{name: "tampered signature", payload: payload, header: "sha256=" + sign(payload, "other"), secret: secret, want: false},
+		{name: "missing prefix", payload: payload, header: sign(payload, secret), secret: secret, want: false},
+		{name: "invalid hex", payload: payload, header: "sha256=zz", secret: secret, want: false},
+		{name: "no secret", payload: payload, header: header, secret: "", want: false},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidateHubSignature(tt.payload, tt.header, tt.secret); got != tt.want {
+				t.Errorf("ValidateHubSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHubSignature(t *testing.T) {
+	signature, err := ParseHubSignature("sha256=" + sign([]byte("x"), "s"))
+	if err != nil {
+		t.Fatalf("ParseHubSignature() error = %v", err)
+	}
+	
+	if signature.Algo != SignatureSHA256 || len(signature.Digest) != sha256.Size {
+		t.Errorf("Unexpected signature: %+v", signature)
+	}
+	
+	if _, err := ParseHubSignature("sha256=abcd"); err == nil {
+		t.Error("Expected error for truncated digest")
+	}
+}
+
+func TestComputeSignature(t *testing.T) {
+	payload := []byte(`{"eventType":"message_received"}`)
+	secret := "test-secret"
+	
+	signature := ComputeSignature(payload, secret)
+	if signature != sign(payload, secret) {
+		t.Errorf("ComputeSignature() = %s, want %s", signature, sign(payload, secret))
+	}
+	if !ValidateSignature(payload, signature, secret) {
+		t.Error("Expected ComputeSignature output to be accepted by ValidateSignature")
+	}
+	
+	hub := ComputeHubSignature(payload, secret)
+	if !strings.HasPrefix(hub, "sha256=") || !ValidateHubSignature(payload, hub, secret) {
+		t.Errorf("Expected ComputeHubSignature output to be accepted, got %s", hub)
+	}
+	
+	config := SignatureConfig{Algo: SignatureSHA1, Encoding: SignatureBase64}
+	signature, err := ComputeSignatureWithConfig(payload, secret, config)
+	if err != nil {
+		t.Fatalf("ComputeSignatureWithConfig() error = %v", err)
+	}
+	if VerifySignatureWithConfig(payload, signature, secret, config) != SignatureValid {
+		t.Error("Expected SHA1/base64 signature to be valid")
+	}
+	
+	if _, err := ComputeSignatureWithConfig(payload, secret, SignatureConfig{Algo: "md5", Encoding: SignatureHex}); err == nil {
+		t.Error("Expected error for unsupported algorithm")
+	}
+}
+
+func TestTestWebhookSigned(t *testing.T) {
+	receiver := NewService(nil)
+	receiver.SetSecret("shared-secret")
+	
+	var valid bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		valid = receiver.ValidateWebhookSignature(body, r.Header.Get("X-Webhook-Signature"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	
+	sender := NewService(nil)
+	sender.SetSecret("shared-secret")
+	
+	if err := sender.TestWebhook(context.Background(), server.URL); err != nil {
+		t.Fatalf("TestWebhook() error = %v", err)
+	}
+	
+	if !valid {
+		t.Error("Expected test webhook to carry a valid signature")
+	}
+}
+
+func TestTestWebhookEvent(t *testing.T) {
+	receiver := NewService(nil)
+	receiver.SetSecret("shared-secret")
+	receiver.SetRequireSignature(true)
+	receiver.SetSignatureAlgo(SignatureSHA1, SignatureBase64)
+	
+	var received *WebhookEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		event, err := receiver.HandleWebhook(body, r.Header.Get(SignatureHeader))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = event
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	
+	sender := NewService(nil)
+	sender.SetSecret("shared-secret")
+	sender.SetSignatureAlgo(SignatureSHA1, SignatureBase64)
+	
+	event := &WebhookEvent{
+		Type: ContactCreated,
+		Data: map[string]interface{}{"id": "contact-1", "wAid": "1234567890"},
+	}
+	
+	if err := sender.TestWebhookEvent(context.Background(), server.URL, event); err != nil {
+		t.Fatalf("TestWebhookEvent() error = %v", err)
+	}
+	
+	if received == nil || received.Type != ContactCreated {
+		t.Fatalf("Expected contact_created event to be accepted, got %+v", received)
+	}
+	
+	if received.ID == "" || received.Timestamp == "" {
+		t.Errorf("Expected ID and Timestamp to be filled, got %q and %q", received.ID, received.Timestamp)
+	}
+	
+	if event.ID != "" {
+		t.Error("Expected caller's event not to be modified")
+	}
+	
+	// Sin secreto en el emisor la firma requerida falta
+	unsigned := NewService(nil)
+	if err := unsigned.TestWebhook(context.Background(), server.URL); err == nil {
+		t.Error("Expected unsigned test webhook to be rejected")
+	}
+}
+
+func TestTestWebhookEventValidation(t *testing.T) {
+	service := NewService(nil)
+	
+	if err := service.TestWebhookEvent(context.Background(), "http://localhost", nil); err == nil {
+		t.Error("Expected error for nil event")
+	}
+	
+	if err := service.TestWebhookEvent(context.Background(), "http://localhost", &WebhookEvent{}); err == nil {
+		t.Error("Expected error for event without type")
+	}
+}
+
+func TestHandleWebhookRequestSignatureHeader(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	secret := "test-secret"
+	
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{
+			name:       "default header",
+			header:     SignatureHeader,
+			value:      sign(payload, secret),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "default hub fallback",
+			header:     HubSignatureHeader,
+			value:      sign(payload, secret),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "custom header",
+			configured: "X-Wati-Signature",
+			header:     "X-Wati-Signature",
+			value:      sign(payload, secret),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "custom header case insensitive",
+			configured: "x-wati-signature",
+			header:     "X-WATI-SIGNATURE",
+			value:      sign(payload, secret),
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "custom header ignores default",
+			configured: "X-Wati-Signature",
+			header:     SignatureHeader,
+			value:      sign(payload, secret),
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil)
+			service.SetSecret(secret)
+			service.SetSignatureHeader(tt.configured)
+			
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+			req.Header.Set(tt.header, tt.value)
+			
+			recorder := httptest.NewRecorder()
+			service.handleWebhookRequest(recorder, req)
+			
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestTestWebhookCustomSignatureHeader(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Wati-Signature")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	
+	service := NewService(nil)
+	service.SetSecret("shared-secret")
+	service.SetSignatureHeader("X-Wati-Signature")
+	
+	if err := service.TestWebhook(context.Background(), server.URL); err != nil {
+		t.Fatalf("TestWebhook() error = %v", err)
+	}
+	
+	if signature == "" {
+		t.Error("Expected signature in the configured header")
+	}
+}
+
+func TestStartWebhookServerTLS(t *testing.T) {
+	// Reutilizar el certificado de prueba de httptest y un cliente que confía en él
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	tlsConfig := certServer.TLS.Clone()
+	client := certServer.Client()
+	certServer.Close()
+	
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	
+	service := NewService(nil, WithTLSConfig(tlsConfig))
+	if err := service.StartWebhookServerWithListener(listener, nil); err != nil {
+		t.Fatalf("StartWebhookServerWithListener() error = %v", err)
+	}
+	defer service.StopWebhookServer()
+	
+	resp, err := client.Get("https://" + listener.Addr().String() + "/health")
+	if err != nil {
+		t.Fatalf("GET /health over TLS error = %v", err)
+	}
+	resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+	
+	if resp.TLS == nil {
+		t.Error("Expected a TLS connection")
+	}
+}
+
+func TestStartWebhookServerTLSInvalidCertificate(t *testing.T) {
+	service := NewService(nil)
+	
+	if err := service.StartWebhookServerTLS(0, "", "", nil); err == nil {
+		t.Error("Expected error for missing certificate files")
+	}
+	
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, []byte("not a certificate"), 0o600)
+	os.WriteFile(keyFile, []byte("not a key"), 0o600)
+	
+	if err := service.StartWebhookServerTLS(0, certFile, keyFile, nil); err == nil {
+		t.Error("Expected error for invalid certificate")
+	}
+	
+	if service.server.IsRunning {
+		t.Error("Expected server not to start with an invalid certificate")
+	}
+}
+
+func TestWebhookHandlerOnExistingMux(t *testing.T) {
+	service := NewService(nil)
+	service.SetSecret("test-secret")
+	
+	var received *WebhookEvent
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		received = event
+		return nil
+	})
+	
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wati/webhook", service.WebhookHandler())
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	
+	payload := []byte(`{"id":"evt_1","type":"message_received","data":{"text":"hola"}}`)
+	
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/wati/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, sign(payload, "test-secret"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /wati/webhook error = %v", err)
+	}
+	resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	
+	if received == nil || received.ID != "evt_1" {
+		t.Errorf("Expected event evt_1 to be dispatched, got %+v", received)
+	}
+	
+	// La firma se sigue verificando fuera del servidor propio
+	req, _ = http.NewRequest(http.MethodPost, server.URL+"/wati/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, "invalid")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST /wati/webhook error = %v", err)
+	}
+	resp.Body.Close()
+	
+	if resp.StatusCode == http.StatusOK {
+		t.Error("Expected invalid signature to be rejected")
+	}
+}
+
+func TestHandleWebhookRequestStatusMapping(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	secret := "test-secret"
+	
+	tests := []struct {
+		name       string
+		payload    []byte
+		signature  string
+		handlers   []WebhookHandler
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			payload:    payload,
+			signature:  sign(payload, secret),
+			handlers:   []WebhookHandler{func(*WebhookEvent) error { return nil }},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid signature",
+			payload:    payload,
+			signature:  "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "invalid payload",
+			payload:    []byte(`not json`),
+			signature:  sign([]byte(`not json`), secret),
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "transient handler error",
+			payload:   payload,
+			signature: sign(payload, secret),
+			handlers: []WebhookHandler{func(*WebhookEvent) error {
+				return errors.New("database unavailable")
+			}},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:      "permanent handler error",
+			payload:   payload,
+			signature: sign(payload, secret),
+			handlers: []WebhookHandler{func(*WebhookEvent) error {
+				return fmt.Errorf("%w: unknown customer", ErrPermanentFailure)
+			}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:      "permanent and transient handler errors",
+			payload:   payload,
+			signature: sign(payload, secret),
+			handlers: []WebhookHandler{
+				func(*WebhookEvent) error { return fmt.Errorf("%w: unknown customer", ErrPermanentFailure) },
+				func(*WebhookEvent) error { return errors.New("database unavailable") },
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewService(nil)
+			service.SetSecret(secret)
+			for _, handler := range tt.handlers {
+				service.RegisterHandler(MessageReceived, handler)
+			}
+			
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(tt.payload))
+			req.Header.Set(SignatureHeader, tt.signature)
+			
+			recorder := httptest.NewRecorder()
+			service.handleWebhookRequest(recorder, req)
+			
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEventRawDataKeepsUnknownFields(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"message_received","data":{"text":"hola","waId":"5491123456789","campaignId":"cmp_7"}}`)
+	
+	event, err := ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	
+	if _, ok := event.Data.(MessageReceivedData); !ok {
+		t.Fatalf("Expected typed MessageReceivedData, got %T", event.Data)
+	}
+	
+	var extra struct {
+		CampaignID string `json:"campaignId"`
+	}
+	if err := json.Unmarshal(event.RawData, &extra); err != nil {
+		t.Fatalf("json.Unmarshal(RawData) error = %v", err)
+	}
+	
+	if extra.CampaignID != "cmp_7" {
+		t.Errorf("Expected campaignId cmp_7 from RawData, got %q", extra.CampaignID)
+	}
+}
+
+func TestStopWebhookServerRetriesAfterTimeout(t *testing.T) {
+	original := stopWebhookTimeout
+	stopWebhookTimeout = 50 * time.Millisecond
+	defer func() { stopWebhookTimeout = original }()
+	
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handlers := map[WebhookEventType]WebhookHandler{
+		MessageReceived: func(event *WebhookEvent) error {
+			close(started)
+			<-release
+			return nil
+		},
+	}
+	
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	
+	service := NewService(nil)
+	if err := service.StartWebhookServerWithListener(listener, handlers); err != nil {
+		t.Fatalf("StartWebhookServerWithListener() error = %v", err)
+	}
+	
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := http.Post("http://"+listener.Addr().String()+"/webhook", "application/json",
+			strings.NewReader(`{"id":"evt_1","type":"message_received"}`))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-started
+	
+	// El handler sigue en curso: la detención vence y puede reintentarse
+	if err := service.StopWebhookServer(); err == nil {
+		t.Fatal("Expected error while a request is in flight")
+	}
+	
+	if !service.GetServerStatus() {
+		t.Error("Expected server to remain running after a failed stop")
+	}
+	
+	close(release)
+	<-done
+	
+	if err := service.StopWebhookServer(); err != nil {
+		t.Fatalf("StopWebhookServer() retry error = %v", err)
+	}
+	
+	if service.GetServerStatus() {
+		t.Error("Expected server to be stopped after the retry")
+	}
+}
+
+func TestStopWebhookServerWithHandlerOnExistingMux(t *testing.T) {
+	service := NewService(nil, WithQueueSize(1), WithQueueFullPolicy(QueueFullPolicy{
+		Action:  QueueFullBlock,
+		Timeout: 20 * time.Millisecond,
+	}))
+	service.RegisterHandler(MessageReceived, func(event *WebhookEvent) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wati/webhook", service.WebhookHandler())
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	
+	if err := service.StartWebhookServerAsyncWithListener(newMemoryListener(), 1); err != nil {
+		t.Fatalf("StartWebhookServerAsyncWithListener() error = %v", err)
+	}
+	
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				resp, err := http.Post(server.URL+"/wati/webhook", "application/json",
+					strings.NewReader(`{"id":"evt","type":"message_received"}`))
+				if err != nil {
+					// Un pánico en el handler corta la conexión sin respuesta
+					t.Errorf("POST /wati/webhook error = %v", err)
+					return
+				}
+				resp.Body.Close()
+				
+				if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+					t.Errorf("Expected 200 or 503, got %d", resp.StatusCode)
+				}
+			}
+		}()
+	}
+	
+	time.Sleep(10 * time.Millisecond)
+	if err := service.StopWebhookServer(); err != nil {
+		t.Fatalf("StopWebhookServer() error = %v", err)
+	}
+	
+	wg.Wait()
+}
+
+func TestVerifyEventWarnsOnceWithoutSecret(t *testing.T) {
+	var output bytes.Buffer
+	log.SetOutput(&output)
+	defer log.SetOutput(os.Stderr)
+	
+	service := NewService(nil)
+	payload := []byte(`{"id":"evt_1","type":"message_received"}`)
+	
+	for i := 0; i < 3; i++ {
+		if _, err := service.HandleWebhook(payload, ""); err != nil {
+			t.Fatalf("HandleWebhook() error = %v", err)
+		}
+	}
+	
+	if got := strings.Count(output.String(), "signature check skipped"); got != 1 {
+		t.Errorf("Expected a single warning for unsigned events, got %d", got)
+	}
+	
+	// Cambiar el secreto vuelve a avisar una vez
+	service.SetSecret("")
+	service.HandleWebhook(payload, "")
+	service.HandleWebhook(payload, "")
+	
+	if got := strings.Count(output.String(), "signature check skipped"); got != 2 {
+		t.Errorf("Expected one more warning after SetSecret, got %d", got)
+	}
+}
@@ -2,12 +2,19 @@ package webhooks
 
 import (
 	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/diogenes-moreira/wati-sdk/contacts"
 )
 
 // WebhookEventType representa el tipo de evento de webhook
@@ -29,6 +36,53 @@ const (
 	ChatStatusChanged     WebhookEventType = "chat_status_changed"
 )
 
+// Headers de firma que acepta el servidor de webhooks
+const (
+	SignatureHeader    = "X-Webhook-Signature"
+	HubSignatureHeader = "X-Hub-Signature-256"
+)
+
+// IsMessageEvent indica si el evento corresponde a un mensaje recibido o enviado
+func (t WebhookEventType) IsMessageEvent() bool {
+	switch t {
+	case MessageReceived, NewContactMessage, SessionMessageSent, TemplateMessageSent, TemplateMessageFailed:
+		return true
+	}
+	return false
+}
+
+// IsStatusEvent indica si el evento es un cambio de estado de un mensaje enviado
+func (t WebhookEventType) IsStatusEvent() bool {
+	switch t {
+	case MessageDelivered, MessageRead, MessageReplied:
+		return true
+	}
+	return false
+}
+
+// IsContactEvent indica si el evento corresponde a un contacto
+func (t WebhookEventType) IsContactEvent() bool {
+	switch t {
+	case ContactCreated, ContactUpdated:
+		return true
+	}
+	return false
+}
+
+// IsChatbotEvent indica si el evento corresponde a un chatbot o al estado del chat
+func (t WebhookEventType) IsChatbotEvent() bool {
+	switch t {
+	case ChatbotStarted, ChatbotStopped, ChatStatusChanged:
+		return true
+	}
+	return false
+}
+
+// IsValid indica si el tipo de evento es uno de los conocidos por el SDK
+func (t WebhookEventType) IsValid() bool {
+	return t.IsMessageEvent() || t.IsStatusEvent() || t.IsContactEvent() || t.IsChatbotEvent()
+}
+
 // WebhookEvent representa un evento de webhook
 type WebhookEvent struct {
 	ID        string           `json:"id"`
@@ -37,6 +91,11 @@ type WebhookEvent struct {
 	Data      interface{}      `json:"data"`
 	Source    string           `json:"source,omitempty"`
 	Version   string           `json:"version,omitempty"`
+	
+	// RawData conserva el JSON original de Data, útil para tipos de evento
+	// que el SDK todavía no conoce y para leer campos que el tipo de Data no
+	// modela, ya que WATI agrega campos con frecuencia
+	RawData json.RawMessage `json:"-"`
 }
 
 // WebhookHandler es una función que maneja eventos de webhook
@@ -53,6 +112,7 @@ type MessageReceivedData struct {
 	Location       *WebhookLocationInfo   `json:"location,omitempty"`
 	Contact        *WebhookContactInfo    `json:"contact,omitempty"`
 	Interactive    *WebhookInteractiveInfo `json:"interactive,omitempty"`
+	Context        *WebhookMessageContext `json:"context,omitempty"`
 	Timestamp      string                 `json:"timestamp"`
 	ContactProfile *WebhookContactProfile `json:"contactProfile,omitempty"`
 }
@@ -69,8 +129,29 @@ type MessageSentData struct {
 	ErrorCode     string                 `json:"errorCode,omitempty"`
 	ErrorMessage  string                 `json:"errorMessage,omitempty"`
 	ContactProfile *WebhookContactProfile `json:"contactProfile,omitempty"`
+	
+	// Datos de la conversación de WhatsApp usados para conciliar la
+	// facturación. Solo están presentes cuando WATI los informa.
+	ConversationID       string               `json:"conversationId,omitempty"`
+	ConversationCategory string               `json:"conversationCategory,omitempty"`
+	Pricing              *ConversationPricing `json:"pricing,omitempty"`
 }
 
+// ConversationPricing representa la información de precio de una conversación
+type ConversationPricing struct {
+	Billable     bool   `json:"billable"`
+	PricingModel string `json:"pricingModel,omitempty"`
+	Category     string `json:"category,omitempty"`
+}
+
+// Categorías de conversación de WhatsApp
+const (
+	ConversationCategoryMarketing      = "marketing"
+	ConversationCategoryUtility        = "utility"
+	ConversationCategoryAuthentication = "authentication"
+	ConversationCategoryService        = "service"
+)
+
 // MessageStatusData representa los datos de cambio de estado de mensaje
 type MessageStatusData struct {
 	MessageID   string `json:"messageId"`
@@ -156,6 +237,13 @@ type WebhookInteractiveInfo struct {
 	ListReply   *WebhookListReply           `json:"listReply,omitempty"`
 }
 
+// WebhookMessageContext identifica el mensaje al que responde un mensaje
+// recibido, por ejemplo el mensaje interactivo cuyo botón se presionó
+type WebhookMessageContext struct {
+	ID   string `json:"id"`
+	From string `json:"from,omitempty"`
+}
+
 // WebhookContactProfile representa el perfil del contacto
 type WebhookContactProfile struct {
 	Name   string `json:"name"`
@@ -235,13 +323,68 @@ type WebhooksResponse struct {
 	Webhooks []WebhookConfig `json:"webhooks"`
 }
 
+// RedeliveryRequest representa la petición para reenviar una entrega fallida
+type RedeliveryRequest struct {
+	URL        string `json:"url"`
+	DeliveryID string `json:"deliveryId"`
+}
+
+// RedeliveryResponse representa la respuesta de un pedido de reenvío
+type RedeliveryResponse struct {
+	BaseResponse
+	Status string `json:"status,omitempty"`
+}
+
 // WebhookServer representa un servidor de webhooks
 type WebhookServer struct {
 	Port     int                                    `json:"port"`
-	Handlers map[WebhookEventType]WebhookHandler   `json:"-"`
+	Handlers map[WebhookEventType][]WebhookHandler `json:"-"`
+	
+	// DefaultHandler se ejecuta para los eventos sin handlers específicos
+	DefaultHandler WebhookHandler `json:"-"`
 	Secret   string                                 `json:"secret,omitempty"`
 	server   *http.Server                          `json:"-"`
 	IsRunning bool                                  `json:"isRunning"`
+	
+	// RequireSignature rechaza los eventos sin firma verificable, incluso
+	// cuando no hay secreto configurado
+	RequireSignature bool `json:"requireSignature"`
+	
+	// MaxEventAge es la antigüedad máxima aceptada para el Timestamp de un
+	// evento. Cero deshabilita la protección contra replay.
+	MaxEventAge time.Duration `json:"maxEventAge,omitempty"`
+	
+	// Signature define el algoritmo y la codificación de las firmas esperadas
+	Signature SignatureConfig `json:"signature"`
+	
+	// SignatureHeader es el header del que se lee la firma. Vacío busca
+	// SignatureHeader y, si falta, HubSignatureHeader.
+	SignatureHeader string `json:"signatureHeader,omitempty"`
+	
+	// TLSConfig, si no es nil, hace que el servidor sirva HTTPS
+	TLSConfig *tls.Config `json:"-"`
+	
+	// QueueSize y QueueFullPolicy configuran la cola del modo asíncrono
+	QueueSize       int             `json:"queueSize"`
+	QueueFullPolicy QueueFullPolicy `json:"queueFullPolicy"`
+}
+
+// QueueFullAction define qué hacer con un evento cuando la cola asíncrona está llena
+type QueueFullAction string
+
+const (
+	// QueueFullReject responde 503 para que WATI reintente la entrega
+	QueueFullReject QueueFullAction = "reject"
+	// QueueFullDrop descarta el evento, lo contabiliza y responde 200
+	QueueFullDrop QueueFullAction = "drop"
+	// QueueFullBlock espera hasta Timeout a que se libere lugar y luego responde 503
+	QueueFullBlock QueueFullAction = "block"
+)
+
+// QueueFullPolicy configura el comportamiento ante una cola asíncrona llena
+type QueueFullPolicy struct {
+	Action  QueueFullAction `json:"action"`
+	Timeout time.Duration   `json:"timeout,omitempty"` // solo para QueueFullBlock
 }
 
 // BaseResponse representa la respuesta base de la API
@@ -294,6 +437,15 @@ func ParseWebhookEvent(payload []byte) (*WebhookEvent, error) {
 		return nil, fmt.Errorf("error parsing webhook event: %w", err)
 	}
 	
+	// Conservar el JSON original de los datos
+	var raw struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing webhook event: %w", err)
+	}
+	event.RawData = raw.Data
+	
 	// Parsear los datos específicos según el tipo de evento
 	if err := parseEventData(&event); err != nil {
 		return nil, fmt.Errorf("error parsing event data: %w", err)
@@ -308,11 +460,8 @@ func parseEventData(event *WebhookEvent) error {
 		return nil
 	}
 	
-	// Convertir a JSON y luego al tipo específico
-	dataBytes, err := json.Marshal(event.Data)
-	if err != nil {
-		return err
-	}
+	// Decodificar el JSON original al tipo específico
+	dataBytes := []byte(event.RawData)
 	
 	switch event.Type {
 	case MessageReceived, NewContactMessage:
@@ -361,19 +510,192 @@ func parseEventData(event *WebhookEvent) error {
 	return nil
 }
 
-// ValidateSignature valida la firma de un webhook
-func ValidateSignature(payload []byte, signature string, secret string) bool {
+// SignatureResult es el resultado de verificar la firma de un webhook
+type SignatureResult int
+
+const (
+	// SignatureInvalid indica que la firma no coincide con el payload
+	SignatureInvalid SignatureResult = iota
+	// SignatureValid indica que la firma coincide con el payload
+	SignatureValid
+	// SignatureSkipped indica que no hay secreto configurado y la firma no se verificó
+	SignatureSkipped
+)
+
+// String retorna una representación legible del resultado
+func (r SignatureResult) String() string {
+	switch r {
+	case SignatureValid:
+		return "valid"
+	case SignatureSkipped:
+		return "skipped"
+	default:
+		return "invalid"
+	}
+}
+
+// SignatureAlgo es el algoritmo HMAC usado para firmar los webhooks
+type SignatureAlgo string
+
+const (
+	SignatureSHA256 SignatureAlgo = "sha256"
+	SignatureSHA1   SignatureAlgo = "sha1"
+)
+
+// SignatureEncoding es la codificación de la firma recibida
+type SignatureEncoding string
+
+const (
+	SignatureHex    SignatureEncoding = "hex"
+	SignatureBase64 SignatureEncoding = "base64"
+)
+
+// SignatureConfig define cómo se calcula y codifica la firma de los webhooks
+type SignatureConfig struct {
+	Algo     SignatureAlgo     `json:"algo"`
+	Encoding SignatureEncoding `json:"encoding"`
+}
+
+// DefaultSignatureConfig retorna la configuración por defecto: HMAC-SHA256 en hexadecimal
+func DefaultSignatureConfig() SignatureConfig {
+	return SignatureConfig{
+		Algo:     SignatureSHA256,
+		Encoding: SignatureHex,
+	}
+}
+
+// VerifySignature verifica la firma HMAC-SHA256 en hexadecimal de un webhook.
+// Sin secreto configurado retorna SignatureSkipped en lugar de dar la firma por válida.
+func VerifySignature(payload []byte, signature string, secret string) SignatureResult {
+	return VerifySignatureWithConfig(payload, signature, secret, DefaultSignatureConfig())
+}
+
+// VerifySignatureWithConfig verifica la firma de un webhook con el algoritmo y la
+// codificación indicados. Acepta firmas con el prefijo "<algo>=" (por ejemplo
+// "sha256=", usado por X-Hub-Signature-256).
+func VerifySignatureWithConfig(payload []byte, signature string, secret string, config SignatureConfig) SignatureResult {
 	if secret == "" {
-		return true // Si no hay secreto configurado, no validamos
+		return SignatureSkipped
+	}
+	
+	expectedSignature, err := ComputeSignatureWithConfig(payload, secret, config)
+	if err != nil {
+		return SignatureInvalid
+	}
+	
+	signature = strings.TrimPrefix(signature, string(config.Algo)+"=")
+	
+	// Comparar firmas
+	if hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return SignatureValid
+	}
+	
+	return SignatureInvalid
+}
+
+// ValidateSignature valida la firma de un webhook. Sin secreto configurado la
+// firma no puede verificarse y retorna false; usar VerifySignature para
+// distinguir ese caso de una firma inválida.
+func ValidateSignature(payload []byte, signature string, secret string) bool {
+	return VerifySignature(payload, signature, secret) == SignatureValid
+}
+
+// ComputeSignature calcula la firma HMAC-SHA256 en hexadecimal de un payload,
+// la que ValidateSignature acepta. Sirve para firmar payloads en los tests de
+// un handler de webhooks.
+func ComputeSignature(payload []byte, secret string) string {
+	signature, _ := ComputeSignatureWithConfig(payload, secret, DefaultSignatureConfig())
+	return signature
+}
+
+// ComputeHubSignature calcula la firma con el formato del header
+// X-Hub-Signature-256 ("sha256=<hex>"), la que ValidateHubSignature acepta
+func ComputeHubSignature(payload []byte, secret string) string {
+	return string(SignatureSHA256) + "=" + ComputeSignature(payload, secret)
+}
+
+// ComputeSignatureWithConfig calcula la firma de un payload con el algoritmo y
+// la codificación indicados, sin prefijo
+func ComputeSignatureWithConfig(payload []byte, secret string, config SignatureConfig) (string, error) {
+	var newHash func() hash.Hash
+	switch config.Algo {
+	case SignatureSHA256:
+		newHash = sha256.New
+	case SignatureSHA1:
+		newHash = sha1.New
+	default:
+		return "", fmt.Errorf("unsupported signature algorithm: %s", config.Algo)
+	}
+	
+	// Calcular HMAC
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(payload)
+	sum := mac.Sum(nil)
+	
+	switch config.Encoding {
+	case SignatureHex:
+		return hex.EncodeToString(sum), nil
+	case SignatureBase64:
+		return base64.StdEncoding.EncodeToString(sum), nil
+	}
+	
+	return "", fmt.Errorf("unsupported signature encoding: %s", config.Encoding)
+}
+
+// HubSignature es la firma de un header X-Hub-Signature-256 ya parseada
+type HubSignature struct {
+	Algo   SignatureAlgo `json:"algo"`
+	Digest []byte        `json:"digest"`
+}
+
+// ParseHubSignature parsea un header con formato "sha256=<hex>", como el que
+// envían GitHub y Meta en X-Hub-Signature-256
+func ParseHubSignature(header string) (*HubSignature, error) {
+	prefix := string(SignatureSHA256) + "="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, fmt.Errorf("signature header must start with %q", prefix)
+	}
+	
+	digest, err := hex.DecodeString(strings.TrimPrefix(header, prefix))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signature: %w", err)
+	}
+	
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("signature must be %d bytes, got %d", sha256.Size, len(digest))
+	}
+	
+	return &HubSignature{
+		Algo:   SignatureSHA256,
+		Digest: digest,
+	}, nil
+}
+
+// Matches verifica en tiempo constante que la firma corresponda al payload
+func (h *HubSignature) Matches(payload []byte, secret string) bool {
+	if h.Algo != SignatureSHA256 {
+		return false
 	}
 	
-	// Calcular HMAC-SHA256
 	mac := hmac.New(sha256.New, []byte(secret))
 	mac.Write(payload)
-	expectedSignature := hex.EncodeToString(mac.Sum(nil))
 	
-	// Comparar firmas
-	return hmac.Equal([]byte(signature), []byte(expectedSignature))
+	return hmac.Equal(h.Digest, mac.Sum(nil))
+}
+
+// ValidateHubSignature valida un header X-Hub-Signature-256 ("sha256=<hex>").
+// Igual que ValidateSignature, sin secreto configurado retorna false.
+func ValidateHubSignature(payload []byte, header, secret string) bool {
+	if secret == "" {
+		return false
+	}
+	
+	signature, err := ParseHubSignature(header)
+	if err != nil {
+		return false
+	}
+	
+	return signature.Matches(payload, secret)
 }
 
 // GetMessageText extrae el texto de un mensaje recibido
@@ -457,6 +779,29 @@ func (d *MessageSentData) HasError() bool {
 	return d.ErrorCode != "" || d.ErrorMessage != ""
 }
 
+// HasPricing indica si el evento incluye información de precio
+func (d *MessageSentData) HasPricing() bool {
+	return d.Pricing != nil
+}
+
+// IsBillable indica si la conversación del mensaje es facturable. Es false si
+// el evento no incluye información de precio.
+func (d *MessageSentData) IsBillable() bool {
+	return d.Pricing != nil && d.Pricing.Billable
+}
+
+// GetConversationCategory obtiene la categoría de la conversación, tomándola de
+// Pricing si el evento no la informa por separado
+func (d *MessageSentData) GetConversationCategory() string {
+	if d.ConversationCategory != "" {
+		return d.ConversationCategory
+	}
+	if d.Pricing != nil {
+		return d.Pricing.Category
+	}
+	return ""
+}
+
 // GetErrorInfo obtiene información del error
 func (d *MessageSentData) GetErrorInfo() string {
 	if d.ErrorMessage != "" {
@@ -468,3 +813,32 @@ func (d *MessageSentData) GetErrorInfo() string {
 	return ""
 }
 
+// ToCreateRequest construye una petición para crear el contacto del evento en
+// otra cuenta mediante contacts.Service.AddContact. Si el evento no trae
+// FirstName se usa FullName. El evento no informa AllowBroadcast ni AllowSMS,
+// por lo que quedan en false.
+func (d *ContactEventData) ToCreateRequest() *contacts.CreateContactRequest {
+	req := &contacts.CreateContactRequest{
+		FirstName: d.FirstName,
+		LastName:  d.LastName,
+		Phone:     d.WhatsappNumber,
+		Email:     d.Email,
+	}
+	
+	if req.FirstName == "" {
+		req.FirstName = d.FullName
+	}
+	
+	if len(d.Tags) > 0 {
+		req.Tags = append([]string(nil), d.Tags...)
+	}
+	
+	for _, param := range d.CustomParams {
+		req.CustomParams = append(req.CustomParams, contacts.CustomParam{
+			Name:  param.Name,
+			Value: param.Value,
+		})
+	}
+	
+	return req
+}